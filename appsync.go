@@ -0,0 +1,439 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/razzkumar/go-graphql/internal/websocket"
+)
+
+// AppSync configures a Client for an AWS AppSync API.
+type AppSync struct {
+	// Auth authorizes requests, such as AppSyncAPIKey or a SigV4Signer.
+	// It is called for every HTTP request, and for the realtime
+	// handshake and subscriptions with the AppSync request that stands
+	// for them.
+	Auth Signer
+	// RealtimeEndpoint is the URL of the realtime API. If empty, it is
+	// derived from the endpoint of the subscription: appsync-api becomes
+	// appsync-realtime-api in the host name, or /realtime is appended
+	// to the path of custom domains.
+	RealtimeEndpoint string
+}
+
+// WithAppSync talks to an AWS AppSync API as configured by appSync.
+// Queries and mutations are sent over HTTP as usual, while Subscribe uses
+// the AppSync realtime WebSocket protocol. Subscriptions pick their
+// endpoint and headers like other requests do: the endpoint comes from
+// WithEndpoints or WithEndpointResolver, and the headers of the Request,
+// the client and the context metadata are sent in the handshake along
+// with the token and signatures.
+//
+//	client := NewClient("https://xxx.appsync-api.eu-west-1.amazonaws.com/graphql",
+//	    WithAppSync(AppSync{Auth: AppSyncAPIKey(key)}))
+func WithAppSync(appSync AppSync) ClientOption {
+	return func(client *Client) {
+		client.appSync = &appSync
+		if appSync.Auth != nil {
+			client.signers = append(client.signers, appSync.Auth)
+		}
+	}
+}
+
+// AppSyncAPIKey authorizes AppSync requests with an API key.
+func AppSyncAPIKey(key string) Signer {
+	return SignerFunc(func(ctx context.Context, r *http.Request, body []byte) error {
+		r.Header.Set("X-Api-Key", key)
+		return nil
+	})
+}
+
+// appSyncMessage is a message of the AppSync realtime protocol.
+type appSyncMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// appSyncKeepAlive is how long to wait for a keep-alive message if the
+// server does not say.
+const appSyncKeepAlive = 5 * time.Minute
+
+// Subscribe starts the subscription req on an AppSync API. The
+// subscription ends when ctx is done, the server completes it, or it is
+// closed.
+//
+//	sub, err := client.Subscribe(ctx, req)
+//	if err != nil {
+//	    return err
+//	}
+//	defer sub.Close()
+//	for sub.Next() {
+//	    var event Event
+//	    if err := sub.Decode(&event); err != nil {
+//	        return err
+//	    }
+//	}
+//	return sub.Err()
+func (c *Client) Subscribe(ctx context.Context, req *Request) (*Subscription, error) {
+	if c.appSync == nil {
+		return nil, errors.New("graphql: Subscribe needs WithAppSync")
+	}
+	req, err := c.validate(req)
+	if err != nil {
+		return nil, err
+	}
+	req, _, err = c.prepare(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	call := &call{operationType: "subscription"}
+	if err := c.pickEndpoint(ctx, req, call); err != nil {
+		return nil, err
+	}
+	api, err := url.Parse(call.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	md := c.metadata(ctx)
+	realtime := c.appSync.RealtimeEndpoint
+	if realtime == "" {
+		realtime = appSyncRealtimeEndpoint(api)
+	}
+	connect := *api
+	connect.Path = strings.TrimSuffix(api.Path, "/") + "/connect"
+	header, err := c.appSyncAuth(ctx, &connect, req, md, call, []byte("{}"))
+	if err != nil {
+		return nil, err
+	}
+	rt, err := url.Parse(realtime)
+	if err != nil {
+		return nil, err
+	}
+	q := rt.Query()
+	q.Set("header", base64.StdEncoding.EncodeToString(header))
+	q.Set("payload", base64.StdEncoding.EncodeToString([]byte("{}")))
+	rt.RawQuery = q.Encode()
+
+	dialer := &websocket.Dialer{Subprotocols: []string{"graphql-ws"}}
+	if t, ok := c.httpClient.Transport.(*http.Transport); ok {
+		dialer.NetDial = t.DialContext
+		dialer.TLSConfig = t.TLSClientConfig
+	}
+	conn, err := dialer.Dial(ctx, rt.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("graphql: connecting to %s: %w", realtime, err)
+	}
+	if c.maxResponseSize > 0 {
+		conn.SetReadLimit(c.maxResponseSize)
+	}
+	sub := &Subscription{
+		c:         c,
+		ctx:       ctx,
+		conn:      conn,
+		id:        newID(),
+		keepAlive: appSyncKeepAlive,
+		d:         &decoder{scalars: c.scalars, codec: c.codec, useNumber: c.useNumber},
+	}
+	_, sub.operation = operationInfo(req.q)
+	sub.stop = context.AfterFunc(ctx, func() { conn.Close() })
+	if err := sub.start(ctx, req, api, md, call); err != nil {
+		sub.stop()
+		conn.Close()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	sub.started = time.Now()
+	if c.metrics != nil {
+		c.metrics.ObserveSubscription(SubscriptionEvent{Operation: sub.operation, State: SubscriptionStarted})
+	}
+	return sub, nil
+}
+
+// appSyncRealtimeEndpoint derives the realtime URL from the API URL.
+func appSyncRealtimeEndpoint(api *url.URL) string {
+	u := *api
+	u.Scheme = "wss"
+	if api.Scheme == "http" {
+		u.Scheme = "ws"
+	}
+	if strings.Contains(u.Host, "appsync-api.") {
+		u.Host = strings.Replace(u.Host, "appsync-api.", "appsync-realtime-api.", 1)
+	} else {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/realtime"
+	}
+	return u.String()
+}
+
+// appSyncAuth returns the headers of an AppSync request to u with body,
+// set, authorized and signed as in Client.do, as a JSON object.
+func (c *Client) appSyncAuth(ctx context.Context, u *url.URL, req *Request, md Metadata, call *call, body []byte) ([]byte, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Accept", "application/json, text/javascript")
+	r.Header.Set("Content-Encoding", "amz-1.0")
+	r.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	c.setHeaders(ctx, r, req, md.Header)
+	if err := c.authorize(ctx, r, call); err != nil {
+		return nil, err
+	}
+	c.inject(ctx, r)
+	if err := c.sign(ctx, r); err != nil {
+		return nil, err
+	}
+	header := map[string]string{"host": u.Host}
+	for key, values := range r.Header {
+		// AppSync spells these two in canonical case, the rest in lower
+		// case.
+		if key != "Authorization" && key != "X-Amz-Security-Token" {
+			key = strings.ToLower(key)
+		}
+		header[key] = strings.Join(values, ",")
+	}
+	return json.Marshal(header)
+}
+
+// start initializes the connection and starts the subscription.
+func (s *Subscription) start(ctx context.Context, req *Request, api *url.URL, md Metadata, call *call) error {
+	if err := s.write(appSyncMessage{Type: "connection_init"}); err != nil {
+		return err
+	}
+	msg, err := s.await("connection_ack")
+	if err != nil {
+		return err
+	}
+	var ack struct {
+		ConnectionTimeoutMs int64 `json:"connectionTimeoutMs"`
+	}
+	if len(msg.Payload) > 0 && json.Unmarshal(msg.Payload, &ack) == nil && ack.ConnectionTimeoutMs > 0 {
+		s.keepAlive = time.Duration(ack.ConnectionTimeoutMs) * time.Millisecond
+	}
+
+	data, err := s.c.codec.Marshal(struct {
+		Query      string         `json:"query"`
+		Variables  map[string]any `json:"variables"`
+		Extensions map[string]any `json:"extensions,omitempty"`
+	}{req.q, req.vars, md.Extensions})
+	if err != nil {
+		return fmt.Errorf("encode body: %w", err)
+	}
+	auth, err := s.c.appSyncAuth(ctx, api, req, md, call, data)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(map[string]any{
+		"data": string(data),
+		"extensions": map[string]json.RawMessage{
+			"authorization": auth,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.write(appSyncMessage{ID: s.id, Type: "start", Payload: payload}); err != nil {
+		return err
+	}
+	_, err = s.await("start_ack")
+	return err
+}
+
+// await reads messages up to one of type typ.
+func (s *Subscription) await(typ string) (appSyncMessage, error) {
+	for {
+		msg, err := s.read()
+		if err != nil {
+			return msg, err
+		}
+		switch msg.Type {
+		case typ:
+			return msg, nil
+		case "ka":
+		case "error", "connection_error":
+			return msg, appSyncError(msg.Payload)
+		default:
+			return msg, fmt.Errorf("graphql: unexpected %q message", msg.Type)
+		}
+	}
+}
+
+func (s *Subscription) read() (appSyncMessage, error) {
+	var msg appSyncMessage
+	s.conn.SetReadDeadline(time.Now().Add(s.keepAlive))
+	_, data, err := s.conn.ReadMessage()
+	if err != nil {
+		return msg, err
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return msg, fmt.Errorf("decoding message: %w", err)
+	}
+	return msg, nil
+}
+
+func (s *Subscription) write(msg appSyncMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return s.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// appSyncError returns the first error in the payload of an error
+// message.
+func appSyncError(payload json.RawMessage) error {
+	var p struct {
+		Errors []graphErr `json:"errors"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil || len(p.Errors) == 0 {
+		return fmt.Errorf("graphql: subscription failed: %s", payload)
+	}
+	return p.Errors[0]
+}
+
+// newID returns a random UUID.
+func newID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// Subscription receives the events of a subscription. See
+// Client.Subscribe.
+type Subscription struct {
+	c         *Client
+	ctx       context.Context
+	conn      *websocket.Conn
+	id        string
+	operation string
+	keepAlive time.Duration
+	d         *decoder
+	stop      func() bool
+	started   time.Time
+
+	data json.RawMessage
+
+	mu       sync.Mutex
+	messages int
+	done     bool
+	err      error
+}
+
+// Next waits for the next event, and reports whether there is one.
+func (s *Subscription) Next() bool {
+	s.data = nil
+	for {
+		msg, err := s.read()
+		if err != nil {
+			if ctxErr := s.ctx.Err(); ctxErr != nil {
+				err = ctxErr
+			}
+			s.finish(err)
+			return false
+		}
+		if msg.ID != "" && msg.ID != s.id {
+			continue
+		}
+		switch msg.Type {
+		case "ka":
+		case "data":
+			var p struct {
+				Data   json.RawMessage `json:"data"`
+				Errors []graphErr      `json:"errors"`
+			}
+			if err := json.Unmarshal(msg.Payload, &p); err != nil {
+				s.finish(fmt.Errorf("decoding response: %w", err))
+				return false
+			}
+			if len(p.Errors) > 0 {
+				s.finish(p.Errors[0])
+				return false
+			}
+			s.data = p.Data
+			s.mu.Lock()
+			s.messages++
+			s.mu.Unlock()
+			return true
+		case "error", "connection_error":
+			s.finish(appSyncError(msg.Payload))
+			return false
+		case "complete":
+			s.finish(nil)
+			return false
+		}
+	}
+}
+
+// Decode decodes the data of the current event into v, a non-nil
+// pointer.
+func (s *Subscription) Decode(v any) error {
+	if s.data == nil {
+		return errors.New("graphql: Decode called without Next")
+	}
+	pv := reflect.ValueOf(v)
+	if pv.Kind() != reflect.Pointer || pv.IsNil() {
+		return fmt.Errorf("graphql: decode into non-pointer %T", v)
+	}
+	if err := s.d.decode(s.data, pv.Elem(), typeInfo{}, ""); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// Err returns the error that ended the subscription, if any.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close stops the subscription. It may be called concurrently with Next.
+func (s *Subscription) Close() error {
+	s.mu.Lock()
+	done := s.done
+	s.mu.Unlock()
+	if !done {
+		s.write(appSyncMessage{ID: s.id, Type: "stop"})
+		s.finish(nil)
+	}
+	return nil
+}
+
+// finish ends the subscription with err.
+func (s *Subscription) finish(err error) {
+	s.mu.Lock()
+	if s.done {
+		s.mu.Unlock()
+		return
+	}
+	s.done = true
+	s.err = err
+	messages := s.messages
+	s.mu.Unlock()
+	s.stop()
+	s.conn.Close()
+	if s.c.metrics != nil {
+		s.c.metrics.ObserveSubscription(SubscriptionEvent{
+			Operation: s.operation,
+			State:     SubscriptionEnded,
+			Duration:  time.Since(s.started),
+			Messages:  messages,
+			Err:       err,
+		})
+	}
+}
@@ -0,0 +1,392 @@
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/razzkumar/go-graphql/internal/websocket"
+)
+
+// fakeAppSync is an AppSync API with an API key. Subscriptions send the
+// events, then wait to be stopped.
+type fakeAppSync struct {
+	t      *testing.T
+	key    string
+	events []string
+	// failStart makes starting subscriptions fail.
+	failStart bool
+
+	mu      sync.Mutex
+	stopped bool
+	// header and auth are the headers of the handshake and of the
+	// start message, and data is its request.
+	header, auth map[string]string
+	data         string
+}
+
+func (f *fakeAppSync) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/graphql":
+		if r.Header.Get("X-Api-Key") != f.key {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"errors":[{"errorType":"UnauthorizedException","message":"You are not authorized to make this call."}]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"getItem":{"id":"1"}}}`)
+	case "/graphql/realtime":
+		f.realtime(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeAppSync) realtime(w http.ResponseWriter, r *http.Request) {
+	t := f.t
+	var header map[string]string
+	if err := decodeBase64JSON(r.URL.Query().Get("header"), &header); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if header["host"] != r.Host || header["x-api-key"] != f.key {
+		t.Errorf("header got %v, want the host and API key", header)
+	}
+	if got := r.URL.Query().Get("payload"); got != "e30=" {
+		t.Errorf("payload got %q, want e30=", got)
+	}
+	conn, err := websocket.Upgrade(w, r, []string{"graphql-ws"})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	defer conn.Close()
+	if conn.Subprotocol() != "graphql-ws" {
+		t.Errorf("subprotocol got %q, want graphql-ws", conn.Subprotocol())
+	}
+	read := func() appSyncMessage {
+		var msg appSyncMessage
+		_, data, err := conn.ReadMessage()
+		if err == nil {
+			err = json.Unmarshal(data, &msg)
+		}
+		if err != nil {
+			msg.Type = "closed"
+		}
+		return msg
+	}
+	send := func(s string) {
+		conn.WriteMessage(websocket.TextMessage, []byte(s))
+	}
+	if msg := read(); msg.Type != "connection_init" {
+		t.Errorf("message got %q, want connection_init", msg.Type)
+		return
+	}
+	send(`{"type":"connection_ack","payload":{"connectionTimeoutMs":300000}}`)
+	start := read()
+	if start.Type != "start" || start.ID == "" {
+		t.Errorf("message got %q, want start", start.Type)
+		return
+	}
+	var payload struct {
+		Data       string
+		Extensions struct {
+			Authorization map[string]string
+		}
+	}
+	if err := json.Unmarshal(start.Payload, &payload); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !strings.Contains(payload.Data, `"variables":{"id":"1"}`) {
+		t.Errorf("data got %s, want the query and variables", payload.Data)
+	}
+	if payload.Extensions.Authorization["x-api-key"] != f.key {
+		t.Errorf("authorization got %v, want the API key", payload.Extensions.Authorization)
+	}
+	f.mu.Lock()
+	f.header, f.auth, f.data = header, payload.Extensions.Authorization, payload.Data
+	f.mu.Unlock()
+	if f.failStart {
+		send(`{"id":"` + start.ID + `","type":"error","payload":{"errors":[{"errorType":"UnsupportedOperation","message":"unknown subscription"}]}}`)
+		return
+	}
+	send(`{"id":"` + start.ID + `","type":"start_ack"}`)
+	send(`{"type":"ka"}`)
+	for _, event := range f.events {
+		send(`{"id":"` + start.ID + `","type":"data","payload":{"data":` + event + `}}`)
+	}
+	if msg := read(); msg.Type == "stop" && msg.ID == start.ID {
+		f.mu.Lock()
+		f.stopped = true
+		f.mu.Unlock()
+		send(`{"id":"` + start.ID + `","type":"complete"}`)
+	}
+}
+
+func decodeBase64JSON(s string, v any) error {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+type subscriptionMetrics struct {
+	Metrics
+	mu     sync.Mutex
+	events []SubscriptionEvent
+}
+
+func (m *subscriptionMetrics) ObserveSubscription(e SubscriptionEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, e)
+}
+
+func TestAppSyncSubscribe(t *testing.T) {
+	fake := &fakeAppSync{t: t, key: "da2-key", events: []string{`{"onItem":{"id":"1"}}`, `{"onItem":{"id":"2"}}`}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	metrics := &subscriptionMetrics{}
+	client := NewClient(srv.URL+"/graphql", WithAppSync(AppSync{Auth: AppSyncAPIKey("da2-key")}), WithMetrics(metrics))
+	req := NewRequest(`subscription OnItem($id: ID) { onItem(id: $id) { id } }`)
+	req.Var("id", "1")
+	sub, err := client.Subscribe(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []string
+	for len(ids) < 2 && sub.Next() {
+		var event struct{ OnItem struct{ ID string } }
+		if err := sub.Decode(&event); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, event.OnItem.ID)
+	}
+	if err := sub.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sub.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(ids, ",") != "1,2" {
+		t.Errorf("events got %v, want [1 2]", ids)
+	}
+	if sub.Next() {
+		t.Errorf("Next got true after Close")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		fake.mu.Lock()
+		stopped := fake.stopped
+		fake.mu.Unlock()
+		if stopped || time.Now().After(deadline) {
+			if !stopped {
+				t.Errorf("subscription was not stopped")
+			}
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if len(metrics.events) != 2 {
+		t.Fatalf("events got %+v, want started and ended", metrics.events)
+	}
+	if e := metrics.events[0]; e.Operation != "OnItem" || e.State != SubscriptionStarted {
+		t.Errorf("event got %+v, want OnItem started", e)
+	}
+	if e := metrics.events[1]; e.State != SubscriptionEnded || e.Messages != 2 || e.Err != nil {
+		t.Errorf("event got %+v, want ended after 2 messages", e)
+	}
+}
+
+func TestAppSyncSubscribeLikeRequests(t *testing.T) {
+	fake := &fakeAppSync{t: t, key: "da2-key", events: []string{`{"onItem":{"id":"1"}}`}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	tokens := TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		return &Token{AccessToken: "tok"}, nil
+	})
+	client := NewClient("",
+		WithEndpointResolver(func(ctx context.Context, req *Request) (string, error) {
+			return srv.URL + "/graphql", nil
+		}),
+		WithAppSync(AppSync{Auth: AppSyncAPIKey("da2-key")}),
+		WithHeader("X-App", "app"),
+		WithTokenSource(tokens),
+	)
+	ctx = ContextWithMetadata(ctx, Metadata{
+		Header:     http.Header{"X-Tenant-Id": {"acme"}},
+		Extensions: map[string]any{"tenant": "acme"},
+	})
+	req := NewRequest(`subscription OnItem($id: ID) { onItem(id: $id) { id } }`)
+	req.Var("id", "1")
+	req.Header.Set("X-Request-Id", "r1")
+	sub, err := client.Subscribe(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sub.Next()
+	sub.Close()
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	want := map[string]string{"x-app": "app", "x-tenant-id": "acme", "x-request-id": "r1", "Authorization": "Bearer tok"}
+	for key, value := range want {
+		if fake.header[key] != value {
+			t.Errorf("handshake %s got %q, want %q", key, fake.header[key], value)
+		}
+		if fake.auth[key] != value {
+			t.Errorf("start %s got %q, want %q", key, fake.auth[key], value)
+		}
+	}
+	if !strings.Contains(fake.data, `"extensions":{"tenant":"acme"}`) {
+		t.Errorf("data got %s, want the metadata extensions", fake.data)
+	}
+}
+
+func TestAppSyncSubscribeErrors(t *testing.T) {
+	fake := &fakeAppSync{t: t, key: "da2-key", failStart: true}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL+"/graphql", WithAppSync(AppSync{Auth: AppSyncAPIKey("da2-key")}))
+	req := NewRequest(`subscription { onItem { id } }`)
+	req.Var("id", "1")
+	_, err := client.Subscribe(ctx, req)
+	if err == nil || err.Error() != "graphql: unknown subscription" {
+		t.Errorf("error got %v, want graphql: unknown subscription", err)
+	}
+
+	_, err = NewClient(srv.URL).Subscribe(ctx, NewRequest(`subscription { onItem { id } }`))
+	if err == nil {
+		t.Errorf("expected an error without WithAppSync")
+	}
+}
+
+func TestAppSyncSubscribeCancel(t *testing.T) {
+	fake := &fakeAppSync{t: t, key: "da2-key"}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL+"/graphql", WithAppSync(AppSync{Auth: AppSyncAPIKey("da2-key")}))
+	subCtx, cancelSub := context.WithCancel(ctx)
+	req := NewRequest(`subscription { onItem { id } }`)
+	req.Var("id", "1")
+	sub, err := client.Subscribe(subCtx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer sub.Close()
+	time.AfterFunc(10*time.Millisecond, cancelSub)
+	if sub.Next() {
+		t.Errorf("Next got true, want the subscription to end")
+	}
+	if err := sub.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("error got %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestAppSyncQuery(t *testing.T) {
+	srv := httptest.NewServer(&fakeAppSync{t: t, key: "da2-key"})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var resp struct{ GetItem struct{ ID string } }
+	client := NewClient(srv.URL+"/graphql", WithAppSync(AppSync{Auth: AppSyncAPIKey("da2-key")}))
+	if err := client.Run(ctx, NewRequest(`query { getItem { id } }`), &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.GetItem.ID != "1" {
+		t.Errorf("id got %q, want 1", resp.GetItem.ID)
+	}
+
+	metrics := &codeMetrics{}
+	client = NewClient(srv.URL+"/graphql", WithAppSync(AppSync{Auth: AppSyncAPIKey("wrong")}), WithMetrics(metrics))
+	err := client.Run(ctx, NewRequest(`query { getItem { id } }`), &resp)
+	if err == nil || err.Error() != "graphql: You are not authorized to make this call." {
+		t.Errorf("error got %v, want the AppSync error", err)
+	}
+	if strings.Join(metrics.codes, ",") != "UnauthorizedException" {
+		t.Errorf("error codes got %q, want [UnauthorizedException]", metrics.codes)
+	}
+}
+
+type codeMetrics struct {
+	Metrics
+	codes []string
+}
+
+func (m *codeMetrics) ObserveRequest(r RequestMetrics) {
+	m.codes = r.ErrorCodes
+}
+
+func TestAppSyncIAM(t *testing.T) {
+	client := NewClient("https://example.appsync-api.eu-west-1.amazonaws.com/graphql", WithAppSync(AppSync{
+		Auth: &SigV4Signer{
+			Region:      "eu-west-1",
+			Credentials: AWSCredentials{AccessKeyID: "id", SecretAccessKey: "secret", SessionToken: "session"},
+		},
+	}))
+	u, err := url.Parse("https://example.appsync-api.eu-west-1.amazonaws.com/graphql/connect")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := client.appSyncAuth(context.Background(), u, NewRequest("subscription { onItem }"), Metadata{}, &call{}, []byte("{}"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var header map[string]string
+	if err := json.Unmarshal(data, &header); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, key := range []string{"accept", "content-encoding", "content-type", "host", "x-amz-date", "X-Amz-Security-Token", "Authorization"} {
+		if header[key] == "" {
+			t.Errorf("header %s missing from %v", key, header)
+		}
+	}
+	if !strings.HasPrefix(header["Authorization"], "AWS4-HMAC-SHA256 Credential=id/") {
+		t.Errorf("Authorization got %q, want a SigV4 signature", header["Authorization"])
+	}
+}
+
+func TestAppSyncRealtimeEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{"https://abc.appsync-api.eu-west-1.amazonaws.com/graphql", "wss://abc.appsync-realtime-api.eu-west-1.amazonaws.com/graphql"},
+		{"https://api.example.com/graphql", "wss://api.example.com/graphql/realtime"},
+		{"http://localhost:8080/graphql/", "ws://localhost:8080/graphql/realtime"},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.endpoint)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := appSyncRealtimeEndpoint(u); got != tt.want {
+			t.Errorf("%s got %q, want %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expiryDelta is how long before its expiry a token is refreshed, so
+// that it does not expire in flight.
+const expiryDelta = 10 * time.Second
+
+// Token is a credential sent in the Authorization header.
+type Token struct {
+	AccessToken string
+	// TokenType is the scheme of the Authorization header, "Bearer" if
+	// empty.
+	TokenType string
+	// Expiry is when the token expires, or zero if it does not.
+	Expiry time.Time
+}
+
+// Valid reports whether t is set and not about to expire.
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry))
+}
+
+func (t *Token) header() string {
+	typ := t.TokenType
+	if typ == "" || strings.EqualFold(typ, "bearer") {
+		typ = "Bearer"
+	}
+	return typ + " " + t.AccessToken
+}
+
+// TokenSource gets new tokens.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+// Token calls f.
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// WithTokenSource authenticates every request with a token from src,
+// unless the Authorization header is set on the Request or with
+// WithHeader. A token is reused until it expires, and only one request
+// at a time gets a new one.
+//
+// If the server responds with 401 Unauthorized or a GraphQL error with
+// the code UNAUTHENTICATED, the token is refreshed and the request is
+// sent once more. Requests with files are not sent again.
+func WithTokenSource(src TokenSource) ClientOption {
+	return func(client *Client) {
+		client.tokens = &tokenCache{src: src}
+	}
+}
+
+// tokenCache caches the token of a TokenSource.
+type tokenCache struct {
+	src TokenSource
+
+	mu     sync.Mutex
+	tok    *Token
+	flight *tokenFlight
+}
+
+// tokenFlight is a refresh in progress.
+type tokenFlight struct {
+	done chan struct{}
+	tok  *Token
+	err  error
+}
+
+// token returns the cached token, or gets a new one if it is not valid.
+func (c *tokenCache) token(ctx context.Context) (*Token, error) {
+	for {
+		c.mu.Lock()
+		if c.tok.Valid() {
+			tok := c.tok
+			c.mu.Unlock()
+			return tok, nil
+		}
+		if f := c.flight; f != nil {
+			c.mu.Unlock()
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			if f.err != nil && ctx.Err() == nil && (errors.Is(f.err, context.Canceled) || errors.Is(f.err, context.DeadlineExceeded)) {
+				// The refresh was abandoned by its caller; try again.
+				continue
+			}
+			return f.tok, f.err
+		}
+		f := &tokenFlight{done: make(chan struct{})}
+		c.flight = f
+		c.mu.Unlock()
+
+		f.tok, f.err = c.src.Token(ctx)
+		if f.err == nil && f.tok == nil {
+			f.err = errors.New("graphql: token source returned no token")
+		}
+		c.mu.Lock()
+		c.flight = nil
+		if f.err == nil {
+			c.tok = f.tok
+		}
+		c.mu.Unlock()
+		close(f.done)
+		return f.tok, f.err
+	}
+}
+
+// invalidate drops tok from the cache if it is still there, so that the
+// next request gets a new token.
+func (c *tokenCache) invalidate(tok *Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tok == tok {
+		c.tok = nil
+	}
+}
+
+// authorize sets the Authorization header of r.
+func (c *Client) authorize(ctx context.Context, r *http.Request, call *call) error {
+	if c.tokens == nil || r.Header.Get("Authorization") != "" {
+		return nil
+	}
+	tok, err := c.tokens.token(ctx)
+	if err != nil {
+		return fmt.Errorf("graphql: getting token: %w", err)
+	}
+	call.token = tok
+	r.Header.Set("Authorization", tok.header())
+	return nil
+}
+
+// retryAuth reports whether a request should be sent again with a new
+// token, and drops the token it was sent with.
+func (c *Client) retryAuth(req *Request, call *call) bool {
+	if c.tokens == nil || call.token == nil || call.authRetried || len(req.files) > 0 {
+		return false
+	}
+	unauthenticated := call.status == http.StatusUnauthorized
+	for _, code := range call.errorCodes {
+		unauthenticated = unauthenticated || code == "UNAUTHENTICATED"
+	}
+	if !unauthenticated {
+		return false
+	}
+	c.tokens.invalidate(call.token)
+	call.authRetried = true
+	call.retries++
+	if call.body != nil {
+		call.body = &bodyCapture{limit: call.body.limit}
+	}
+	return true
+}
+
+// ClientCredentials is a TokenSource that gets tokens with the OAuth 2.0
+// client credentials grant (RFC 6749, section 4.4).
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// EndpointParams are added to the token request.
+	EndpointParams url.Values
+	// AuthInParams sends the client ID and secret in the request body
+	// rather than with HTTP Basic authentication.
+	AuthInParams bool
+	// HTTPClient makes the token requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+// TokenError is an error response from a token endpoint.
+type TokenError struct {
+	StatusCode  int
+	Code        string
+	Description string
+}
+
+func (e *TokenError) Error() string {
+	msg := fmt.Sprintf("graphql: token endpoint returned %d", e.StatusCode)
+	if e.Code != "" {
+		msg += ": " + e.Code
+	}
+	if e.Description != "" {
+		msg += ": " + e.Description
+	}
+	return msg
+}
+
+// Token requests a new token from the token endpoint.
+func (cc *ClientCredentials) Token(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(cc.Scopes) > 0 {
+		form.Set("scope", strings.Join(cc.Scopes, " "))
+	}
+	for key, values := range cc.EndpointParams {
+		form[key] = values
+	}
+	if cc.AuthInParams {
+		form.Set("client_id", cc.ClientID)
+		form.Set("client_secret", cc.ClientSecret)
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, cc.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Accept", "application/json")
+	if !cc.AuthInParams {
+		r.SetBasicAuth(url.QueryEscape(cc.ClientID), url.QueryEscape(cc.ClientSecret))
+	}
+	httpClient := cc.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := httpClient.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("reading token response: %w", err)
+	}
+	var tr struct {
+		AccessToken      string      `json:"access_token"`
+		TokenType        string      `json:"token_type"`
+		ExpiresIn        json.Number `json:"expires_in"`
+		Error            string      `json:"error"`
+		ErrorDescription string      `json:"error_description"`
+	}
+	jsonErr := json.Unmarshal(body, &tr)
+	if res.StatusCode != http.StatusOK || tr.Error != "" {
+		return nil, &TokenError{StatusCode: res.StatusCode, Code: tr.Error, Description: tr.ErrorDescription}
+	}
+	if jsonErr != nil {
+		return nil, fmt.Errorf("decoding token response: %w", jsonErr)
+	}
+	if tr.AccessToken == "" {
+		return nil, errors.New("graphql: token response has no access_token")
+	}
+	tok := &Token{AccessToken: tr.AccessToken, TokenType: tr.TokenType}
+	if tr.ExpiresIn != "" {
+		secs, err := tr.ExpiresIn.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("decoding token response: invalid expires_in %q", tr.ExpiresIn)
+		}
+		if secs > 0 {
+			tok.Expiry = time.Now().Add(time.Duration(secs) * time.Second)
+		}
+	}
+	return tok, nil
+}
@@ -0,0 +1,287 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingTokens returns the tokens "token-1", "token-2" and so on.
+type countingTokens struct {
+	n      atomic.Int32
+	expiry time.Duration
+	delay  time.Duration
+}
+
+func (s *countingTokens) Token(ctx context.Context) (*Token, error) {
+	time.Sleep(s.delay)
+	tok := &Token{AccessToken: fmt.Sprintf("token-%d", s.n.Add(1))}
+	if s.expiry != 0 {
+		tok.Expiry = time.Now().Add(s.expiry)
+	}
+	return tok, nil
+}
+
+func TestTokenSource(t *testing.T) {
+	var auth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	tokens := &countingTokens{}
+	client := NewClient(srv.URL, WithTokenSource(tokens))
+	for range 2 {
+		if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	req := NewRequest("query {}")
+	req.Header.Set("Authorization", "Basic abc")
+	if err := client.Run(ctx, req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"Bearer token-1", "Bearer token-1", "Basic abc"}
+	if strings.Join(auth, ",") != strings.Join(want, ",") {
+		t.Errorf("Authorization got %q, want %q", auth, want)
+	}
+}
+
+func TestTokenSourceExpiry(t *testing.T) {
+	var auth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// Tokens that expire within expiryDelta are never reused.
+	tokens := &countingTokens{expiry: expiryDelta / 2}
+	client := NewClient(srv.URL, WithTokenSource(tokens))
+	for range 2 {
+		if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	want := []string{"Bearer token-1", "Bearer token-2"}
+	if strings.Join(auth, ",") != strings.Join(want, ",") {
+		t.Errorf("Authorization got %q, want %q", auth, want)
+	}
+}
+
+func TestTokenSourceRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(w http.ResponseWriter, auth string)
+		retries int
+		wantErr string
+	}{
+		{
+			name: "401",
+			handler: func(w http.ResponseWriter, auth string) {
+				if auth != "Bearer token-2" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				io.WriteString(w, `{"data":{"value":"ok"}}`)
+			},
+			retries: 1,
+		},
+		{
+			name: "unauthenticated",
+			handler: func(w http.ResponseWriter, auth string) {
+				if auth != "Bearer token-2" {
+					io.WriteString(w, `{"data":null,"errors":[{"message":"expired","extensions":{"code":"UNAUTHENTICATED"}}]}`)
+					return
+				}
+				io.WriteString(w, `{"data":{"value":"ok"}}`)
+			},
+			retries: 1,
+		},
+		{
+			name: "once",
+			handler: func(w http.ResponseWriter, auth string) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			retries: 1,
+			wantErr: "graphql: server returned a non-200 status code: 401",
+		},
+		{
+			name: "other error",
+			handler: func(w http.ResponseWriter, auth string) {
+				io.WriteString(w, `{"data":null,"errors":[{"message":"forbidden","extensions":{"code":"FORBIDDEN"}}]}`)
+			},
+			wantErr: "graphql: forbidden",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(w, r.Header.Get("Authorization"))
+			}))
+			defer srv.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			metrics := &retryMetrics{}
+			client := NewClient(srv.URL, WithTokenSource(&countingTokens{}), WithMetrics(metrics))
+			var resp struct{ Value string }
+			err := client.Run(ctx, NewRequest("query {}"), &resp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error got %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if resp.Value != "ok" {
+				t.Errorf("value got %q, want %q", resp.Value, "ok")
+			}
+			if metrics.retries != tt.retries {
+				t.Errorf("retries got %d, want %d", metrics.retries, tt.retries)
+			}
+		})
+	}
+}
+
+type retryMetrics struct {
+	Metrics
+	retries int
+}
+
+func (m *retryMetrics) ObserveRequest(r RequestMetrics) {
+	m.retries = r.Retries
+}
+
+func TestTokenSourceSingleRefresh(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	tokens := &countingTokens{delay: 10 * time.Millisecond}
+	client := NewClient(srv.URL, WithTokenSource(tokens))
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- client.Run(ctx, NewRequest("query {}"), nil)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if n := tokens.n.Load(); n != 2 {
+		t.Errorf("tokens got %d, want 2", n)
+	}
+}
+
+func TestTokenSourceError(t *testing.T) {
+	srv := responseServer(t, `{"data":{}}`)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	errNoToken := errors.New("no token")
+	client := NewClient(srv.URL, WithTokenSource(TokenSourceFunc(func(context.Context) (*Token, error) {
+		return nil, errNoToken
+	})))
+	err := client.Run(ctx, NewRequest("query {}"), nil)
+	if !errors.Is(err, errNoToken) {
+		t.Errorf("error got %v, want %v", err, errNoToken)
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	var requests atomic.Int32
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client" || secret != "s%C3%A9cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"error":"invalid_client","error_description":"bad credentials"}`)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if got := r.PostForm.Get("grant_type"); got != "client_credentials" {
+			t.Errorf("grant_type got %q, want client_credentials", got)
+		}
+		if got := r.PostForm.Get("scope"); got != "read write" {
+			t.Errorf("scope got %q, want %q", got, "read write")
+		}
+		if got := r.PostForm.Get("audience"); got != "api" {
+			t.Errorf("audience got %q, want api", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"access_token":"abc","token_type":"bearer","expires_in":3600}`)
+	}))
+	defer tokenSrv.Close()
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	cc := &ClientCredentials{
+		TokenURL:       tokenSrv.URL,
+		ClientID:       "client",
+		ClientSecret:   "sécret",
+		Scopes:         []string{"read", "write"},
+		EndpointParams: map[string][]string{"audience": {"api"}},
+	}
+	tok, err := cc.Token(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tok.AccessToken != "abc" || time.Until(tok.Expiry) < 59*time.Minute {
+		t.Errorf("got %+v, want abc expiring in an hour", tok)
+	}
+
+	client := NewClient(srv.URL, WithTokenSource(cc))
+	for range 2 {
+		if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if auth != "Bearer abc" {
+		t.Errorf("Authorization got %q, want %q", auth, "Bearer abc")
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("token requests got %d, want 2", n)
+	}
+
+	cc.ClientSecret = "wrong"
+	_, err = cc.Token(ctx)
+	var tokenErr *TokenError
+	if !errors.As(err, &tokenErr) || tokenErr.Code != "invalid_client" || tokenErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("error got %v, want invalid_client", err)
+	}
+}
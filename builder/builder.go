@@ -0,0 +1,430 @@
+// Package builder builds GraphQL documents programmatically, for
+// queries whose shape is only known at run time:
+//
+//	op := builder.Query().Name("GetUser").
+//	    Variable("id", "ID!", id).
+//	    Select(
+//	        builder.Field("user").Arg("id", builder.Var("id")).Select(
+//	            builder.Field("name"),
+//	            builder.On("Admin", builder.Field("level")),
+//	        ),
+//	    )
+//	req, err := op.Request()
+//
+// Names are checked and argument values are encoded as GraphQL
+// literals, so untrusted input cannot change the structure of the
+// document. Every variable that is used must be declared, and every
+// fragment that is spread must be added with Operation.Fragment.
+package builder
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/razzkumar/go-graphql"
+	"github.com/razzkumar/go-graphql/language"
+)
+
+// Args are the arguments of a directive.
+type Args map[string]any
+
+// Selection is a field, inline fragment or fragment spread.
+type Selection interface {
+	selection(b *build) language.Selection
+}
+
+// Operation builds a query, mutation or subscription.
+type Operation struct {
+	op         language.Operation
+	name       string
+	vars       []*variable
+	directives []*directive
+	selections []Selection
+	fragments  []*FragmentBuilder
+}
+
+type variable struct {
+	name, typ string
+	value     any
+	bound     bool
+	def       any
+	hasDef    bool
+}
+
+// Query starts a query.
+func Query() *Operation {
+	return &Operation{op: language.Query}
+}
+
+// Mutation starts a mutation.
+func Mutation() *Operation {
+	return &Operation{op: language.Mutation}
+}
+
+// Subscription starts a subscription.
+func Subscription() *Operation {
+	return &Operation{op: language.Subscription}
+}
+
+// Name sets the operation name.
+func (o *Operation) Name(name string) *Operation {
+	o.name = name
+	return o
+}
+
+// Variable declares the variable name with the GraphQL type typ, such as
+// "[ID!]!", and binds value to it in the request.
+func (o *Operation) Variable(name, typ string, value any) *Operation {
+	o.vars = append(o.vars, &variable{name: name, typ: typ, value: value, bound: true})
+	return o
+}
+
+// DefaultVariable declares the variable name with the GraphQL type typ
+// and a default value, without binding a value to it.
+func (o *Operation) DefaultVariable(name, typ string, defaultValue any) *Operation {
+	o.vars = append(o.vars, &variable{name: name, typ: typ, def: defaultValue, hasDef: true})
+	return o
+}
+
+// Directive applies a directive to the operation.
+func (o *Operation) Directive(name string, args Args) *Operation {
+	o.directives = append(o.directives, &directive{name, args})
+	return o
+}
+
+// Select adds selections to the operation.
+func (o *Operation) Select(sel ...Selection) *Operation {
+	o.selections = append(o.selections, sel...)
+	return o
+}
+
+// Fragment adds fragment definitions that the operation spreads.
+func (o *Operation) Fragment(frags ...*FragmentBuilder) *Operation {
+	o.fragments = append(o.fragments, frags...)
+	return o
+}
+
+// Document builds the document of the operation and its fragments.
+func (o *Operation) Document() (*language.QueryDocument, error) {
+	b := &build{used: make(map[string]bool), spread: make(map[string]bool)}
+	def := &language.OperationDefinition{
+		Operation:  o.op,
+		Name:       o.name,
+		Directives: b.directives(o.directives),
+	}
+	if o.name != "" {
+		b.checkName("operation", o.name)
+	}
+	declared := make(map[string]bool)
+	for _, v := range o.vars {
+		b.checkName("variable", v.name)
+		if declared[v.name] {
+			b.errorf("variable $%s declared more than once", v.name)
+		}
+		declared[v.name] = true
+		typ, err := language.ParseType(v.typ)
+		if err != nil {
+			b.errorf("variable $%s: invalid type %q", v.name, v.typ)
+			continue
+		}
+		vd := &language.VariableDefinition{Variable: v.name, Type: typ}
+		if v.hasDef {
+			vd.DefaultValue = b.value(v.def)
+		}
+		def.VariableDefinitions = append(def.VariableDefinitions, vd)
+	}
+	if len(o.selections) == 0 {
+		b.errorf("%s has no selections", o.op)
+	}
+	def.SelectionSet = b.selectionSet(o.selections)
+
+	doc := &language.QueryDocument{Operations: []*language.OperationDefinition{def}}
+	defined := make(map[string]bool)
+	for _, f := range o.fragments {
+		if defined[f.name] {
+			b.errorf("fragment %s defined more than once", f.name)
+		}
+		defined[f.name] = true
+		doc.Fragments = append(doc.Fragments, f.definition(b))
+	}
+
+	for _, name := range sortedKeys(b.used) {
+		if !declared[name] {
+			b.errorf("variable $%s is not declared", name)
+		}
+	}
+	for _, name := range sortedKeys(b.spread) {
+		if !defined[name] {
+			b.errorf("fragment %s is not defined", name)
+		}
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	return doc, nil
+}
+
+// Build builds the document text.
+func (o *Operation) Build() (string, error) {
+	doc, err := o.Document()
+	if err != nil {
+		return "", err
+	}
+	return language.Print(doc), nil
+}
+
+// Request builds a request for the operation with its variables bound.
+func (o *Operation) Request() (*graphql.Request, error) {
+	q, err := o.Build()
+	if err != nil {
+		return nil, err
+	}
+	req := graphql.NewRequest(q)
+	for _, v := range o.vars {
+		if v.bound {
+			req.Var(v.name, v.value)
+		}
+	}
+	return req, nil
+}
+
+// FieldBuilder builds a field selection.
+type FieldBuilder struct {
+	alias, name string
+	args        []*argument
+	directives  []*directive
+	selections  []Selection
+}
+
+type argument struct {
+	name  string
+	value any
+}
+
+type directive struct {
+	name string
+	args Args
+}
+
+// Field selects the field name.
+func Field(name string) *FieldBuilder {
+	return &FieldBuilder{name: name}
+}
+
+// Alias sets the response key of the field.
+func (f *FieldBuilder) Alias(alias string) *FieldBuilder {
+	f.alias = alias
+	return f
+}
+
+// Arg adds an argument. The value may be a Var, an Enum, or a Go value
+// that is encoded as a GraphQL literal.
+func (f *FieldBuilder) Arg(name string, value any) *FieldBuilder {
+	f.args = append(f.args, &argument{name, value})
+	return f
+}
+
+// Directive applies a directive to the field.
+func (f *FieldBuilder) Directive(name string, args Args) *FieldBuilder {
+	f.directives = append(f.directives, &directive{name, args})
+	return f
+}
+
+// Include applies @include(if: cond).
+func (f *FieldBuilder) Include(cond any) *FieldBuilder {
+	return f.Directive("include", Args{"if": cond})
+}
+
+// Skip applies @skip(if: cond).
+func (f *FieldBuilder) Skip(cond any) *FieldBuilder {
+	return f.Directive("skip", Args{"if": cond})
+}
+
+// Select adds sub-selections to the field.
+func (f *FieldBuilder) Select(sel ...Selection) *FieldBuilder {
+	f.selections = append(f.selections, sel...)
+	return f
+}
+
+func (f *FieldBuilder) selection(b *build) language.Selection {
+	b.checkName("field", f.name)
+	if f.alias != "" {
+		b.checkName("alias", f.alias)
+	}
+	field := &language.Field{
+		Alias:        f.alias,
+		Name:         f.name,
+		Directives:   b.directives(f.directives),
+		SelectionSet: b.selectionSet(f.selections),
+	}
+	for _, arg := range f.args {
+		b.checkName("argument", arg.name)
+		field.Arguments = append(field.Arguments, &language.Argument{Name: arg.name, Value: b.value(arg.value)})
+	}
+	return field
+}
+
+// InlineFragmentBuilder builds an inline fragment.
+type InlineFragmentBuilder struct {
+	on         string
+	directives []*directive
+	selections []Selection
+}
+
+// On builds an inline fragment with the type condition typ, or none if
+// typ is empty.
+func On(typ string, sel ...Selection) *InlineFragmentBuilder {
+	return &InlineFragmentBuilder{on: typ, selections: sel}
+}
+
+// Directive applies a directive to the inline fragment.
+func (f *InlineFragmentBuilder) Directive(name string, args Args) *InlineFragmentBuilder {
+	f.directives = append(f.directives, &directive{name, args})
+	return f
+}
+
+func (f *InlineFragmentBuilder) selection(b *build) language.Selection {
+	if f.on != "" {
+		b.checkName("type", f.on)
+	}
+	if len(f.selections) == 0 {
+		b.errorf("inline fragment on %s has no selections", f.on)
+	}
+	return &language.InlineFragment{
+		TypeCondition: f.on,
+		Directives:    b.directives(f.directives),
+		SelectionSet:  b.selectionSet(f.selections),
+	}
+}
+
+// SpreadBuilder builds a fragment spread.
+type SpreadBuilder struct {
+	name       string
+	directives []*directive
+}
+
+// Spread spreads the named fragment.
+func Spread(name string) *SpreadBuilder {
+	return &SpreadBuilder{name: name}
+}
+
+// Directive applies a directive to the spread.
+func (s *SpreadBuilder) Directive(name string, args Args) *SpreadBuilder {
+	s.directives = append(s.directives, &directive{name, args})
+	return s
+}
+
+func (s *SpreadBuilder) selection(b *build) language.Selection {
+	b.checkName("fragment", s.name)
+	b.spread[s.name] = true
+	return &language.FragmentSpread{Name: s.name, Directives: b.directives(s.directives)}
+}
+
+// FragmentBuilder builds a named fragment definition.
+type FragmentBuilder struct {
+	name, on   string
+	directives []*directive
+	selections []Selection
+}
+
+// Fragment defines the fragment name on the type typ.
+func Fragment(name, typ string) *FragmentBuilder {
+	return &FragmentBuilder{name: name, on: typ}
+}
+
+// Directive applies a directive to the fragment.
+func (f *FragmentBuilder) Directive(name string, args Args) *FragmentBuilder {
+	f.directives = append(f.directives, &directive{name, args})
+	return f
+}
+
+// Select adds selections to the fragment.
+func (f *FragmentBuilder) Select(sel ...Selection) *FragmentBuilder {
+	f.selections = append(f.selections, sel...)
+	return f
+}
+
+func (f *FragmentBuilder) definition(b *build) *language.FragmentDefinition {
+	b.checkName("fragment", f.name)
+	b.checkName("type", f.on)
+	if len(f.selections) == 0 {
+		b.errorf("fragment %s has no selections", f.name)
+	}
+	return &language.FragmentDefinition{
+		Name:          f.name,
+		TypeCondition: f.on,
+		Directives:    b.directives(f.directives),
+		SelectionSet:  b.selectionSet(f.selections),
+	}
+}
+
+// build holds the state of a Document call: the first error and the
+// variables and fragments referenced.
+type build struct {
+	err    error
+	used   map[string]bool
+	spread map[string]bool
+}
+
+func (b *build) errorf(format string, args ...any) {
+	if b.err == nil {
+		b.err = fmt.Errorf("builder: "+format, args...)
+	}
+}
+
+func (b *build) checkName(what, name string) {
+	if !isName(name) {
+		b.errorf("invalid %s name %q", what, name)
+	}
+}
+
+func (b *build) selectionSet(sels []Selection) language.SelectionSet {
+	var set language.SelectionSet
+	for _, sel := range sels {
+		if sel == nil {
+			b.errorf("nil selection")
+			continue
+		}
+		set = append(set, sel.selection(b))
+	}
+	return set
+}
+
+func (b *build) directives(ds []*directive) []*language.Directive {
+	var out []*language.Directive
+	for _, d := range ds {
+		b.checkName("directive", d.name)
+		dir := &language.Directive{Name: d.name}
+		for _, name := range sortedKeys(d.args) {
+			b.checkName("argument", name)
+			dir.Arguments = append(dir.Arguments, &language.Argument{Name: name, Value: b.value(d.args[name])})
+		}
+		out = append(out, dir)
+	}
+	return out
+}
+
+// isName reports whether s is a GraphQL name.
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
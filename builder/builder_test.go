@@ -0,0 +1,129 @@
+package builder
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/razzkumar/go-graphql/language"
+)
+
+func TestBuild(t *testing.T) {
+	op := Query().Name("GetUser").
+		Variable("id", "ID!", "1").
+		DefaultVariable("n", "Int", 10).
+		Variable("withEmail", "Boolean!", true).
+		Select(
+			Field("user").Arg("id", Var("id")).Select(
+				Field("name"),
+				Field("email").Include(Var("withEmail")),
+				Field("friends").Alias("first").
+					Arg("first", Var("n")).
+					Arg("filter", map[string]any{"role": Enum("ADMIN"), "name": `a"b`, "ids": []int{1, 2}}).
+					Select(Spread("UserFields")),
+				On("Admin", Field("level")),
+			),
+		).
+		Fragment(Fragment("UserFields", "User").Select(Field("id"), Field("name")))
+	got, err := op.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `query GetUser($id: ID!, $n: Int = 10, $withEmail: Boolean!) {
+  user(id: $id) {
+    name
+    email @include(if: $withEmail)
+    first: friends(first: $n, filter: {ids: [1, 2], name: "a\"b", role: ADMIN}) {
+      ...UserFields
+    }
+    ... on Admin {
+      level
+    }
+  }
+}
+
+fragment UserFields on User {
+  id
+  name
+}
+`
+	if got != want {
+		t.Errorf("Build got:\n%s\nwant:\n%s", got, want)
+	}
+	if _, err := language.ParseQuery(got); err != nil {
+		t.Errorf("built document does not parse: %v", err)
+	}
+
+	req, err := op.Request()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	vars := req.Vars()
+	if vars["id"] != "1" || vars["withEmail"] != true {
+		t.Errorf("vars got %v", vars)
+	}
+	if _, ok := vars["n"]; ok {
+		t.Errorf("default variable n was bound: %v", vars)
+	}
+}
+
+func TestBuildValues(t *testing.T) {
+	type filter struct {
+		Name  string    `json:"name"`
+		Since time.Time `json:"since"`
+		Tags  []string  `json:"tags,omitempty"`
+	}
+	var nilPtr *int
+	got, err := Mutation().Select(
+		Field("update").
+			Arg("filter", filter{Name: "x", Since: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}).
+			Arg("ratio", 0.5).
+			Arg("count", uint8(3)).
+			Arg("big", json.Number("12345678901234567890")).
+			Arg("exp", json.Number("-1.5e+3")).
+			Arg("none", nilPtr).
+			Arg("text", "line\n}{ injected"),
+	).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `mutation {
+  update(filter: {name: "x", since: "2024-01-02T00:00:00Z"}, ratio: 0.5, count: 3, big: 12345678901234567890, exp: -1.5e+3, none: null, text: "line\n}{ injected")
+}
+`
+	if got != want {
+		t.Errorf("Build got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		op   *Operation
+		want string
+	}{
+		{Query().Select(Field("user { admin }")), `invalid field name "user { admin }"`},
+		{Query().Select(Field("user").Alias("a:b")), `invalid alias name "a:b"`},
+		{Query().Select(Field("user").Arg("id", Var("id"))), "variable $id is not declared"},
+		{Query().Select(Field("user").Select(Spread("F"))), "fragment F is not defined"},
+		{Query().Variable("id", "ID!!", 1).Select(Field("a")), `invalid type "ID!!"`},
+		{Query().Variable("id", "ID", 1).Variable("id", "ID", 2).Select(Field("a").Arg("id", Var("id"))), "declared more than once"},
+		{Query().Select(Field("a").Arg("e", Enum("true"))), `invalid enum value "true"`},
+		{Query(), "query has no selections"},
+		{Query().Select(Field("a").Arg("n", json.Number("+1"))), `invalid number "+1"`},
+		{Query().Select(Field("a").Arg("n", json.Number("01"))), `invalid number "01"`},
+		{Query().Select(Field("a").Arg("n", json.Number("0x1p4"))), `invalid number "0x1p4"`},
+		{Query().Select(Field("a").Arg("n", json.Number("Inf"))), `invalid number "Inf"`},
+		{Query().Select(Field("a").Arg("n", json.Number(" 1"))), `invalid number " 1"`},
+		{Query().Select(Field("a").Arg("f", math.NaN())), "invalid number NaN"},
+		{Query().Select(Field("a").Arg("f", math.Inf(1))), "invalid number +Inf"},
+		{Query().Select(Field("a").Arg("f", float32(math.Inf(-1)))), "invalid number -Inf"},
+	}
+	for _, tt := range tests {
+		_, err := tt.op.Build()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("err got %v, want %q", err, tt.want)
+		}
+	}
+}
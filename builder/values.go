@@ -0,0 +1,120 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/razzkumar/go-graphql/language"
+)
+
+// Var refers to a variable of the operation.
+type Var string
+
+// Enum is an enum value, written without quotes.
+type Enum string
+
+// value converts a Go value to a GraphQL literal.
+func (b *build) value(v any) *language.Value {
+	switch v := v.(type) {
+	case nil:
+		return &language.Value{Kind: language.NullValue, Raw: "null"}
+	case Var:
+		b.checkName("variable", string(v))
+		b.used[string(v)] = true
+		return &language.Value{Kind: language.Variable, Raw: string(v)}
+	case Enum:
+		if !isName(string(v)) || v == "true" || v == "false" || v == "null" {
+			b.errorf("invalid enum value %q", string(v))
+		}
+		return &language.Value{Kind: language.EnumValue, Raw: string(v)}
+	case *language.Value:
+		return v
+	case string:
+		return &language.Value{Kind: language.StringValue, Raw: v}
+	case bool:
+		return &language.Value{Kind: language.BooleanValue, Raw: strconv.FormatBool(v)}
+	case json.Number:
+		// The text is written as is, so it must be a GraphQL number and
+		// nothing else.
+		num, err := language.ParseValue(string(v))
+		if err != nil || num.Kind != language.IntValue && num.Kind != language.FloatValue || num.Raw != string(v) {
+			b.errorf("invalid number %q", string(v))
+			return b.value(nil)
+		}
+		return &language.Value{Kind: num.Kind, Raw: string(v)}
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return b.value(nil)
+		}
+		return b.value(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &language.Value{Kind: language.IntValue, Raw: strconv.FormatInt(rv.Int(), 10)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &language.Value{Kind: language.IntValue, Raw: strconv.FormatUint(rv.Uint(), 10)}
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			b.errorf("invalid number %v", f)
+			return b.value(nil)
+		}
+		return &language.Value{Kind: language.FloatValue, Raw: strconv.FormatFloat(f, 'g', -1, 64)}
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return b.value(nil)
+		}
+		list := &language.Value{Kind: language.ListValue}
+		for i := 0; i < rv.Len(); i++ {
+			list.Children = append(list.Children, &language.ChildValue{Value: b.value(rv.Index(i).Interface())})
+		}
+		return list
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			if rv.IsNil() {
+				return b.value(nil)
+			}
+			obj := &language.Value{Kind: language.ObjectValue}
+			keys := rv.MapKeys()
+			names := make([]string, len(keys))
+			for i, k := range keys {
+				names[i] = k.String()
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				b.checkName("object field", name)
+				obj.Children = append(obj.Children, &language.ChildValue{
+					Name:  name,
+					Value: b.value(rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key())).Interface()),
+				})
+			}
+			return obj
+		}
+	case reflect.String:
+		return &language.Value{Kind: language.StringValue, Raw: rv.String()}
+	case reflect.Bool:
+		return &language.Value{Kind: language.BooleanValue, Raw: strconv.FormatBool(rv.Bool())}
+	}
+
+	// Other values, such as structs and time.Time, are encoded the way
+	// they would be sent as variables.
+	data, err := json.Marshal(v)
+	if err != nil {
+		b.errorf("encoding argument: %v", err)
+		return b.value(nil)
+	}
+	var decoded any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&decoded); err != nil {
+		b.errorf("encoding argument: %v", err)
+		return b.value(nil)
+	}
+	return b.value(decoded)
+}
@@ -0,0 +1,150 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/razzkumar/go-graphql/language"
+)
+
+// call records what happened in one request, for the logger, tracer
+// and metrics.
+type call struct {
+	start         time.Time
+	operation     string
+	operationType string
+	status        int
+	requestSize   int64
+	responseSize  int64
+	errors        int
+	errorCodes    []string
+	// retries counts the times the request was sent again, and token is
+	// the token it was last sent with.
+	retries     int
+	authRetried bool
+	token       *Token
+	// endpoint is the URL the request was last sent to. With
+	// WithEndpoints, endpointState is its state, failed are the
+	// endpoints that failed, and roundTripErr is the error of the last
+	// round trip.
+	endpoint      string
+	endpointState *endpointState
+	failed        []*endpointState
+	roundTripErr  error
+	// body captures the start of the response body when it is logged.
+	body *bodyCapture
+	span Span
+	// timer records the phases of the round trip, and readTime the time
+	// spent waiting for the response body.
+	timer    timer
+	readTime time.Duration
+	timing   Timing
+}
+
+// startCall starts recording a request. It returns the context to make
+// the request with.
+func (c *Client) startCall(ctx context.Context, req *Request) (context.Context, *call) {
+	cl := &call{start: time.Now()}
+	if c.logger != nil || c.tracer != nil || c.metrics != nil || c.pool != nil {
+		cl.operationType, cl.operation = operationInfo(req.q)
+	}
+	if c.logger != nil && c.logger.Enabled(ctx, slog.LevelDebug) {
+		cl.body = &bodyCapture{limit: c.bodyLimit()}
+	}
+	if c.tracer != nil {
+		attrs := []Attribute{
+			{AttrOperationType, cl.operationType},
+			{AttrDocumentHash, documentHash(req.q)},
+		}
+		if cl.operation != "" {
+			attrs = append(attrs, Attribute{AttrOperationName, cl.operation})
+		}
+		if u, err := url.Parse(c.endpoint); err == nil && !c.dynamicEndpoint() {
+			attrs = append(attrs, Attribute{AttrServerAddress, u.Hostname()})
+		}
+		ctx, cl.span = c.tracer.Start(ctx, spanName(cl.operationType, cl.operation), attrs)
+	}
+	return ctx, cl
+}
+
+func (c *Client) endCall(ctx context.Context, cl *call, err error) {
+	cl.timing = cl.timer.result(cl.start)
+	c.logCall(ctx, cl, err)
+	if c.metrics != nil {
+		c.metrics.ObserveRequest(RequestMetrics{
+			Operation:     cl.operation,
+			OperationType: cl.operationType,
+			Duration:      cl.timing.Total,
+			StatusCode:    cl.status,
+			ErrorCodes:    cl.errorCodes,
+			Err:           err,
+			RequestSize:   cl.requestSize,
+			ResponseSize:  cl.responseSize,
+			Retries:       cl.retries,
+			Timing:        cl.timing,
+		})
+	}
+	if cl.span != nil {
+		attrs := []Attribute{
+			{AttrErrorCount, cl.errors},
+			{AttrRequestBodySize, cl.requestSize},
+			{AttrResponseBodySize, cl.responseSize},
+		}
+		if cl.status != 0 {
+			attrs = append(attrs, Attribute{AttrStatusCode, cl.status})
+		}
+		if u, err := url.Parse(cl.endpoint); err == nil && c.dynamicEndpoint() {
+			// The endpoint is only known once the request was sent.
+			attrs = append(attrs, Attribute{AttrServerAddress, u.Hostname()})
+		}
+		cl.span.SetAttributes(attrs...)
+		cl.span.End(err)
+	}
+}
+
+func (cl *call) setErrors(errs []graphErr) {
+	cl.errors = len(errs)
+	cl.errorCodes = cl.errorCodes[:0]
+	for _, e := range errs {
+		cl.errorCodes = append(cl.errorCodes, e.code)
+	}
+}
+
+// capture returns a reader of r that captures what is read if the body
+// is logged.
+func (cl *call) capture(r io.Reader) io.Reader {
+	if cl.body == nil {
+		return r
+	}
+	return io.TeeReader(r, cl.body)
+}
+
+// operationInfo gets the type and name of the operation in the query q,
+// or empty strings if q does not parse or has several operations.
+func operationInfo(q string) (typ, name string) {
+	doc, err := language.ParseQuery(q)
+	if err != nil || len(doc.Operations) != 1 {
+		return "", ""
+	}
+	op := doc.Operations[0]
+	return string(op.Operation), op.Name
+}
+
+// countingBody counts the bytes read from a response body into n, and
+// the time spent reading into wait.
+type countingBody struct {
+	io.ReadCloser
+	n    *int64
+	wait *time.Duration
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := b.ReadCloser.Read(p)
+	*b.wait += time.Since(start)
+	*b.n += int64(n)
+	return n, err
+}
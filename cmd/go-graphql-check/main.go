@@ -0,0 +1,100 @@
+// Command go-graphql-check reports which client operations a schema
+// would break.
+//
+// Usage:
+//
+//	go-graphql-check -schema new.graphql [-old old.graphql] [-manifest persisted.json] [-json] [paths...]
+//
+// Operations are read from .graphql and .gql files under paths and
+// from a persisted query manifest. Every operation is validated against
+// the schema given by -schema. With -old, the schema diff is printed
+// as well and only operations that are valid against the old schema
+// but fail against the new one are reported.
+//
+// The exit status is 1 if any operation fails.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/razzkumar/go-graphql/schema"
+	"github.com/razzkumar/go-graphql/validator"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "go-graphql-check:", err)
+		os.Exit(2)
+	}
+}
+
+func run() error {
+	var (
+		schemaPath   = flag.String("schema", "", "schema to check against (.graphql SDL or .json introspection)")
+		oldPath      = flag.String("old", "", "previous schema; only report operations broken by the change")
+		manifestPath = flag.String("manifest", "", "persisted query manifest")
+		jsonOutput   = flag.Bool("json", false, "write the report as JSON")
+	)
+	flag.Parse()
+	if *schemaPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	newSchema, err := schema.LoadFile(*schemaPath)
+	if err != nil {
+		return err
+	}
+	ops, err := validator.LoadOperationFiles(flag.Args()...)
+	if err != nil {
+		return err
+	}
+	if *manifestPath != "" {
+		data, err := os.ReadFile(*manifestPath)
+		if err != nil {
+			return err
+		}
+		manifestOps, err := validator.LoadPersistedQueries(data)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, manifestOps...)
+	}
+
+	var changes schema.Changes
+	var report *validator.Report
+	if *oldPath != "" {
+		oldSchema, err := schema.LoadFile(*oldPath)
+		if err != nil {
+			return err
+		}
+		changes = schema.Diff(oldSchema, newSchema)
+		report = validator.CheckImpact(oldSchema, newSchema, ops)
+	} else {
+		report = validator.CheckOperations(newSchema, ops)
+	}
+
+	if *jsonOutput {
+		out := struct {
+			Changes    schema.Changes    `json:"changes,omitempty"`
+			Operations *validator.Report `json:"operations"`
+		}{changes, report}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+	} else {
+		if *oldPath != "" {
+			fmt.Print(changes)
+			fmt.Println()
+		}
+		fmt.Print(report)
+	}
+	if !report.OK() {
+		os.Exit(1)
+	}
+	return nil
+}
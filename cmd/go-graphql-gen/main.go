@@ -0,0 +1,76 @@
+// Command go-graphql-gen generates type-safe Go functions from GraphQL
+// operations.
+//
+// Usage:
+//
+//	go-graphql-gen [-config graphql.json] [-schema schema.graphql] [-out generated.go] [-package name] [paths...]
+//
+// Settings are read from the JSON config file described by
+// codegen.Config, which defaults to graphql.json when it exists. Flags
+// and operation paths given on the command line override the config.
+//
+// It is typically run with go generate:
+//
+//	//go:generate go run github.com/razzkumar/go-graphql/cmd/go-graphql-gen -config graphql.json
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/razzkumar/go-graphql/codegen"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "go-graphql-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		configPath = flag.String("config", "", "config file (default graphql.json if present)")
+		schemaPath = flag.String("schema", "", "schema (.graphql SDL or .json introspection)")
+		output     = flag.String("out", "", "generated Go file")
+		pkg        = flag.String("package", "", "package name of the generated file")
+	)
+	flag.Parse()
+
+	cfg := &codegen.Config{}
+	path := *configPath
+	if path == "" {
+		if _, err := os.Stat("graphql.json"); err == nil {
+			path = "graphql.json"
+		}
+	}
+	if path != "" {
+		loaded, err := codegen.LoadConfig(path)
+		if err != nil && !(errors.Is(err, fs.ErrNotExist) && *configPath == "") {
+			return err
+		}
+		if loaded != nil {
+			cfg = loaded
+		}
+	}
+	if *schemaPath != "" {
+		cfg.Schema = *schemaPath
+	}
+	if *output != "" {
+		cfg.Output = *output
+	}
+	if *pkg != "" {
+		cfg.Package = *pkg
+	}
+	if flag.NArg() > 0 {
+		cfg.Operations = flag.Args()
+	}
+	if cfg.Schema == "" || len(cfg.Operations) == 0 || cfg.Output == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	return codegen.WriteFile(cfg)
+}
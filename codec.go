@@ -0,0 +1,92 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Codec encodes requests and decodes responses. JSONCodec, which uses
+// encoding/json, is the default; another Codec can be used to plug in a
+// faster or stricter JSON package with WithCodec.
+//
+// Response data is given to the Codec with the response object passed
+// to Run, which may implement json.Unmarshaler, so the Codec must call
+// UnmarshalJSON methods as encoding/json does.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	// NewDecoder returns a Decoder reading a stream of values from r.
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Decoder decodes values from a stream, like a json.Decoder. If it has
+// a UseNumber method, the UseNumber option calls it.
+type Decoder interface {
+	Decode(v any) error
+}
+
+// JSONCodec is a Codec using encoding/json.
+type JSONCodec struct {
+	// UseNumber decodes numbers into an any as json.Number.
+	UseNumber bool
+	// DisallowUnknownFields makes decoding an object into a struct fail
+	// if the object has a key that does not match a field.
+	DisallowUnknownFields bool
+}
+
+// Marshal calls json.Marshal.
+func (c JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes data, which must hold a single JSON value, into v.
+func (c JSONCodec) Unmarshal(data []byte, v any) error {
+	if !c.UseNumber && !c.DisallowUnknownFields {
+		return json.Unmarshal(data, v)
+	}
+	dec := c.newDecoder(bytes.NewReader(data))
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+// NewDecoder returns a json.Decoder reading r.
+func (c JSONCodec) NewDecoder(r io.Reader) Decoder {
+	return c.newDecoder(r)
+}
+
+func (c JSONCodec) newDecoder(r io.Reader) *json.Decoder {
+	dec := json.NewDecoder(r)
+	if c.UseNumber {
+		dec.UseNumber()
+	}
+	if c.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	return dec
+}
+
+// WithCodec encodes requests and decodes responses with codec:
+//
+//	NewClient(endpoint, WithCodec(graphql.JSONCodec{DisallowUnknownFields: true}))
+func WithCodec(codec Codec) ClientOption {
+	return func(client *Client) {
+		client.codec = codec
+	}
+}
+
+// newDecoder returns a Decoder of codec reading r, which decodes
+// numbers as json.Number if useNumber is set and it supports that.
+func newDecoder(codec Codec, r io.Reader, useNumber bool) Decoder {
+	dec := codec.NewDecoder(r)
+	if n, ok := dec.(interface{ UseNumber() }); ok && useNumber {
+		n.UseNumber()
+	}
+	return dec
+}
@@ -0,0 +1,143 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingCodec is a JSONCodec that counts its calls.
+type countingCodec struct {
+	JSONCodec
+	marshals, unmarshals, decoders int
+}
+
+func (c *countingCodec) Marshal(v any) ([]byte, error) {
+	c.marshals++
+	return c.JSONCodec.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v any) error {
+	c.unmarshals++
+	return c.JSONCodec.Unmarshal(data, v)
+}
+
+func (c *countingCodec) NewDecoder(r io.Reader) Decoder {
+	c.decoders++
+	return c.JSONCodec.NewDecoder(r)
+}
+
+func TestWithCodec(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		io.WriteString(w, `{"data":{"name":"Ada"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	codec := &countingCodec{}
+	client := NewClient(srv.URL, WithCodec(codec))
+	req := NewRequest("query {}")
+	req.Var("id", 1)
+	var resp struct{ Name string }
+	if err := client.Run(ctx, req, &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Name != "Ada" {
+		t.Errorf("name got %q, want Ada", resp.Name)
+	}
+	if got, want := body, `{"query":"query {}","variables":{"id":1}}`+"\n"; got != want {
+		t.Errorf("body got %s, want %s", got, want)
+	}
+	if codec.marshals != 1 || codec.decoders != 1 {
+		t.Errorf("got %d marshals and %d decoders, want 1 and 1", codec.marshals, codec.decoders)
+	}
+}
+
+func TestWithCodecMultipart(t *testing.T) {
+	var variables string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		variables = r.FormValue("variables")
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	codec := &countingCodec{}
+	client := NewClient(srv.URL, UseMultipartForm(), WithCodec(codec))
+	req := NewRequest("query {}")
+	req.Var("id", 1)
+	if err := client.Run(ctx, req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := variables, `{"id":1}`+"\n"; got != want {
+		t.Errorf("variables got %q, want %q", got, want)
+	}
+	if codec.marshals != 1 {
+		t.Errorf("got %d marshals, want 1", codec.marshals)
+	}
+}
+
+func TestDisallowUnknownFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		wantErr  string
+	}{
+		{
+			name:     "known fields",
+			response: `{"data":{"name":"Ada"},"extensions":{"cost":1}}`,
+		},
+		{
+			name:     "error details",
+			response: `{"data":null,"errors":[{"message":"boom","locations":[{"line":1,"column":2}],"path":["user"],"extensions":{"code":"X"}}]}`,
+			wantErr:  "graphql: boom",
+		},
+		{
+			name:     "unknown field",
+			response: `{"data":{"name":"Ada","age":36}}`,
+			wantErr:  `decoding response: json: unknown field "age"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := responseServer(t, tt.response)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			client := NewClient(srv.URL, WithCodec(JSONCodec{DisallowUnknownFields: true}))
+			var resp struct{ Name string }
+			err := client.Run(ctx, NewRequest("query {}"), &resp)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Fatalf("err got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJSONCodecUnmarshal(t *testing.T) {
+	codec := JSONCodec{UseNumber: true}
+	var v any
+	if err := codec.Unmarshal([]byte(`{"n":1.10}`), &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := v.(map[string]any)["n"], json.Number("1.10"); got != want {
+		t.Errorf("n got %#v, want %#v", got, want)
+	}
+	err := codec.Unmarshal([]byte(`{} {}`), &v)
+	if err == nil || !strings.Contains(err.Error(), "after top-level value") {
+		t.Errorf("err got %v, want trailing data error", err)
+	}
+}
@@ -0,0 +1,117 @@
+package codegen
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/razzkumar/go-graphql/schema"
+	"github.com/razzkumar/go-graphql/validator"
+)
+
+var update = flag.Bool("update", false, "update the generated example")
+
+func TestGenerateExample(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join("internal", "example", "config.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := Generate(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *update {
+		if err := os.WriteFile(cfg.Output, got, 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	want, err := os.ReadFile(cfg.Output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s is out of date; run go test ./codegen -update", cfg.Output)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	s, err := schema.LoadSDL(`
+		type User { id: ID!, name: String }
+		type Query { user(id: ID!): User }
+		type Subscription { userAdded: User }
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`{ user(id: 1) { id } }`, "operations must be named"},
+		{`query Q { user { id } }`, `argument "id" of type "ID!" is required`},
+		{`subscription S { userAdded { id } }`, "subscription S is not supported"},
+		{`query Q { user(id: 1) { id } } query q { user(id: 2) { id } }`, "operation q defined more than once"},
+	}
+	for _, tt := range tests {
+		_, err := GenerateFrom(s, []validator.Operation{{Source: "q.graphql", Query: tt.query}}, &Config{})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err got %v, want %q", tt.query, err, tt.want)
+		}
+	}
+}
+
+func TestGenerateScalars(t *testing.T) {
+	s, err := schema.LoadSDL(`
+		scalar UUID
+		scalar JSON
+		type Query { id(in: UUID): UUID!, data: JSON, ids: [UUID] }
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg := &Config{
+		Package: "api",
+		Scalars: map[string]string{"UUID": "github.com/google/uuid.UUID"},
+	}
+	src, err := GenerateFrom(s, []validator.Operation{{Query: `query Q($in: UUID) { id(in: $in) data ids }`}}, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"package api",
+		`"github.com/google/uuid"`,
+		"ID   uuid.UUID    `json:\"id\"`",
+		"Data any          `json:\"data\"`",
+		"IDs  []*uuid.UUID `json:\"ids\"`",
+		"func Q(ctx context.Context, client *graphql.Client, in *uuid.UUID) (*QResponse, error)",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		name, exported, unexported string
+	}{
+		{"userId", "UserID", "userID"},
+		{"user_id", "UserID", "userID"},
+		{"USER_ID", "UserID", "userID"},
+		{"HTMLBody", "HTMLBody", "htmlBody"},
+		{"type", "Type", "type_"},
+		{"client", "Client", "client_"},
+		{"__typename", "Typename", "typename"},
+		{"userIds", "UserIDs", "userIDs"},
+	}
+	for _, tt := range tests {
+		if got := exportedName(tt.name); got != tt.exported {
+			t.Errorf("exportedName(%q) got %q, want %q", tt.name, got, tt.exported)
+		}
+		if got := unexportedName(tt.name); got != tt.unexported {
+			t.Errorf("unexportedName(%q) got %q, want %q", tt.name, got, tt.unexported)
+		}
+	}
+}
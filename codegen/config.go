@@ -0,0 +1,93 @@
+// Package codegen generates type-safe Go functions from GraphQL
+// operations.
+//
+// For each named operation it emits a function that runs it with a
+// graphql.Client, a struct for its variables and structs for its
+// response:
+//
+//	func GetUser(ctx context.Context, client *graphql.Client, id string) (*GetUserResponse, error)
+//
+// Enums become string types with a constant per value. Fields of
+// interface and union types become Go interfaces with an
+// implementation per possible object type, picked using __typename.
+// Named fragments on object types become structs that are embedded
+// wherever they are spread.
+//
+// The go-graphql-gen command runs the generator from a config file.
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Config controls code generation. It is usually read from a JSON
+// file with LoadConfig:
+//
+//	{
+//	    "schema": "schema.graphql",
+//	    "operations": ["queries/"],
+//	    "output": "api/generated.go",
+//	    "package": "api",
+//	    "scalars": {
+//	        "DateTime": "time.Time",
+//	        "UUID": "github.com/google/uuid.UUID"
+//	    }
+//	}
+type Config struct {
+	// Schema is the path of the schema, as SDL or an introspection
+	// result.
+	Schema string `json:"schema"`
+	// Operations lists .graphql files or directories containing the
+	// operations to generate code for.
+	Operations []string `json:"operations"`
+	// Output is the path of the generated Go file.
+	Output string `json:"output"`
+	// Package is the package name of the generated file. It defaults to
+	// the name of the output directory.
+	Package string `json:"package"`
+	// Scalars maps GraphQL scalar names to Go types. Types from other
+	// packages are written as "import/path.Type". Built-in scalars may
+	// be overridden; other custom scalars default to any.
+	Scalars map[string]string `json:"scalars"`
+}
+
+// LoadConfig reads a Config from a JSON file. Relative paths in the
+// config are resolved against the directory containing it.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	cfg.Schema = resolve(cfg.Schema)
+	cfg.Output = resolve(cfg.Output)
+	for i, op := range cfg.Operations {
+		cfg.Operations[i] = resolve(op)
+	}
+	return &cfg, nil
+}
+
+func (cfg *Config) packageName() string {
+	if cfg.Package != "" {
+		return cfg.Package
+	}
+	if cfg.Output != "" {
+		if abs, err := filepath.Abs(cfg.Output); err == nil {
+			return filepath.Base(filepath.Dir(abs))
+		}
+	}
+	return "generated"
+}
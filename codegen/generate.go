@@ -0,0 +1,346 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/razzkumar/go-graphql/language"
+	"github.com/razzkumar/go-graphql/schema"
+	"github.com/razzkumar/go-graphql/validator"
+)
+
+// Generate loads the schema and operations named by cfg and returns
+// the generated Go source.
+func Generate(cfg *Config) ([]byte, error) {
+	s, err := schema.LoadFile(cfg.Schema)
+	if err != nil {
+		return nil, err
+	}
+	ops, err := validator.LoadOperationFiles(cfg.Operations...)
+	if err != nil {
+		return nil, err
+	}
+	return GenerateFrom(s, ops, cfg)
+}
+
+// GenerateFrom generates Go source for ops. Only the Package and
+// Scalars fields of cfg are used.
+func GenerateFrom(s *schema.Schema, ops []validator.Operation, cfg *Config) ([]byte, error) {
+	g := &generator{
+		schema:    s,
+		cfg:       cfg,
+		fragments: make(map[string]*language.FragmentDefinition),
+		imports:   make(map[string]string),
+		names:     make(map[string]bool),
+		enums:     make(map[string]bool),
+		inputs:    make(map[string]bool),
+		generated: make(map[string]bool),
+	}
+	if report := validator.CheckOperations(s, ops); !report.OK() {
+		return nil, fmt.Errorf("invalid operations:\n%s", report)
+	}
+	var operations []*language.OperationDefinition
+	for _, op := range ops {
+		doc, err := language.ParseQuery(op.Query)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op.Source, err)
+		}
+		for _, def := range doc.Operations {
+			if def.Name == "" {
+				return nil, fmt.Errorf("%s: %s: operations must be named", op.Source, def.Position)
+			}
+			if def.Operation == language.Subscription {
+				return nil, fmt.Errorf("%s: %s: subscription %s is not supported", op.Source, def.Position, def.Name)
+			}
+			if g.names[exportedName(def.Name)] {
+				return nil, fmt.Errorf("%s: %s: operation %s defined more than once", op.Source, def.Position, def.Name)
+			}
+			g.names[exportedName(def.Name)] = true
+			operations = append(operations, def)
+		}
+		for _, frag := range doc.Fragments {
+			if _, ok := g.fragments[frag.Name]; ok {
+				return nil, fmt.Errorf("%s: %s: fragment %s defined more than once", op.Source, frag.Position, frag.Name)
+			}
+			g.fragments[frag.Name] = frag
+			g.names[exportedName(frag.Name)] = true
+		}
+	}
+	for _, t := range s.Types {
+		if t.Kind == schema.Enum || t.Kind == schema.InputObject {
+			g.names[exportedName(t.Name)] = true
+		}
+	}
+	for _, frag := range g.fragments {
+		frag.SelectionSet = g.addTypename(s.Types[frag.TypeCondition], frag.SelectionSet)
+	}
+	for _, op := range operations {
+		op.SelectionSet = g.addTypename(s.RootType(op.Operation), op.SelectionSet)
+		if err := g.operation(op); err != nil {
+			return nil, err
+		}
+	}
+	return g.source()
+}
+
+// WriteFile generates code for cfg and writes it to cfg.Output.
+func WriteFile(cfg *Config) error {
+	if cfg.Output == "" {
+		return fmt.Errorf("codegen: no output file configured")
+	}
+	src, err := Generate(cfg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cfg.Output, src, 0o644)
+}
+
+// knownPackages names packages whose name differs from their path.
+var knownPackages = map[string]string{
+	"github.com/razzkumar/go-graphql": "graphql",
+}
+
+type generator struct {
+	schema    *schema.Schema
+	cfg       *Config
+	fragments map[string]*language.FragmentDefinition
+	// imports maps import paths to package names.
+	imports map[string]string
+	// names holds the Go type names in use.
+	names map[string]bool
+	enums map[string]bool
+	// inputs and generated track which input objects and fragment
+	// structs have been emitted.
+	inputs    map[string]bool
+	generated map[string]bool
+	decls     []string
+}
+
+// addTypename adds __typename to every selection on an interface or
+// union so responses can be decoded into the right Go type. It returns
+// the updated selection set.
+func (g *generator) addTypename(parent *schema.Type, set language.SelectionSet) language.SelectionSet {
+	if parent == nil {
+		return set
+	}
+	if parent.IsAbstract() {
+		has := false
+		for _, sel := range set {
+			if f, ok := sel.(*language.Field); ok && f.Name == "__typename" && f.Alias == "" {
+				has = true
+			}
+		}
+		if !has {
+			set = append(language.SelectionSet{&language.Field{Name: "__typename"}}, set...)
+		}
+	}
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *language.Field:
+			if len(sel.SelectionSet) == 0 {
+				continue
+			}
+			if def := g.fieldDefinition(parent, sel.Name); def != nil {
+				sel.SelectionSet = g.addTypename(g.schema.Types[def.Type.Name()], sel.SelectionSet)
+			}
+		case *language.InlineFragment:
+			t := parent
+			if sel.TypeCondition != "" {
+				t = g.schema.Types[sel.TypeCondition]
+			}
+			sel.SelectionSet = g.addTypename(t, sel.SelectionSet)
+		}
+	}
+	return set
+}
+
+func (g *generator) fieldDefinition(parent *schema.Type, name string) *schema.Field {
+	switch {
+	case name == schema.TypenameMetaField.Name:
+		return schema.TypenameMetaField
+	case parent == g.schema.Query && name == schema.SchemaMetaField.Name:
+		return schema.SchemaMetaField
+	case parent == g.schema.Query && name == schema.TypeMetaField.Name:
+		return schema.TypeMetaField
+	}
+	return parent.Field(name)
+}
+
+func (g *generator) decl(format string, args ...any) {
+	g.decls = append(g.decls, fmt.Sprintf(format, args...))
+}
+
+func (g *generator) uniqueName(name string) string {
+	if !g.names[name] {
+		g.names[name] = true
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := name + strconv.Itoa(i)
+		if !g.names[candidate] {
+			g.names[candidate] = true
+			return candidate
+		}
+	}
+}
+
+func (g *generator) importName(importPath string) string {
+	if name, ok := g.imports[importPath]; ok {
+		return name
+	}
+	if name, ok := knownPackages[importPath]; ok {
+		g.imports[importPath] = name
+		return name
+	}
+	name := path.Base(importPath)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = path.Base(path.Dir(importPath))
+	}
+	name = strings.NewReplacer("-", "", ".", "").Replace(name)
+	g.imports[importPath] = name
+	return name
+}
+
+func (g *generator) operation(op *language.OperationDefinition) error {
+	name := exportedName(op.Name)
+	responseName := g.uniqueName(name + "Response")
+	if _, err := g.objectStruct(responseName, g.schema.RootType(op.Operation), op.SelectionSet); err != nil {
+		return fmt.Errorf("%s: %w", op.Name, err)
+	}
+
+	document := language.Print(&language.QueryDocument{
+		Operations: []*language.OperationDefinition{op},
+		Fragments:  g.usedFragments(op.SelectionSet),
+	})
+	g.decl("// %sDocument is the GraphQL document of the %s %s.\nconst %sDocument = %s\n\n",
+		name, op.Name, op.Operation, name, quoteRaw(document))
+
+	g.importName("context")
+	g.importName("github.com/razzkumar/go-graphql")
+	var params, body strings.Builder
+	if len(op.VariableDefinitions) > 0 {
+		varsName := g.uniqueName(name + "Variables")
+		var fields, init, set strings.Builder
+		for _, def := range op.VariableDefinitions {
+			goType, err := g.inputType(def.Type)
+			if err != nil {
+				return fmt.Errorf("%s: variable $%s: %w", op.Name, def.Variable, err)
+			}
+			param, field := unexportedName(def.Variable), exportedName(def.Variable)
+			tag := def.Variable
+			if !def.Type.NonNull {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&fields, "\t%s %s `json:%q`\n", field, goType, tag)
+			fmt.Fprintf(&params, ", %s %s", param, goType)
+			fmt.Fprintf(&init, "\t\t%s: %s,\n", field, param)
+			if !def.Type.NonNull && canBeNil(goType) {
+				fmt.Fprintf(&set, "\tif vars.%s != nil {\n\t\treq.Var(%q, vars.%s)\n\t}\n", field, def.Variable, field)
+			} else {
+				fmt.Fprintf(&set, "\treq.Var(%q, vars.%s)\n", def.Variable, field)
+			}
+		}
+		g.decl("// %s are the variables of the %s %s.\ntype %s struct {\n%s}\n\n",
+			varsName, op.Name, op.Operation, varsName, fields.String())
+		fmt.Fprintf(&body, "\tvars := %s{\n%s\t}\n\treq := graphql.NewRequest(%sDocument)\n%s", varsName, init.String(), name, set.String())
+	} else {
+		fmt.Fprintf(&body, "\treq := graphql.NewRequest(%sDocument)\n", name)
+	}
+	g.decl("// %s runs the %s %s.\nfunc %s(ctx context.Context, client *graphql.Client%s) (*%s, error) {\n%s"+
+		"\tvar resp %s\n\tif err := client.Run(ctx, req, &resp); err != nil {\n\t\treturn nil, err\n\t}\n\treturn &resp, nil\n}\n\n",
+		name, op.Name, op.Operation, name, params.String(), responseName, body.String(), responseName)
+	return nil
+}
+
+// usedFragments gets the fragment definitions spread by set, directly
+// or indirectly, in a stable order.
+func (g *generator) usedFragments(set language.SelectionSet) []*language.FragmentDefinition {
+	seen := make(map[string]bool)
+	var visit func(set language.SelectionSet)
+	visit = func(set language.SelectionSet) {
+		for _, sel := range set {
+			switch sel := sel.(type) {
+			case *language.Field:
+				visit(sel.SelectionSet)
+			case *language.InlineFragment:
+				visit(sel.SelectionSet)
+			case *language.FragmentSpread:
+				if seen[sel.Name] {
+					continue
+				}
+				seen[sel.Name] = true
+				if frag, ok := g.fragments[sel.Name]; ok {
+					visit(frag.SelectionSet)
+				}
+			}
+		}
+	}
+	visit(set)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	frags := make([]*language.FragmentDefinition, 0, len(names))
+	for _, name := range names {
+		frags = append(frags, g.fragments[name])
+	}
+	return frags
+}
+
+func isStdlib(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+func quoteRaw(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+func (g *generator) source() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by go-graphql-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.cfg.packageName())
+	g.enumDecls()
+	if len(g.imports) > 0 {
+		paths := make([]string, 0, len(g.imports))
+		for p := range g.imports {
+			paths = append(paths, p)
+		}
+		sort.Slice(paths, func(i, j int) bool {
+			if isStdlib(paths[i]) != isStdlib(paths[j]) {
+				return isStdlib(paths[i])
+			}
+			return paths[i] < paths[j]
+		})
+		buf.WriteString("import (\n")
+		for i, p := range paths {
+			if i > 0 && isStdlib(paths[i-1]) && !isStdlib(p) {
+				buf.WriteString("\n")
+			}
+			if name := g.imports[p]; name != path.Base(p) {
+				fmt.Fprintf(&buf, "\t%s %q\n", name, p)
+			} else {
+				fmt.Fprintf(&buf, "\t%q\n", p)
+			}
+		}
+		buf.WriteString(")\n\n")
+	}
+	for _, d := range g.decls {
+		buf.WriteString(d)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("codegen: formatting generated code: %w\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
@@ -0,0 +1,5 @@
+// Package example is generated from the schema and queries in this
+// directory. It is checked by the codegen tests.
+package example
+
+//go:generate go run ../../../cmd/go-graphql-gen -config config.json
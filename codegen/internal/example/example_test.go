@@ -0,0 +1,112 @@
+package example
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/razzkumar/go-graphql"
+)
+
+func newServer(t *testing.T, response string, vars *map[string]any) *graphql.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		if vars != nil {
+			*vars = body.Variables
+		}
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(srv.Close)
+	return graphql.NewClient(srv.URL)
+}
+
+func TestGetUser(t *testing.T) {
+	var vars map[string]any
+	client := newServer(t, `{"data":{"user":{"id":"1","name":"Ada","role":"ADMIN","email":null,
+		"createdAt":"2024-01-02T03:04:05Z","friends":[{"name":"Grace"}]}}}`, &vars)
+	resp, err := GetUser(context.Background(), client, "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := vars["id"], "1"; got != want {
+		t.Errorf("id variable got %v, want %v", got, want)
+	}
+	u := resp.User
+	if u.ID != "1" || u.Name != "Ada" || u.Role != RoleAdmin || u.Email != nil {
+		t.Errorf("user got %+v", u)
+	}
+	if want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC); !u.CreatedAt.Equal(want) {
+		t.Errorf("createdAt got %v, want %v", u.CreatedAt, want)
+	}
+	if len(u.Friends) != 1 || u.Friends[0].Name != "Grace" {
+		t.Errorf("friends got %+v", u.Friends)
+	}
+}
+
+func TestListUsersOptionalVariables(t *testing.T) {
+	var vars map[string]any
+	client := newServer(t, `{"data":{"users":[]}}`, &vars)
+	role := RoleGuest
+	if _, err := ListUsers(context.Background(), client, &UserFilter{Role: &role}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := vars["first"]; ok {
+		t.Errorf("first variable sent: %v", vars)
+	}
+	if got, want := vars["filter"], map[string]any{"role": "GUEST"}; !equalJSON(got, want) {
+		t.Errorf("filter variable got %v, want %v", got, want)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	client := newServer(t, `{"data":{"search":[
+		{"__typename":"User","id":"1","name":"Ada","role":"MEMBER"},
+		{"__typename":"Post","id":"2","title":"Notes","author":{"name":"Ada"}}
+	]}}`, nil)
+	resp, err := Search(context.Background(), client, "a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Search) != 2 {
+		t.Fatalf("search got %d results, want 2", len(resp.Search))
+	}
+	user, ok := resp.Search[0].(*SearchResponseSearchUser)
+	if !ok || user.Name != "Ada" || user.Role != RoleMember || user.GetTypename() != "User" {
+		t.Errorf("search[0] got %#v", resp.Search[0])
+	}
+	post, ok := resp.Search[1].(*SearchResponseSearchPost)
+	if !ok || post.Title != "Notes" || post.Author.Name != "Ada" {
+		t.Errorf("search[1] got %#v", resp.Search[1])
+	}
+}
+
+func TestGetNode(t *testing.T) {
+	client := newServer(t, `{"data":{"node":{"__typename":"Post","id":"2","title":"Notes"}}}`, nil)
+	resp, err := GetNode(context.Background(), client, "2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := resp.Node.GetID(), "2"; got != want {
+		t.Errorf("id got %q, want %q", got, want)
+	}
+
+	client = newServer(t, `{"data":{"node":{"__typename":"Comment","id":"3"}}}`, nil)
+	if _, err := GetNode(context.Background(), client, "3"); err == nil {
+		t.Error("expected error for unknown __typename")
+	}
+}
+
+func equalJSON(a, b any) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
@@ -0,0 +1,498 @@
+// Code generated by go-graphql-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	graphql "github.com/razzkumar/go-graphql"
+)
+
+// GetNodeResponse holds the fields selected on Query.
+type GetNodeResponse struct {
+	Node GetNodeResponseNode `json:"node"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *GetNodeResponse) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var raw struct {
+		Node json.RawMessage `json:"node"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if value, err := unmarshalGetNodeResponseNode(raw.Node); err != nil {
+		return err
+	} else {
+		v.Node = value
+	}
+	return nil
+}
+
+// GetNodeResponseNode is the selection on the Node interface.
+// It is implemented by *GetNodeResponseNodePost, *GetNodeResponseNodeUser.
+type GetNodeResponseNode interface {
+	isGetNodeResponseNode()
+	// GetTypename gets the __typename of the object.
+	GetTypename() string
+	GetID() string
+}
+
+func unmarshalGetNodeResponseNode(b json.RawMessage) (GetNodeResponseNode, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, nil
+	}
+	var tn struct {
+		Typename string `json:"__typename"`
+	}
+	if err := json.Unmarshal(b, &tn); err != nil {
+		return nil, err
+	}
+	var v GetNodeResponseNode
+	switch tn.Typename {
+	case "Post":
+		v = new(GetNodeResponseNodePost)
+	case "User":
+		v = new(GetNodeResponseNodeUser)
+	default:
+		return nil, fmt.Errorf("graphql: unexpected __typename %q for Node", tn.Typename)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// GetNodeResponseNodePost holds the fields selected on Post.
+type GetNodeResponseNodePost struct {
+	Typename string `json:"__typename"`
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+}
+
+func (v *GetNodeResponseNodePost) isGetNodeResponseNode() {}
+
+// GetTypename gets the __typename of v.
+func (v *GetNodeResponseNodePost) GetTypename() string { return v.Typename }
+
+// GetID gets the ID field of v.
+func (v *GetNodeResponseNodePost) GetID() string { return v.ID }
+
+// GetNodeResponseNodeUser holds the fields selected on User.
+type GetNodeResponseNodeUser struct {
+	Typename string `json:"__typename"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+}
+
+func (v *GetNodeResponseNodeUser) isGetNodeResponseNode() {}
+
+// GetTypename gets the __typename of v.
+func (v *GetNodeResponseNodeUser) GetTypename() string { return v.Typename }
+
+// GetID gets the ID field of v.
+func (v *GetNodeResponseNodeUser) GetID() string { return v.ID }
+
+// GetNodeDocument is the GraphQL document of the GetNode query.
+const GetNodeDocument = `query GetNode($id: ID!) {
+  node(id: $id) {
+    __typename
+    id
+    ... on User {
+      name
+    }
+    ... on Post {
+      title
+    }
+  }
+}
+`
+
+// GetNodeVariables are the variables of the GetNode query.
+type GetNodeVariables struct {
+	ID string `json:"id"`
+}
+
+// GetNode runs the GetNode query.
+func GetNode(ctx context.Context, client *graphql.Client, id string) (*GetNodeResponse, error) {
+	vars := GetNodeVariables{
+		ID: id,
+	}
+	req := graphql.NewRequest(GetNodeDocument)
+	req.Var("id", vars.ID)
+	var resp GetNodeResponse
+	if err := client.Run(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SearchResponse holds the fields selected on Query.
+type SearchResponse struct {
+	Search []SearchResponseSearch `json:"search"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *SearchResponse) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var raw struct {
+		Search []json.RawMessage `json:"search"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw.Search != nil {
+		v.Search = make([]SearchResponseSearch, len(raw.Search))
+		for i, item := range raw.Search {
+			value, err := unmarshalSearchResponseSearch(item)
+			if err != nil {
+				return err
+			}
+			v.Search[i] = value
+		}
+	}
+	return nil
+}
+
+// SearchResponseSearch is the selection on the SearchResult union.
+// It is implemented by *SearchResponseSearchPost, *SearchResponseSearchUser.
+type SearchResponseSearch interface {
+	isSearchResponseSearch()
+	// GetTypename gets the __typename of the object.
+	GetTypename() string
+}
+
+func unmarshalSearchResponseSearch(b json.RawMessage) (SearchResponseSearch, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, nil
+	}
+	var tn struct {
+		Typename string `json:"__typename"`
+	}
+	if err := json.Unmarshal(b, &tn); err != nil {
+		return nil, err
+	}
+	var v SearchResponseSearch
+	switch tn.Typename {
+	case "Post":
+		v = new(SearchResponseSearchPost)
+	case "User":
+		v = new(SearchResponseSearchUser)
+	default:
+		return nil, fmt.Errorf("graphql: unexpected __typename %q for SearchResult", tn.Typename)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SearchResponseSearchPost holds the fields selected on Post.
+type SearchResponseSearchPost struct {
+	Typename string                         `json:"__typename"`
+	ID       string                         `json:"id"`
+	Title    string                         `json:"title"`
+	Author   SearchResponseSearchPostAuthor `json:"author"`
+}
+
+// SearchResponseSearchPostAuthor holds the fields selected on User.
+type SearchResponseSearchPostAuthor struct {
+	Name string `json:"name"`
+}
+
+func (v *SearchResponseSearchPost) isSearchResponseSearch() {}
+
+// GetTypename gets the __typename of v.
+func (v *SearchResponseSearchPost) GetTypename() string { return v.Typename }
+
+// SearchResponseSearchUser holds the fields selected on User.
+type SearchResponseSearchUser struct {
+	UserFields
+	Typename string `json:"__typename"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *SearchResponseSearchUser) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(b, &v.UserFields); err != nil {
+		return err
+	}
+	var raw struct {
+		Typename string `json:"__typename"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	v.Typename = raw.Typename
+	return nil
+}
+
+// UserFields holds the fields selected on User.
+type UserFields struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+func (v *SearchResponseSearchUser) isSearchResponseSearch() {}
+
+// GetTypename gets the __typename of v.
+func (v *SearchResponseSearchUser) GetTypename() string { return v.Typename }
+
+// SearchDocument is the GraphQL document of the Search query.
+const SearchDocument = `query Search($text: String!) {
+  search(text: $text) {
+    __typename
+    ... on User {
+      ...UserFields
+    }
+    ... on Post {
+      id
+      title
+      author {
+        name
+      }
+    }
+  }
+}
+
+fragment UserFields on User {
+  id
+  name
+  role
+}
+`
+
+// SearchVariables are the variables of the Search query.
+type SearchVariables struct {
+	Text string `json:"text"`
+}
+
+// Search runs the Search query.
+func Search(ctx context.Context, client *graphql.Client, text string) (*SearchResponse, error) {
+	vars := SearchVariables{
+		Text: text,
+	}
+	req := graphql.NewRequest(SearchDocument)
+	req.Var("text", vars.Text)
+	var resp SearchResponse
+	if err := client.Run(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreatePostResponse holds the fields selected on Mutation.
+type CreatePostResponse struct {
+	CreatePost CreatePostResponseCreatePost `json:"createPost"`
+}
+
+// CreatePostResponseCreatePost holds the fields selected on Post.
+type CreatePostResponseCreatePost struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title"`
+	Tags  []string `json:"tags"`
+}
+
+// CreatePostDocument is the GraphQL document of the CreatePost mutation.
+const CreatePostDocument = `mutation CreatePost($input: CreatePostInput!) {
+  createPost(input: $input) {
+    id
+    title
+    tags
+  }
+}
+`
+
+// CreatePostInput is the CreatePostInput input type.
+type CreatePostInput struct {
+	Title string   `json:"title"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// CreatePostVariables are the variables of the CreatePost mutation.
+type CreatePostVariables struct {
+	Input CreatePostInput `json:"input"`
+}
+
+// CreatePost runs the CreatePost mutation.
+func CreatePost(ctx context.Context, client *graphql.Client, input CreatePostInput) (*CreatePostResponse, error) {
+	vars := CreatePostVariables{
+		Input: input,
+	}
+	req := graphql.NewRequest(CreatePostDocument)
+	req.Var("input", vars.Input)
+	var resp CreatePostResponse
+	if err := client.Run(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetUserResponse holds the fields selected on Query.
+type GetUserResponse struct {
+	User *GetUserResponseUser `json:"user"`
+}
+
+// GetUserResponseUser holds the fields selected on User.
+type GetUserResponseUser struct {
+	UserFields
+	Email     *string                      `json:"email"`
+	CreatedAt time.Time                    `json:"createdAt"`
+	Friends   []GetUserResponseUserFriends `json:"friends"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *GetUserResponseUser) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(b, &v.UserFields); err != nil {
+		return err
+	}
+	var raw struct {
+		Email     *string                      `json:"email"`
+		CreatedAt time.Time                    `json:"createdAt"`
+		Friends   []GetUserResponseUserFriends `json:"friends"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	v.Email = raw.Email
+	v.CreatedAt = raw.CreatedAt
+	v.Friends = raw.Friends
+	return nil
+}
+
+// GetUserResponseUserFriends holds the fields selected on User.
+type GetUserResponseUserFriends struct {
+	Name string `json:"name"`
+}
+
+// GetUserDocument is the GraphQL document of the GetUser query.
+const GetUserDocument = `query GetUser($id: ID!) {
+  user(id: $id) {
+    ...UserFields
+    email
+    createdAt
+    friends(first: 3) {
+      name
+    }
+  }
+}
+
+fragment UserFields on User {
+  id
+  name
+  role
+}
+`
+
+// GetUserVariables are the variables of the GetUser query.
+type GetUserVariables struct {
+	ID string `json:"id"`
+}
+
+// GetUser runs the GetUser query.
+func GetUser(ctx context.Context, client *graphql.Client, id string) (*GetUserResponse, error) {
+	vars := GetUserVariables{
+		ID: id,
+	}
+	req := graphql.NewRequest(GetUserDocument)
+	req.Var("id", vars.ID)
+	var resp GetUserResponse
+	if err := client.Run(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListUsersResponse holds the fields selected on Query.
+type ListUsersResponse struct {
+	Users []ListUsersResponseUsers `json:"users"`
+}
+
+// ListUsersResponseUsers holds the fields selected on User.
+type ListUsersResponseUsers struct {
+	UserFields
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *ListUsersResponseUsers) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(b, &v.UserFields); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListUsersDocument is the GraphQL document of the ListUsers query.
+const ListUsersDocument = `query ListUsers($filter: UserFilter, $first: Int) {
+  users(filter: $filter, first: $first) {
+    ...UserFields
+  }
+}
+
+fragment UserFields on User {
+  id
+  name
+  role
+}
+`
+
+// UserFilter is the UserFilter input type.
+type UserFilter struct {
+	// Only return users with this role.
+	Role         *Role      `json:"role,omitempty"`
+	Name         *string    `json:"name,omitempty"`
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+}
+
+// ListUsersVariables are the variables of the ListUsers query.
+type ListUsersVariables struct {
+	Filter *UserFilter `json:"filter,omitempty"`
+	First  *int        `json:"first,omitempty"`
+}
+
+// ListUsers runs the ListUsers query.
+func ListUsers(ctx context.Context, client *graphql.Client, filter *UserFilter, first *int) (*ListUsersResponse, error) {
+	vars := ListUsersVariables{
+		Filter: filter,
+		First:  first,
+	}
+	req := graphql.NewRequest(ListUsersDocument)
+	if vars.Filter != nil {
+		req.Var("filter", vars.Filter)
+	}
+	if vars.First != nil {
+		req.Var("first", vars.First)
+	}
+	var resp ListUsersResponse
+	if err := client.Run(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Role is the Role enum.
+//
+// The role of a user.
+type Role string
+
+const (
+	RoleAdmin  Role = "ADMIN"
+	RoleMember Role = "MEMBER"
+	RoleGuest  Role = "GUEST"
+)
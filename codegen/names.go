@@ -0,0 +1,88 @@
+package codegen
+
+import (
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// commonInitialisms are written in upper case in Go identifiers, as
+// golint recommends.
+var commonInitialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true,
+	"EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "LHS": true, "QPS": true,
+	"RAM": true, "RHS": true, "RPC": true, "SLA": true, "SMTP": true,
+	"SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true,
+	"UDP": true, "UI": true, "UID": true, "URI": true, "URL": true,
+	"UTF8": true, "UUID": true, "VM": true, "XML": true,
+}
+
+// splitWords splits a GraphQL name such as userId, user_id or USER_ID
+// into words.
+func splitWords(name string) []string {
+	var words []string
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if (unicode.IsLower(prev) || unicode.IsDigit(prev)) && unicode.IsUpper(cur) ||
+				unicode.IsUpper(prev) && unicode.IsUpper(cur) && nextLower {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// exportedName converts a GraphQL name to an exported Go identifier.
+func exportedName(name string) string {
+	var sb strings.Builder
+	for _, word := range splitWords(name) {
+		upper := strings.ToUpper(word)
+		if commonInitialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		if n := len(word); n > 2 && (word[n-1] == 's') && commonInitialisms[upper[:n-1]] {
+			sb.WriteString(upper[:n-1] + "s")
+			continue
+		}
+		if word == upper {
+			word = strings.ToLower(word)
+		}
+		sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	if sb.Len() == 0 {
+		return "X"
+	}
+	s := sb.String()
+	if !unicode.IsLetter(rune(s[0])) {
+		s = "X" + s
+	}
+	return s
+}
+
+// reservedParams are names used by generated function bodies.
+var reservedParams = map[string]bool{"ctx": true, "client": true, "req": true, "resp": true, "vars": true, "err": true}
+
+// unexportedName converts a GraphQL name to an unexported Go
+// identifier, suitable for function parameters.
+func unexportedName(name string) string {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return "x"
+	}
+	s := strings.ToLower(words[0]) + exportedName(strings.Join(words[1:], "_"))
+	if len(words) == 1 {
+		s = strings.ToLower(words[0])
+	}
+	if token.IsKeyword(s) || reservedParams[s] {
+		s += "_"
+	}
+	return s
+}
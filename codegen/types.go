@@ -0,0 +1,474 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/razzkumar/go-graphql/language"
+	"github.com/razzkumar/go-graphql/schema"
+)
+
+// builtinScalars are the default Go types of the built-in scalars.
+var builtinScalars = map[string]string{
+	"Int":     "int",
+	"Float":   "float64",
+	"String":  "string",
+	"Boolean": "bool",
+	"ID":      "string",
+}
+
+// selectedField is a response field with the selections of every
+// field merged into it.
+type selectedField struct {
+	key string
+	def *schema.Field
+	set language.SelectionSet
+}
+
+// goField is a field of a generated struct.
+type goField struct {
+	name, goType, key string
+	description       string
+	// iface is the interface type of a field of abstract type, and
+	// depth is the number of lists it is wrapped in.
+	iface string
+	depth int
+}
+
+// collect gathers the fields selected on the object type t by set and
+// the named fragments on t it spreads.
+func (g *generator) collect(t *schema.Type, set language.SelectionSet, fields *[]*selectedField, embeds *[]string) {
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *language.Field:
+			key := sel.ResponseKey()
+			merged := false
+			for _, f := range *fields {
+				if f.key == key {
+					f.set = append(f.set, sel.SelectionSet...)
+					merged = true
+				}
+			}
+			if !merged {
+				*fields = append(*fields, &selectedField{
+					key: key,
+					def: g.fieldDefinition(t, sel.Name),
+					set: append(language.SelectionSet(nil), sel.SelectionSet...),
+				})
+			}
+		case *language.InlineFragment:
+			if g.applies(sel.TypeCondition, t) {
+				g.collect(t, sel.SelectionSet, fields, embeds)
+			}
+		case *language.FragmentSpread:
+			frag := g.fragments[sel.Name]
+			if frag == nil || !g.applies(frag.TypeCondition, t) {
+				continue
+			}
+			if frag.TypeCondition != t.Name {
+				g.collect(t, frag.SelectionSet, fields, embeds)
+				continue
+			}
+			if !contains(*embeds, frag.Name) {
+				*embeds = append(*embeds, frag.Name)
+			}
+		}
+	}
+}
+
+// applies reports whether a selection with the type condition cond
+// applies to the object type t.
+func (g *generator) applies(cond string, t *schema.Type) bool {
+	if cond == "" || cond == t.Name {
+		return true
+	}
+	condType := g.schema.Types[cond]
+	return condType != nil && condType.IsAbstract() && g.schema.IsPossibleType(condType, t)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// reserve adds a placeholder declaration, so that a type is declared
+// before the types it refers to, and returns a function that fills it.
+func (g *generator) reserve() func(decl string) {
+	i := len(g.decls)
+	g.decls = append(g.decls, "")
+	return func(decl string) { g.decls[i] = decl }
+}
+
+// objectStruct generates the struct name for the selections set on the
+// object type t.
+func (g *generator) objectStruct(name string, t *schema.Type, set language.SelectionSet) (string, error) {
+	fill := g.reserve()
+	var selected []*selectedField
+	var embeds []string
+	g.collect(t, set, &selected, &embeds)
+
+	fields := make([]*goField, 0, len(selected))
+	for _, f := range selected {
+		field, err := g.field(name, f)
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, field)
+	}
+	embedded := make([]string, 0, len(embeds))
+	for _, frag := range embeds {
+		typ, err := g.fragmentStruct(g.fragments[frag])
+		if err != nil {
+			return "", err
+		}
+		embedded = append(embedded, typ)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "// %s holds the fields selected on %s.\ntype %s struct {\n", name, t.Name, name)
+	for _, typ := range embedded {
+		fmt.Fprintf(&sb, "\t%s\n", typ)
+	}
+	for _, f := range fields {
+		sb.WriteString(comment("\t", f.description))
+		fmt.Fprintf(&sb, "\t%s %s `json:%q`\n", f.name, f.goType, f.key)
+	}
+	sb.WriteString("}\n\n")
+	if custom := len(embedded) > 0 || hasAbstract(fields); custom {
+		sb.WriteString(g.unmarshalMethod(name, embedded, fields))
+	}
+	fill(sb.String())
+	return name, nil
+}
+
+func hasAbstract(fields []*goField) bool {
+	for _, f := range fields {
+		if f.iface != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// fragmentStruct generates the struct for a named fragment on an
+// object type, once.
+func (g *generator) fragmentStruct(frag *language.FragmentDefinition) (string, error) {
+	name := exportedName(frag.Name)
+	if g.generated[frag.Name] {
+		return name, nil
+	}
+	g.generated[frag.Name] = true
+	return g.objectStruct(name, g.schema.Types[frag.TypeCondition], frag.SelectionSet)
+}
+
+// field gets the Go field for f, generating the types it needs.
+func (g *generator) field(parent string, f *selectedField) (*goField, error) {
+	field := &goField{
+		name:        exportedName(f.key),
+		key:         f.key,
+		description: f.def.Description,
+	}
+	named := g.schema.Types[f.def.Type.Name()]
+	switch {
+	case named == nil:
+		return nil, fmt.Errorf("field %s has undefined type %s", f.key, f.def.Type.Name())
+	case named.IsLeaf():
+		base, err := g.leafType(named)
+		if err != nil {
+			return nil, err
+		}
+		field.goType = wrap(f.def.Type, base, !canBeNil(base))
+	case named.Kind == schema.Object:
+		base, err := g.objectStruct(g.uniqueName(parent+field.name), named, f.set)
+		if err != nil {
+			return nil, err
+		}
+		field.goType = wrap(f.def.Type, base, true)
+	default:
+		for t := f.def.Type; t.Elem != nil; t = t.Elem {
+			field.depth++
+		}
+		if field.depth > 1 {
+			return nil, fmt.Errorf("field %s: nested lists of %s are not supported", f.key, named.Name)
+		}
+		base, err := g.abstractType(g.uniqueName(parent+field.name), named, f.set)
+		if err != nil {
+			return nil, err
+		}
+		field.iface = base
+		field.goType = wrap(f.def.Type, base, false)
+	}
+	return field, nil
+}
+
+// abstractType generates an interface for the selections set on the
+// interface or union t, with an implementation for each possible type.
+func (g *generator) abstractType(name string, t *schema.Type, set language.SelectionSet) (string, error) {
+	fill := g.reserve()
+
+	// Leaf fields selected directly on t get a getter in the interface.
+	type getter struct{ name, goType string }
+	var getters []getter
+	for _, sel := range set {
+		f, ok := sel.(*language.Field)
+		if !ok || f.Name == "__typename" {
+			continue
+		}
+		def := g.fieldDefinition(t, f.Name)
+		if def == nil {
+			continue
+		}
+		named := g.schema.Types[def.Type.Name()]
+		if named == nil || !named.IsLeaf() {
+			continue
+		}
+		base, err := g.leafType(named)
+		if err != nil {
+			return "", err
+		}
+		getters = append(getters, getter{"Get" + exportedName(f.ResponseKey()), wrap(def.Type, base, !canBeNil(base))})
+	}
+
+	possible := append([]*schema.Type(nil), g.schema.PossibleTypes(t)...)
+	sort.Slice(possible, func(i, j int) bool { return possible[i].Name < possible[j].Name })
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "// %s is the selection on the %s %s.\n// It is implemented by ", name, t.Name, strings.ToLower(string(t.Kind)))
+	impls := make([]string, len(possible))
+	for i, p := range possible {
+		impls[i] = g.uniqueName(name + exportedName(p.Name))
+	}
+	for i, impl := range impls {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("*" + impl)
+	}
+	fmt.Fprintf(&sb, ".\ntype %s interface {\n\tis%s()\n\t// GetTypename gets the __typename of the object.\n\tGetTypename() string\n", name, name)
+	for _, gt := range getters {
+		fmt.Fprintf(&sb, "\t%s() %s\n", gt.name, gt.goType)
+	}
+	sb.WriteString("}\n\n")
+
+	for i, p := range possible {
+		if _, err := g.objectStruct(impls[i], p, set); err != nil {
+			return "", err
+		}
+		var methods strings.Builder
+		fmt.Fprintf(&methods, "func (v *%s) is%s() {}\n\n", impls[i], name)
+		fmt.Fprintf(&methods, "// GetTypename gets the __typename of v.\nfunc (v *%s) GetTypename() string { return v.Typename }\n\n", impls[i])
+		for _, gt := range getters {
+			fmt.Fprintf(&methods, "// %s gets the %s field of v.\nfunc (v *%s) %s() %s { return v.%s }\n\n",
+				gt.name, strings.TrimPrefix(gt.name, "Get"), impls[i], gt.name, gt.goType, strings.TrimPrefix(gt.name, "Get"))
+		}
+		g.decl("%s", methods.String())
+	}
+
+	g.importName("encoding/json")
+	g.importName("fmt")
+	fmt.Fprintf(&sb, "func unmarshal%s(b json.RawMessage) (%s, error) {\n", name, name)
+	sb.WriteString("\tif len(b) == 0 || string(b) == \"null\" {\n\t\treturn nil, nil\n\t}\n")
+	sb.WriteString("\tvar tn struct {\n\t\tTypename string `json:\"__typename\"`\n\t}\n")
+	sb.WriteString("\tif err := json.Unmarshal(b, &tn); err != nil {\n\t\treturn nil, err\n\t}\n")
+	fmt.Fprintf(&sb, "\tvar v %s\n\tswitch tn.Typename {\n", name)
+	for i, p := range possible {
+		fmt.Fprintf(&sb, "\tcase %q:\n\t\tv = new(%s)\n", p.Name, impls[i])
+	}
+	fmt.Fprintf(&sb, "\tdefault:\n\t\treturn nil, fmt.Errorf(\"graphql: unexpected __typename %%q for %s\", tn.Typename)\n\t}\n", t.Name)
+	sb.WriteString("\tif err := json.Unmarshal(b, v); err != nil {\n\t\treturn nil, err\n\t}\n\treturn v, nil\n}\n\n")
+	fill(sb.String())
+	return name, nil
+}
+
+// unmarshalMethod generates an UnmarshalJSON method for a struct with
+// embedded fragments or fields of abstract type, which encoding/json
+// cannot decode on its own.
+func (g *generator) unmarshalMethod(name string, embedded []string, fields []*goField) string {
+	g.importName("encoding/json")
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "// UnmarshalJSON implements json.Unmarshaler.\nfunc (v *%s) UnmarshalJSON(b []byte) error {\n", name)
+	sb.WriteString("\tif string(b) == \"null\" {\n\t\treturn nil\n\t}\n")
+	for _, typ := range embedded {
+		fmt.Fprintf(&sb, "\tif err := json.Unmarshal(b, &v.%s); err != nil {\n\t\treturn err\n\t}\n", typ)
+	}
+	if len(fields) == 0 {
+		sb.WriteString("\treturn nil\n}\n\n")
+		return sb.String()
+	}
+	sb.WriteString("\tvar raw struct {\n")
+	for _, f := range fields {
+		typ := f.goType
+		if f.iface != "" {
+			typ = strings.Repeat("[]", f.depth) + "json.RawMessage"
+		}
+		fmt.Fprintf(&sb, "\t\t%s %s `json:%q`\n", f.name, typ, f.key)
+	}
+	sb.WriteString("\t}\n\tif err := json.Unmarshal(b, &raw); err != nil {\n\t\treturn err\n\t}\n")
+	for _, f := range fields {
+		switch {
+		case f.iface == "":
+			fmt.Fprintf(&sb, "\tv.%s = raw.%s\n", f.name, f.name)
+		case f.depth == 0:
+			fmt.Fprintf(&sb, "\tif value, err := unmarshal%s(raw.%s); err != nil {\n\t\treturn err\n\t} else {\n\t\tv.%s = value\n\t}\n",
+				f.iface, f.name, f.name)
+		default:
+			fmt.Fprintf(&sb, "\tif raw.%s != nil {\n\t\tv.%s = make(%s, len(raw.%s))\n", f.name, f.name, f.goType, f.name)
+			fmt.Fprintf(&sb, "\t\tfor i, item := range raw.%s {\n\t\t\tvalue, err := unmarshal%s(item)\n\t\t\tif err != nil {\n\t\t\t\treturn err\n\t\t\t}\n\t\t\tv.%s[i] = value\n\t\t}\n\t}\n",
+				f.name, f.iface, f.name)
+		}
+	}
+	sb.WriteString("\treturn nil\n}\n\n")
+	return sb.String()
+}
+
+// leafType gets the Go type of a scalar or enum.
+func (g *generator) leafType(t *schema.Type) (string, error) {
+	if spec, ok := g.cfg.Scalars[t.Name]; ok {
+		return g.typeRef(spec)
+	}
+	if t.Kind == schema.Enum {
+		g.enums[t.Name] = true
+		return exportedName(t.Name), nil
+	}
+	if typ, ok := builtinScalars[t.Name]; ok {
+		return typ, nil
+	}
+	return "any", nil
+}
+
+// typeRef converts a configured type such as "time.Time" or
+// "github.com/google/uuid.UUID" to a Go type, importing its package.
+func (g *generator) typeRef(spec string) (string, error) {
+	typ := strings.TrimLeft(spec, "*[]")
+	prefix := spec[:len(spec)-len(typ)]
+	i := strings.LastIndex(typ, ".")
+	if i < 0 {
+		return spec, nil
+	}
+	if i == 0 || i == len(typ)-1 {
+		return "", fmt.Errorf("invalid Go type %q", spec)
+	}
+	return prefix + g.importName(typ[:i]) + "." + typ[i+1:], nil
+}
+
+// inputType gets the Go type of an input type reference, generating
+// input object structs as needed.
+func (g *generator) inputType(ref *language.Type) (string, error) {
+	named := g.schema.Types[ref.Name()]
+	var base string
+	switch {
+	case named == nil:
+		return "", fmt.Errorf("undefined type %s", ref.Name())
+	case named.IsLeaf():
+		typ, err := g.leafType(named)
+		if err != nil {
+			return "", err
+		}
+		base = typ
+	case named.Kind == schema.InputObject:
+		typ, err := g.inputStruct(named)
+		if err != nil {
+			return "", err
+		}
+		base = typ
+	default:
+		return "", fmt.Errorf("%s is not an input type", named.Name)
+	}
+	return wrap(ref, base, !canBeNil(base)), nil
+}
+
+// inputStruct generates the struct for an input object type, once.
+func (g *generator) inputStruct(t *schema.Type) (string, error) {
+	name := exportedName(t.Name)
+	if g.inputs[t.Name] {
+		return name, nil
+	}
+	g.inputs[t.Name] = true
+	fill := g.reserve()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "// %s is the %s input type.\n", name, t.Name)
+	sb.WriteString(docComment(t.Description))
+	fmt.Fprintf(&sb, "type %s struct {\n", name)
+	for _, f := range t.InputFields {
+		typ, err := g.inputType(f.Type)
+		if err != nil {
+			return "", fmt.Errorf("%s.%s: %w", t.Name, f.Name, err)
+		}
+		tag := f.Name
+		if !f.Type.NonNull {
+			tag += ",omitempty"
+		}
+		sb.WriteString(comment("\t", f.Description))
+		fmt.Fprintf(&sb, "\t%s %s `json:%q`\n", exportedName(f.Name), typ, tag)
+	}
+	sb.WriteString("}\n\n")
+	fill(sb.String())
+	return name, nil
+}
+
+// enumDecls declares the enum types that were used.
+func (g *generator) enumDecls() {
+	names := make([]string, 0, len(g.enums))
+	for name := range g.enums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t := g.schema.Types[name]
+		typ := exportedName(name)
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "// %s is the %s enum.\n", typ, name)
+		sb.WriteString(docComment(t.Description))
+		fmt.Fprintf(&sb, "type %s string\n\nconst (\n", typ)
+		for _, v := range t.EnumValues {
+			sb.WriteString(comment("\t", v.Description))
+			fmt.Fprintf(&sb, "\t%s%s %s = %q\n", typ, exportedName(v.Name), typ, v.Name)
+		}
+		sb.WriteString(")\n\n")
+		g.decl("%s", sb.String())
+	}
+}
+
+// wrap applies the list and non-null modifiers of ref to base. Nullable
+// values become pointers if pointer is set.
+func wrap(ref *language.Type, base string, pointer bool) string {
+	if ref.Elem != nil {
+		return "[]" + wrap(ref.Elem, base, pointer)
+	}
+	if !ref.NonNull && pointer {
+		return "*" + base
+	}
+	return base
+}
+
+// canBeNil reports whether a Go type has a nil value.
+func canBeNil(goType string) bool {
+	return goType == "any" || strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "[]") ||
+		strings.HasPrefix(goType, "map[") || goType == "json.RawMessage"
+}
+
+// docComment formats a description as a further paragraph of a type's
+// doc comment.
+func docComment(description string) string {
+	if description == "" {
+		return ""
+	}
+	return "//\n" + comment("", description)
+}
+
+// comment formats a description as a Go comment.
+func comment(indent, description string) string {
+	if description == "" {
+		return ""
+	}
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(description), "\n") {
+		sb.WriteString(strings.TrimRight(indent+"// "+line, " ") + "\n")
+	}
+	return sb.String()
+}
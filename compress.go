@@ -0,0 +1,156 @@
+package graphql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Compressor compresses a request body with a Content-Encoding. It
+// returns a writer that compresses to w and is flushed by Close.
+type Compressor func(w io.Writer) (io.WriteCloser, error)
+
+// WithCompressor registers compress for the Content-Encoding encoding,
+// such as "zstd" or "br", for use with WithRequestCompression. A
+// compressor for gzip is built in.
+func WithCompressor(encoding string, compress Compressor) ClientOption {
+	return func(client *Client) {
+		if client.compressors == nil {
+			client.compressors = make(map[string]Compressor)
+		}
+		client.compressors[strings.ToLower(encoding)] = compress
+	}
+}
+
+// WithRequestCompression compresses request bodies of at least minSize
+// bytes with the Content-Encoding encoding. The server must accept it.
+//
+//	NewClient(endpoint, WithRequestCompression("gzip", 1024))
+func WithRequestCompression(encoding string, minSize int) ClientOption {
+	return func(client *Client) {
+		client.requestEncoding = strings.ToLower(encoding)
+		client.compressMinSize = minSize
+	}
+}
+
+// WithAcceptEncoding asks for responses compressed with one of
+// encodings, which need a Decompressor. With no encodings, every
+// encoding with a Decompressor is accepted.
+//
+// By default the http.Client's Transport asks for gzip and decompresses
+// the response itself; with this option the Client does, so that
+// other encodings can be used and WithMaxResponseSize applies to the
+// compressed size.
+func WithAcceptEncoding(encodings ...string) ClientOption {
+	return func(client *Client) {
+		client.acceptEncoding = encodings
+		if len(encodings) == 0 {
+			client.acceptEncoding = []string{}
+		}
+	}
+}
+
+// Decompressor decompresses a response body with a Content-Encoding.
+type Decompressor func(r io.Reader) (io.Reader, error)
+
+// WithDecompressor registers decompress for response bodies with the
+// Content-Encoding encoding, such as "br". Decompressors for gzip and
+// deflate are built in.
+func WithDecompressor(encoding string, decompress Decompressor) ClientOption {
+	return func(client *Client) {
+		if client.decompressors == nil {
+			client.decompressors = make(map[string]Decompressor)
+		}
+		client.decompressors[strings.ToLower(encoding)] = decompress
+	}
+}
+
+var builtinDecompressors = map[string]Decompressor{
+	"gzip": func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	},
+	"deflate": func(r io.Reader) (io.Reader, error) {
+		return zlib.NewReader(r)
+	},
+}
+
+func (c *Client) decompressor(encoding string) Decompressor {
+	if d, ok := c.decompressors[encoding]; ok {
+		return d
+	}
+	return builtinDecompressors[encoding]
+}
+
+var builtinCompressors = map[string]Compressor{
+	"gzip": func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	},
+}
+
+func (c *Client) compressor(encoding string) Compressor {
+	if compress, ok := c.compressors[encoding]; ok {
+		return compress
+	}
+	return builtinCompressors[encoding]
+}
+
+// acceptEncodingHeader gets the value of the Accept-Encoding header to
+// send, or "" to leave it to the Transport.
+func (c *Client) acceptEncodingHeader() string {
+	if c.acceptEncoding == nil {
+		return ""
+	}
+	encodings := c.acceptEncoding
+	if len(encodings) == 0 {
+		for encoding := range builtinDecompressors {
+			encodings = append(encodings, encoding)
+		}
+		for encoding := range c.decompressors {
+			if builtinDecompressors[encoding] == nil {
+				encodings = append(encodings, encoding)
+			}
+		}
+		sort.Strings(encodings)
+	}
+	return strings.Join(encodings, ", ")
+}
+
+// compress sets the Accept-Encoding header of r and compresses its
+// body.
+func (c *Client) compress(r *http.Request) error {
+	if accept := c.acceptEncodingHeader(); accept != "" && r.Header.Get("Accept-Encoding") == "" {
+		r.Header.Set("Accept-Encoding", accept)
+	}
+	if c.requestEncoding == "" || r.ContentLength < int64(c.compressMinSize) || r.Body == nil {
+		return nil
+	}
+	compress := c.compressor(c.requestEncoding)
+	if compress == nil {
+		return fmt.Errorf("graphql: unsupported Content-Encoding %q", c.requestEncoding)
+	}
+	var buf bytes.Buffer
+	w, err := compress(&buf)
+	if err != nil {
+		return fmt.Errorf("compressing request: %w", err)
+	}
+	if _, err := io.Copy(w, r.Body); err != nil {
+		return fmt.Errorf("compressing request: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("compressing request: %w", err)
+	}
+	compressed := buf.Bytes()
+	r.Body = io.NopCloser(bytes.NewReader(compressed))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	r.ContentLength = int64(len(compressed))
+	r.Header.Set("Content-Encoding", c.requestEncoding)
+	c.logf(">> compressed: %d bytes", len(compressed))
+	return nil
+}
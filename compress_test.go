@@ -0,0 +1,144 @@
+package graphql
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestWithRequestCompression(t *testing.T) {
+	var encoding, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		var rd io.Reader = r.Body
+		if encoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			rd = zr
+		}
+		b, _ := io.ReadAll(rd)
+		body = string(b)
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithRequestCompression("gzip", 100))
+	tests := []struct {
+		query        string
+		wantEncoding string
+	}{
+		{query: "query {}", wantEncoding: ""},
+		{query: "query {" + strings.Repeat(" a", 100) + "}", wantEncoding: "gzip"},
+	}
+	for _, tt := range tests {
+		if err := client.Run(ctx, NewRequest(tt.query), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if encoding != tt.wantEncoding {
+			t.Errorf("Content-Encoding got %q, want %q", encoding, tt.wantEncoding)
+		}
+		if want := `{"query":"` + tt.query + `","variables":null}` + "\n"; body != want {
+			t.Errorf("body got %s, want %s", body, want)
+		}
+	}
+}
+
+func TestWithRequestCompressionMultipart(t *testing.T) {
+	var encoding string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, UseMultipartForm(), WithRequestCompression("gzip", 0))
+	req := NewRequest("query {}")
+	req.File("file", "file.txt", strings.NewReader("contents"))
+	if err := client.Run(ctx, req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if encoding != "gzip" {
+		t.Errorf("Content-Encoding got %q, want gzip", encoding)
+	}
+}
+
+func TestWithCompressor(t *testing.T) {
+	var encoding, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := NewClient(srv.URL, WithRequestCompression("zstd", 0)).Run(ctx, NewRequest("query {}"), nil)
+	if err == nil || err.Error() != `graphql: unsupported Content-Encoding "zstd"` {
+		t.Fatalf("err got %v, want unsupported Content-Encoding", err)
+	}
+
+	plain := func(w io.Writer) (io.WriteCloser, error) {
+		return nopWriteCloser{w}, nil
+	}
+	client := NewClient(srv.URL, WithCompressor("zstd", plain), WithRequestCompression("zstd", 0))
+	if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if encoding != "zstd" || !strings.Contains(body, "query {}") {
+		t.Errorf("got Content-Encoding %q and body %s, want zstd and the request", encoding, body)
+	}
+}
+
+func TestWithAcceptEncoding(t *testing.T) {
+	var accept string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		io.WriteString(zw, `{"data":{"name":"Ada"}}`)
+		zw.Close()
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	identity := func(r io.Reader) (io.Reader, error) { return r, nil }
+	tests := []struct {
+		opts []ClientOption
+		want string
+	}{
+		{opts: []ClientOption{WithAcceptEncoding()}, want: "deflate, gzip"},
+		{opts: []ClientOption{WithAcceptEncoding(), WithDecompressor("br", identity)}, want: "br, deflate, gzip"},
+		{opts: []ClientOption{WithAcceptEncoding("gzip")}, want: "gzip"},
+	}
+	for _, tt := range tests {
+		var resp struct{ Name string }
+		if err := NewClient(srv.URL, tt.opts...).Run(ctx, NewRequest("query {}"), &resp); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if accept != tt.want {
+			t.Errorf("Accept-Encoding got %q, want %q", accept, tt.want)
+		}
+		if resp.Name != "Ada" {
+			t.Errorf("name got %q, want Ada", resp.Name)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/razzkumar/go-graphql/language"
+	"github.com/razzkumar/go-graphql/schema"
+)
+
+// responseValue is a response object that is decoded by a decoder
+// rather than by encoding/json directly. Run sets up the decoder.
+type responseValue struct {
+	v           reflect.Value
+	structQuery bool
+	d           *decoder
+	info        typeInfo
+}
+
+func (r *responseValue) UnmarshalJSON(data []byte) error {
+	d := r.d
+	if d == nil {
+		d = &decoder{}
+	}
+	d.structQuery = r.structQuery
+	return d.decode(data, r.v, r.info, "")
+}
+
+// typeInfo is what is known about the GraphQL type of a value in the
+// response: its type and the selections made on it. ref is nil if the
+// type is not known.
+type typeInfo struct {
+	ref *language.Type
+	set language.SelectionSet
+}
+
+// decoder decodes response data into Go values. Unlike encoding/json it
+// flattens the inline fragments of struct queries, and decodes custom
+// scalars with their ScalarCodec. The scalar type of a value is taken
+// from a scalar struct tag, or from the query and schema.
+type decoder struct {
+	schema    *schema.Schema
+	fragments map[string]*language.FragmentDefinition
+	scalars   map[string]ScalarCodec
+	// structQuery makes graphql tags name fields, as ConstructQuery does.
+	structQuery bool
+	// codec decodes values that need no special handling.
+	codec Codec
+	// useNumber decodes numbers in an any as json.Number.
+	useNumber bool
+}
+
+// unmarshal decodes data into v with the codec.
+func (d *decoder) unmarshal(data []byte, v any) error {
+	codec := d.codec
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	if !d.useNumber {
+		return codec.Unmarshal(data, v)
+	}
+	return newDecoder(codec, bytes.NewReader(data), true).Decode(v)
+}
+
+func isNull(data []byte) bool {
+	return bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+}
+
+func isArray(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '['
+}
+
+func (d *decoder) decode(data []byte, v reflect.Value, info typeInfo, scalar string) error {
+	if scalar == "" && info.ref != nil && info.ref.Elem == nil {
+		if _, ok := d.scalars[info.ref.NamedType]; ok {
+			scalar = info.ref.NamedType
+		}
+	}
+	if scalar == "" && info.ref == nil && !d.structQuery && !hasScalarTags(v.Type()) {
+		return d.unmarshal(data, v.Addr().Interface())
+	}
+	if isNull(data) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	kind := v.Kind()
+	if kind == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(data, v.Elem(), info, scalar)
+	}
+	if kind == reflect.Interface && v.NumMethod() == 0 {
+		value, err := d.decodeAny(data, info, scalar)
+		if err != nil {
+			return err
+		}
+		if value != nil {
+			v.Set(reflect.ValueOf(value))
+		}
+		return nil
+	}
+	if (kind == reflect.Slice || kind == reflect.Array) && isArray(data) {
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		if kind == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+		}
+		elem := typeInfo{set: info.set}
+		if info.ref != nil && info.ref.Elem != nil {
+			elem.ref = info.ref.Elem
+		}
+		for i := 0; i < len(items) && i < v.Len(); i++ {
+			if err := d.decode(items[i], v.Index(i), elem, scalar); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if codec := d.scalars[scalar]; codec != nil {
+		return codec.DecodeScalar(data, v.Addr().Interface())
+	}
+	switch {
+	case kind == reflect.Struct && !isLeaf(v.Type()):
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		return d.decodeStruct(obj, v, info, typename(obj))
+	case kind == reflect.Map && v.Type().Key().Kind() == reflect.String && info.ref != nil:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(obj)))
+		}
+		tn := typename(obj)
+		for key, raw := range obj {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decode(raw, elem, d.fieldInfo(info, key, tn), ""); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+		return nil
+	}
+	return d.unmarshal(data, v.Addr().Interface())
+}
+
+// decodeAny decodes data into the generic values encoding/json uses
+// for an any, decoding custom scalars with their codec.
+func (d *decoder) decodeAny(data []byte, info typeInfo, scalar string) (any, error) {
+	if isNull(data) {
+		return nil, nil
+	}
+	if isArray(data) {
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		elem := typeInfo{set: info.set}
+		if info.ref != nil && info.ref.Elem != nil {
+			elem.ref = info.ref.Elem
+		}
+		list := make([]any, len(items))
+		for i, item := range items {
+			value, err := d.decodeAny(item, elem, scalar)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = value
+		}
+		return list, nil
+	}
+	if scalar == "" && info.ref != nil {
+		if _, ok := d.scalars[info.ref.NamedType]; ok {
+			scalar = info.ref.NamedType
+		}
+	}
+	if codec := d.scalars[scalar]; codec != nil {
+		var value any
+		err := codec.DecodeScalar(data, &value)
+		return value, err
+	}
+	if info.ref != nil && bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return nil, err
+		}
+		tn := typename(obj)
+		out := make(map[string]any, len(obj))
+		for key, raw := range obj {
+			value, err := d.decodeAny(raw, d.fieldInfo(info, key, tn), "")
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			out[key] = value
+		}
+		return out, nil
+	}
+	var value any
+	err := d.unmarshal(data, &value)
+	return value, err
+}
+
+func (d *decoder) decodeStruct(obj map[string]json.RawMessage, v reflect.Value, info typeInfo, tn string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+		key, fragment, ok := d.fieldKey(f, obj)
+		if !ok {
+			continue
+		}
+		if fragment {
+			if fv.Kind() == reflect.Pointer {
+				if !fragmentApplies(tn, f.Tag.Get("graphql")) {
+					continue
+				}
+				if fv.IsNil() {
+					fv.Set(reflect.New(f.Type.Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if err := d.decodeStruct(obj, fv, info, tn); err != nil {
+				return err
+			}
+			continue
+		}
+		data, ok := obj[key]
+		if !ok {
+			continue
+		}
+		if err := d.decode(data, fv, d.fieldInfo(info, key, tn), f.Tag.Get("scalar")); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// fieldKey gets the response key a struct field is decoded from, or
+// reports that the field is a fragment decoded from the same object.
+func (d *decoder) fieldKey(f reflect.StructField, obj map[string]json.RawMessage) (key string, fragment, ok bool) {
+	if !f.IsExported() && !(f.Anonymous && f.Type.Kind() == reflect.Struct) {
+		return "", false, false
+	}
+	embedded := f.Anonymous && selectionType(f.Type) != nil
+	if d.structQuery {
+		tag, tagged := f.Tag.Lookup("graphql")
+		switch {
+		case tag == "-":
+			return "", false, false
+		case embedded && !tagged, strings.HasPrefix(tag, "...") && selectionType(f.Type) != nil:
+			return "", true, true
+		case tagged:
+			return responseKey(tag), false, true
+		}
+		return fieldName(f.Name), false, true
+	}
+	tag, tagged := f.Tag.Lookup("json")
+	name, _, _ := strings.Cut(tag, ",")
+	switch {
+	case name == "-" && tag == "-":
+		return "", false, false
+	case embedded && name == "":
+		return "", true, true
+	case name != "":
+		return name, false, true
+	case !tagged && !f.IsExported():
+		return "", false, false
+	}
+	if _, ok := obj[f.Name]; ok {
+		return f.Name, false, true
+	}
+	for k := range obj {
+		if strings.EqualFold(k, f.Name) {
+			return k, false, true
+		}
+	}
+	return f.Name, false, true
+}
+
+// fieldInfo gets the type of the field with the response key key in a
+// value described by info, whose __typename is tn if it was selected.
+func (d *decoder) fieldInfo(info typeInfo, key, tn string) typeInfo {
+	if info.ref == nil || d.schema == nil {
+		return typeInfo{}
+	}
+	parent := d.schema.Types[info.ref.Name()]
+	if parent == nil {
+		return typeInfo{}
+	}
+	var out typeInfo
+	d.findField(parent, info.set, key, tn, &out, make(map[string]bool))
+	return out
+}
+
+func (d *decoder) findField(parent *schema.Type, set language.SelectionSet, key, tn string, out *typeInfo, visited map[string]bool) {
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *language.Field:
+			if sel.ResponseKey() != key {
+				continue
+			}
+			def := parent.Field(sel.Name)
+			if sel.Name == schema.TypenameMetaField.Name {
+				def = schema.TypenameMetaField
+			}
+			if def == nil {
+				continue
+			}
+			if out.ref == nil {
+				out.ref = def.Type
+			}
+			out.set = append(out.set, sel.SelectionSet...)
+		case *language.InlineFragment:
+			t := parent
+			if sel.TypeCondition != "" {
+				t = d.schema.Types[sel.TypeCondition]
+			}
+			if d.typeApplies(t, tn) {
+				d.findField(t, sel.SelectionSet, key, tn, out, visited)
+			}
+		case *language.FragmentSpread:
+			frag := d.fragments[sel.Name]
+			if frag == nil || visited[sel.Name] {
+				continue
+			}
+			visited[sel.Name] = true
+			if t := d.schema.Types[frag.TypeCondition]; d.typeApplies(t, tn) {
+				d.findField(t, frag.SelectionSet, key, tn, out, visited)
+			}
+		}
+	}
+}
+
+// typeApplies reports whether selections on t apply to an object with
+// the __typename tn, which is empty if unknown.
+func (d *decoder) typeApplies(t *schema.Type, tn string) bool {
+	if t == nil {
+		return false
+	}
+	if tn == "" || t.Name == tn {
+		return true
+	}
+	obj := d.schema.Types[tn]
+	return obj != nil && t.IsAbstract() && d.schema.IsPossibleType(t, obj)
+}
+
+func typename(obj map[string]json.RawMessage) string {
+	var tn string
+	if raw, ok := obj["__typename"]; ok {
+		json.Unmarshal(raw, &tn)
+	}
+	return tn
+}
+
+// fragmentApplies reports whether an inline fragment with the given
+// graphql tag applies to an object with the __typename tn.
+func fragmentApplies(tn, tag string) bool {
+	cond, ok := strings.CutPrefix(strings.TrimSpace(strings.TrimPrefix(tag, "...")), "on ")
+	if !ok || tn == "" {
+		return true
+	}
+	cond, _, _ = strings.Cut(strings.TrimSpace(cond), " ")
+	return tn == cond
+}
+
+var scalarTagCache sync.Map // map[reflect.Type]bool
+
+// hasScalarTags reports whether t contains struct fields with a scalar
+// tag, which encoding/json would ignore.
+func hasScalarTags(t reflect.Type) bool {
+	if cached, ok := scalarTagCache.Load(t); ok {
+		return cached.(bool)
+	}
+	found := scanScalarTags(t, make(map[reflect.Type]bool))
+	scalarTagCache.Store(t, found)
+	return found
+}
+
+// scanScalarTags looks for scalar tags in t, skipping the types in
+// visited. Only the result for the outermost type is complete, since a
+// cycle back to a type being scanned is skipped.
+func scanScalarTags(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if cached, ok := scalarTagCache.Load(t); ok {
+		return cached.(bool)
+	}
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return scanScalarTags(t.Elem(), visited)
+	case reflect.Struct:
+		if isLeaf(t) {
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if _, ok := f.Tag.Lookup("scalar"); ok || scanScalarTags(f.Type, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,287 @@
+package graphql
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Endpoint is one of the URLs of a GraphQL API, see WithEndpoints.
+type Endpoint struct {
+	URL string
+	// Primary marks an endpoint that takes writes. If any endpoint is
+	// primary, mutations are only sent to primary endpoints.
+	Primary bool
+	// Priority orders endpoints for PriorityFailover, lowest first.
+	Priority int
+}
+
+// LoadBalancing is how requests are spread over endpoints.
+type LoadBalancing int
+
+const (
+	// RoundRobin sends requests to the endpoints in turn.
+	RoundRobin LoadBalancing = iota
+	// LeastLatency sends requests to the endpoint that has been fastest
+	// to respond recently.
+	LeastLatency
+	// PriorityFailover sends requests to the endpoint with the lowest
+	// Priority, and to the next only while it is unhealthy.
+	PriorityFailover
+)
+
+// Defaults of WithEndpointHealth.
+const (
+	defaultMaxFailures    = 3
+	defaultHealthCooldown = 30 * time.Second
+)
+
+// latencyWeight is the weight of the latest latency in the moving
+// average of an endpoint.
+const latencyWeight = 0.3
+
+// WithEndpoints spreads requests over endpoints, which replace the
+// endpoint given to NewClient, according to lb. WithEndpointResolver
+// overrides it.
+//
+// Endpoints are tracked passively: an endpoint that fails several times
+// in a row, with a connection error or a 5xx status, is left out for a
+// while and then tried again, see WithEndpointHealth. A query that fails
+// this way is sent to another endpoint. A mutation is only sent to
+// another endpoint if it could not connect, so that it is not applied
+// twice.
+//
+//	NewClient("", WithEndpoints(LeastLatency,
+//	    Endpoint{URL: "https://eu.example.com/graphql", Primary: true},
+//	    Endpoint{URL: "https://us.example.com/graphql"},
+//	))
+func WithEndpoints(lb LoadBalancing, endpoints ...Endpoint) ClientOption {
+	return func(client *Client) {
+		client.loadBalancing = lb
+		client.endpointList = endpoints
+	}
+}
+
+// WithEndpointHealth marks an endpoint unhealthy after maxFailures
+// failures in a row, and tries it again after cooldown. The defaults are
+// 3 failures and 30 seconds.
+func WithEndpointHealth(maxFailures int, cooldown time.Duration) ClientOption {
+	return func(client *Client) {
+		client.maxFailures = maxFailures
+		client.healthCooldown = cooldown
+	}
+}
+
+// EndpointStatus is the health of an endpoint.
+type EndpointStatus struct {
+	URL     string
+	Healthy bool
+	// Failures is the number of failures in a row.
+	Failures int
+	// Latency is the moving average of the time to a response.
+	Latency time.Duration
+}
+
+// Endpoints returns the status of the endpoints of WithEndpoints.
+func (c *Client) Endpoints() []EndpointStatus {
+	if c.pool == nil {
+		return nil
+	}
+	now := time.Now()
+	statuses := make([]EndpointStatus, len(c.pool.endpoints))
+	for i, e := range c.pool.endpoints {
+		e.mu.Lock()
+		statuses[i] = EndpointStatus{
+			URL:      e.URL,
+			Healthy:  !e.down(now),
+			Failures: e.failures,
+			Latency:  e.latency,
+		}
+		e.mu.Unlock()
+	}
+	return statuses
+}
+
+// endpointPool picks the endpoints of requests.
+type endpointPool struct {
+	lb          LoadBalancing
+	endpoints   []*endpointState
+	hasPrimary  bool
+	maxFailures int
+	cooldown    time.Duration
+	// next counts the picks of queries and of mutations for RoundRobin.
+	next [2]atomic.Uint64
+}
+
+// endpointState is the health of an endpoint.
+type endpointState struct {
+	Endpoint
+
+	mu        sync.Mutex
+	failures  int
+	downUntil time.Time
+	latency   time.Duration
+}
+
+func newEndpointPool(lb LoadBalancing, endpoints []Endpoint, maxFailures int, cooldown time.Duration) *endpointPool {
+	if maxFailures <= 0 {
+		maxFailures = defaultMaxFailures
+	}
+	if cooldown <= 0 {
+		cooldown = defaultHealthCooldown
+	}
+	p := &endpointPool{lb: lb, maxFailures: maxFailures, cooldown: cooldown}
+	for _, e := range endpoints {
+		p.endpoints = append(p.endpoints, &endpointState{Endpoint: e})
+		p.hasPrimary = p.hasPrimary || e.Primary
+	}
+	return p
+}
+
+// down reports whether the endpoint is left out at now. e.mu is held.
+func (e *endpointState) down(now time.Time) bool {
+	return now.Before(e.downUntil)
+}
+
+// candidates returns the endpoints a request may be sent to, leaving out
+// those in exclude.
+func (p *endpointPool) candidates(mutation bool, exclude []*endpointState) []*endpointState {
+	var candidates []*endpointState
+	for _, e := range p.endpoints {
+		if mutation && p.hasPrimary && !e.Primary || slices.Contains(exclude, e) {
+			continue
+		}
+		candidates = append(candidates, e)
+	}
+	return candidates
+}
+
+// pick picks the endpoint of a request, or nil if none is left. Unhealthy
+// endpoints are only picked if all are unhealthy, the one that recovers
+// first.
+func (p *endpointPool) pick(mutation bool, exclude []*endpointState) *endpointState {
+	candidates := p.candidates(mutation, exclude)
+	if len(candidates) == 0 {
+		return nil
+	}
+	now := time.Now()
+	healthy := candidates[:0:0]
+	for _, e := range candidates {
+		e.mu.Lock()
+		if !e.down(now) {
+			healthy = append(healthy, e)
+		}
+		e.mu.Unlock()
+	}
+	if len(healthy) == 0 {
+		return slices.MinFunc(candidates, func(a, b *endpointState) int {
+			return a.until().Compare(b.until())
+		})
+	}
+	switch p.lb {
+	case LeastLatency:
+		// Endpoints without a latency yet are tried first.
+		return slices.MinFunc(healthy, func(a, b *endpointState) int {
+			return cmp.Compare(a.averageLatency(), b.averageLatency())
+		})
+	case PriorityFailover:
+		return slices.MinFunc(healthy, func(a, b *endpointState) int {
+			return cmp.Compare(a.Priority, b.Priority)
+		})
+	}
+	next := &p.next[0]
+	if mutation {
+		next = &p.next[1]
+	}
+	return healthy[(next.Add(1)-1)%uint64(len(healthy))]
+}
+
+func (e *endpointState) until() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.downUntil
+}
+
+func (e *endpointState) averageLatency() time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.latency
+}
+
+// observe records the outcome of a round trip to e that took latency.
+func (p *endpointPool) observe(e *endpointState, latency time.Duration, failed bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if failed {
+		e.failures++
+		if e.failures >= p.maxFailures {
+			e.downUntil = time.Now().Add(p.cooldown)
+		}
+		return
+	}
+	e.failures = 0
+	e.downUntil = time.Time{}
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(e.latency))
+	}
+}
+
+// endpointFailed reports whether a round trip failed because of the
+// endpoint.
+func endpointFailed(res *http.Response, err error) bool {
+	return err != nil || res.StatusCode >= http.StatusInternalServerError
+}
+
+// pickEndpoint sets the endpoint of the next attempt of a request.
+func (c *Client) pickEndpoint(ctx context.Context, req *Request, call *call) error {
+	switch {
+	case c.resolver != nil:
+		endpoint, err := c.resolveEndpoint(ctx, req)
+		if err != nil {
+			return err
+		}
+		call.endpoint = endpoint
+	case c.pool != nil:
+		call.endpointState = c.pool.pick(call.operationType == "mutation", call.failed)
+		call.endpoint = call.endpointState.URL
+	default:
+		call.endpoint = c.endpoint
+	}
+	return nil
+}
+
+// dynamicEndpoint reports whether the endpoint of a request is only
+// known once it is sent.
+func (c *Client) dynamicEndpoint() bool {
+	return c.resolver != nil || c.pool != nil
+}
+
+// failover reports whether a request that failed should be sent to
+// another endpoint. A request whose ctx is done is not.
+func (c *Client) failover(ctx context.Context, req *Request, call *call) bool {
+	if c.pool == nil || call.endpointState == nil || len(req.files) > 0 || ctx.Err() != nil {
+		return false
+	}
+	if call.roundTripErr == nil && call.status < http.StatusInternalServerError {
+		return false
+	}
+	var opErr *net.OpError
+	mutation := call.operationType == "mutation"
+	if mutation && !(errors.As(call.roundTripErr, &opErr) && opErr.Op == "dial") {
+		return false
+	}
+	call.failed = append(call.failed, call.endpointState)
+	if len(c.pool.candidates(mutation, call.failed)) == 0 {
+		return false
+	}
+	call.retries++
+	return true
+}
@@ -0,0 +1,256 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingServer counts its requests and answers them after delay with
+// status.
+type countingServer struct {
+	*httptest.Server
+	requests atomic.Int32
+}
+
+func newCountingServer(t *testing.T, status int, delay time.Duration) *countingServer {
+	s := &countingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		time.Sleep(delay)
+		w.WriteHeader(status)
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// closedURL returns the URL of a server that refuses connections.
+func closedURL() string {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	return srv.URL
+}
+
+func TestEndpointsRoundRobin(t *testing.T) {
+	servers := []*countingServer{
+		newCountingServer(t, http.StatusOK, 0),
+		newCountingServer(t, http.StatusOK, 0),
+		newCountingServer(t, http.StatusOK, 0),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var endpoints []Endpoint
+	for _, s := range servers {
+		endpoints = append(endpoints, Endpoint{URL: s.URL})
+	}
+	client := NewClient("", WithEndpoints(RoundRobin, endpoints...))
+	for range 6 {
+		if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	for i, s := range servers {
+		if n := s.requests.Load(); n != 2 {
+			t.Errorf("server %d got %d requests, want 2", i, n)
+		}
+	}
+}
+
+func TestEndpointsFailover(t *testing.T) {
+	failing := newCountingServer(t, http.StatusServiceUnavailable, 0)
+	ok := newCountingServer(t, http.StatusOK, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	metrics := &retryMetrics{}
+	client := NewClient("",
+		WithEndpoints(PriorityFailover, Endpoint{URL: failing.URL}, Endpoint{URL: ok.URL, Priority: 1}),
+		WithEndpointHealth(2, time.Hour),
+		WithMetrics(metrics),
+	)
+	for i := range 3 {
+		if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		wantRetries := 1
+		if i == 2 {
+			// The failing endpoint is left out by now.
+			wantRetries = 0
+		}
+		if metrics.retries != wantRetries {
+			t.Errorf("request %d: retries got %d, want %d", i, metrics.retries, wantRetries)
+		}
+	}
+	if n := failing.requests.Load(); n != 2 {
+		t.Errorf("failing server got %d requests, want 2", n)
+	}
+	status := client.Endpoints()
+	if status[0].Healthy || status[0].Failures != 2 || !status[1].Healthy {
+		t.Errorf("got %+v, want the first endpoint unhealthy", status)
+	}
+}
+
+func TestEndpointsRecovery(t *testing.T) {
+	ok := newCountingServer(t, http.StatusOK, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	const cooldown = 20 * time.Millisecond
+	client := NewClient("",
+		WithEndpoints(PriorityFailover, Endpoint{URL: closedURL()}, Endpoint{URL: ok.URL, Priority: 1}),
+		WithEndpointHealth(1, cooldown),
+	)
+	if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.Endpoints()[0].Healthy {
+		t.Errorf("refusing endpoint got healthy, want unhealthy")
+	}
+	time.Sleep(cooldown)
+	if !client.Endpoints()[0].Healthy {
+		t.Errorf("refusing endpoint got unhealthy after the cooldown, want it to be tried again")
+	}
+	if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.Endpoints()[0].Healthy {
+		t.Errorf("refusing endpoint got healthy after failing again, want unhealthy")
+	}
+	if n := ok.requests.Load(); n != 2 {
+		t.Errorf("server got %d requests, want 2", n)
+	}
+}
+
+func TestEndpointsLeastLatency(t *testing.T) {
+	slow := newCountingServer(t, http.StatusOK, 20*time.Millisecond)
+	fast := newCountingServer(t, http.StatusOK, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient("", WithEndpoints(LeastLatency, Endpoint{URL: slow.URL}, Endpoint{URL: fast.URL}))
+	for range 5 {
+		if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// Each endpoint is measured once, then the fast one is used.
+	if n := slow.requests.Load(); n != 1 {
+		t.Errorf("slow server got %d requests, want 1", n)
+	}
+	if n := fast.requests.Load(); n != 4 {
+		t.Errorf("fast server got %d requests, want 4", n)
+	}
+}
+
+func TestEndpointsReadWriteSplit(t *testing.T) {
+	primary := newCountingServer(t, http.StatusOK, 0)
+	replica := newCountingServer(t, http.StatusOK, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient("", WithEndpoints(RoundRobin, Endpoint{URL: primary.URL, Primary: true}, Endpoint{URL: replica.URL}))
+	for range 4 {
+		if err := client.Run(ctx, NewRequest("mutation { save }"), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := client.Run(ctx, NewRequest("query { items }"), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := primary.requests.Load(); n != 6 {
+		t.Errorf("primary got %d requests, want 4 mutations and 2 queries", n)
+	}
+	if n := replica.requests.Load(); n != 2 {
+		t.Errorf("replica got %d requests, want 2 queries", n)
+	}
+}
+
+func TestEndpointsMutationFailover(t *testing.T) {
+	failing := newCountingServer(t, http.StatusServiceUnavailable, 0)
+	ok := newCountingServer(t, http.StatusOK, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// A mutation that reached a server is not sent again.
+	client := NewClient("", WithEndpoints(PriorityFailover, Endpoint{URL: failing.URL}, Endpoint{URL: ok.URL, Priority: 1}))
+	client.Run(ctx, NewRequest("mutation { save }"), nil)
+	if n := ok.requests.Load(); n != 0 {
+		t.Errorf("second server got %d requests, want 0", n)
+	}
+
+	// A mutation that could not connect is.
+	client = NewClient("", WithEndpoints(PriorityFailover, Endpoint{URL: closedURL()}, Endpoint{URL: ok.URL, Priority: 1}))
+	if err := client.Run(ctx, NewRequest("mutation { save }"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := ok.requests.Load(); n != 1 {
+		t.Errorf("second server got %d requests, want 1", n)
+	}
+}
+
+func TestEndpointsCanceled(t *testing.T) {
+	slow := []*countingServer{
+		newCountingServer(t, http.StatusOK, 200*time.Millisecond),
+		newCountingServer(t, http.StatusOK, 200*time.Millisecond),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	client := NewClient("", WithEndpoints(RoundRobin, Endpoint{URL: slow[0].URL}, Endpoint{URL: slow[1].URL}))
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Run(ctx, NewRequest("query {}"), nil)
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Run did not return after the context was done")
+	}
+	if n := slow[0].requests.Load() + slow[1].requests.Load(); n != 1 {
+		t.Errorf("servers got %d requests, want 1", n)
+	}
+}
+
+func TestEndpointsIterate(t *testing.T) {
+	failing := newCountingServer(t, http.StatusServiceUnavailable, 0)
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"items":[1,2,3]}}`)
+	}))
+	defer ok.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	metrics := &retryMetrics{}
+	endpoints := []Endpoint{{URL: closedURL()}, {URL: failing.URL, Priority: 1}, {URL: ok.URL, Priority: 2}}
+	client := NewClient("", WithEndpoints(PriorityFailover, endpoints...), WithMetrics(metrics))
+	it, err := client.Iterate(ctx, NewRequest("query { items }"), "items")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if err := it.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("got %d items, want 3", n)
+	}
+	if failing.requests.Load() != 1 || metrics.retries != 2 {
+		t.Errorf("got %d requests to the failing server and %d retries, want 1 and 2", failing.requests.Load(), metrics.retries)
+	}
+}
@@ -29,6 +29,17 @@
 //
 //	httpclient := &http.Client{}
 //	client := graphql.NewClient("https://example.com/graphql", graphql.WithHTTPClient(httpclient))
+//
+// # Validation
+//
+// To catch invalid queries before they reach the server, load a schema
+// and use the WithSchemaValidation option:
+//
+//	s, err := schema.LoadFile("schema.graphql")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	client := graphql.NewClient("https://example.com/graphql", graphql.WithSchemaValidation(s))
 package graphql
 
 import (
@@ -38,8 +49,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/razzkumar/go-graphql/schema"
 )
 
 // Client is a client for interacting with a GraphQL API.
@@ -48,9 +64,53 @@ type Client struct {
 	httpClient       *http.Client
 	useMultipartForm bool
 
+	schema            *schema.Schema
+	validateVariables bool
+	scalars           map[string]ScalarCodec
+
+	codec     Codec
+	useNumber bool
+	decoder   func(data []byte, v any) error
+
+	maxResponseSize     int64
+	maxDecompressedSize int64
+	decompressors       map[string]Decompressor
+
+	compressors     map[string]Compressor
+	requestEncoding string
+	compressMinSize int
+	acceptEncoding  []string
+
+	logger            *slog.Logger
+	redactedHeaders   []string
+	redactedVariables []string
+	logBodyLimit      int
+
+	tracer     Tracer
+	propagator Propagator
+	metrics    Metrics
+
+	tokens  *tokenCache
+	signers []Signer
+	appSync *AppSync
+
+	header           http.Header
+	headerFuncs      []func(ctx context.Context) http.Header
+	headerPrecedence HeaderPrecedence
+	metadataFuncs    []func(ctx context.Context) Metadata
+
+	endpointList   []Endpoint
+	loadBalancing  LoadBalancing
+	maxFailures    int
+	healthCooldown time.Duration
+	pool           *endpointPool
+	resolver       EndpointResolver
+
 	// Log is called with various debug information.
 	// To log to standard out, use:
 	//  client.Log = func(s string) { log.Println(s) }
+	// Responses are only read into memory as a whole once Log is replaced.
+	// Nothing is redacted; WithLogger logs structured records instead.
 	Log func(s string)
 }
 
@@ -58,7 +118,7 @@ type Client struct {
 func NewClient(endpoint string, opts ...ClientOption) *Client {
 	c := &Client{
 		endpoint: endpoint,
-		Log:      func(string) {},
+		Log:      noLog,
 	}
 	for _, optionFunc := range opts {
 		optionFunc(c)
@@ -66,10 +126,27 @@ func NewClient(endpoint string, opts ...ClientOption) *Client {
 	if c.httpClient == nil {
 		c.httpClient = http.DefaultClient
 	}
+	if c.codec == nil {
+		c.codec = JSONCodec{}
+	}
+	if len(c.endpointList) > 0 && c.resolver == nil {
+		c.pool = newEndpointPool(c.loadBalancing, c.endpointList, c.maxFailures, c.healthCooldown)
+	}
 	return c
 }
 
+// noLog is the default Log, which discards messages.
+func noLog(string) {}
+
+// logging reports whether Log has been set to a function that logs.
+func (c *Client) logging() bool {
+	return c.Log != nil && reflect.ValueOf(c.Log).Pointer() != reflect.ValueOf(noLog).Pointer()
+}
+
 func (c *Client) logf(format string, args ...any) {
+	if !c.logging() {
+		return
+	}
 	c.Log(fmt.Sprintf(format, args...))
 }
 
@@ -79,136 +156,234 @@ func (c *Client) logf(format string, args ...any) {
 // If the request fails or the server returns an error, the first error
 // will be returned.
 func (c *Client) Run(ctx context.Context, req *Request, resp any) error {
+	ctx, call := c.startCall(ctx, req)
+	var err error
+	for {
+		var res *http.Response
+		var decodeResp any
+		res, decodeResp, err = c.send(ctx, req, resp, call)
+		if err == nil {
+			err = c.readResponse(res, decodeResp, call)
+			res.Body.Close()
+		}
+		if !c.retryAuth(req, call) && !c.failover(ctx, req, call) {
+			break
+		}
+	}
+	c.endCall(ctx, call, err)
+	if r, ok := resp.(*Response); ok {
+		r.Timing = call.timing
+	}
+	return err
+}
+
+// send validates and prepares req and sends it, as a new attempt of
+// call. It returns the HTTP response and the response object to decode
+// the body into.
+func (c *Client) send(ctx context.Context, req *Request, resp any, call *call) (*http.Response, any, error) {
+	call.status = 0
+	call.roundTripErr = nil
+	call.endpointState = nil
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		return nil, nil, ctx.Err()
 	default:
 	}
 	if len(req.files) > 0 && !c.useMultipartForm {
-		return errors.New("cannot send files with PostFields option")
+		return nil, nil, errors.New("cannot send files with PostFields option")
+	}
+	req, err := c.validate(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, resp, err = c.prepare(req, resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := c.do(ctx, req, call)
+	if err != nil {
+		return nil, nil, err
 	}
+	call.status = res.StatusCode
+	res.Body = &countingBody{ReadCloser: res.Body, n: &call.responseSize, wait: &call.readTime}
+	return res, resp, nil
+}
+
+// do sends req and returns the HTTP response.
+func (c *Client) do(ctx context.Context, req *Request, call *call) (*http.Response, error) {
+	if err := c.pickEndpoint(ctx, req, call); err != nil {
+		return nil, err
+	}
+	md := c.metadata(ctx)
+	var r *http.Request
+	var err error
 	if c.useMultipartForm {
-		return c.runWithPostFields(ctx, req, resp)
+		r, err = c.requestWithPostFields(call.endpoint, req, md.Extensions)
+	} else {
+		r, err = c.requestWithJSON(call.endpoint, req, md.Extensions)
 	}
-	return c.runWithJSON(ctx, req, resp)
+	if err != nil {
+		return nil, err
+	}
+	c.setHeaders(ctx, r, req, md.Header)
+	c.logf(">> headers: %v", r.Header)
+	if err := c.compress(r); err != nil {
+		return nil, err
+	}
+	if err := c.authorize(ctx, r, call); err != nil {
+		return nil, err
+	}
+	c.inject(ctx, r)
+	if err := c.sign(ctx, r); err != nil {
+		return nil, err
+	}
+	call.requestSize += r.ContentLength
+	c.logRequest(ctx, call, req, r)
+	start := time.Now()
+	res, err := c.httpClient.Do(r.WithContext(call.timer.trace(ctx)))
+	call.roundTripErr = err
+	if call.endpointState != nil && ctx.Err() == nil {
+		c.pool.observe(call.endpointState, time.Since(start), endpointFailed(res, err))
+	}
+	return res, err
 }
 
-func (c *Client) runWithJSON(ctx context.Context, req *Request, resp any) error {
-	var requestBody bytes.Buffer
+func (c *Client) requestWithJSON(endpoint string, req *Request, extensions map[string]any) (*http.Request, error) {
 	requestBodyObj := struct {
-		Query     string         `json:"query"`
-		Variables map[string]any `json:"variables"`
+		Query      string         `json:"query"`
+		Variables  map[string]any `json:"variables"`
+		Extensions map[string]any `json:"extensions,omitempty"`
 	}{
-		Query:     req.q,
-		Variables: req.vars,
+		Query:      req.q,
+		Variables:  req.vars,
+		Extensions: extensions,
 	}
-	if err := json.NewEncoder(&requestBody).Encode(requestBodyObj); err != nil {
-		return fmt.Errorf("encode body: %w", err)
+	requestBody, err := c.codec.Marshal(requestBodyObj)
+	if err != nil {
+		return nil, fmt.Errorf("encode body: %w", err)
 	}
+	requestBody = append(requestBody, '\n')
 	c.logf(">> variables: %v", req.vars)
 	c.logf(">> query: %s", req.q)
-	gr := &graphResponse{
-		Data: resp,
-	}
-	r, err := http.NewRequest(http.MethodPost, c.endpoint, &requestBody)
+	r, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(requestBody))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	r.Header.Set("Content-Type", "application/json; charset=utf-8")
 	r.Header.Set("Accept", "application/json; charset=utf-8")
-	for key, values := range req.Header {
-		for _, value := range values {
-			r.Header.Add(key, value)
-		}
-	}
-	c.logf(">> headers: %v", r.Header)
-	r = r.WithContext(ctx)
-	res, err := c.httpClient.Do(r)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, res.Body); err != nil {
-		return fmt.Errorf("reading body: %w", err)
-	}
-	c.logf("<< %s", buf.String())
-	if err := json.NewDecoder(&buf).Decode(&gr); err != nil {
-		if res.StatusCode != http.StatusOK {
-			return fmt.Errorf("graphql: server returned a non-200 status code: %v", res.StatusCode)
-		}
-		return fmt.Errorf("decoding response: %w", err)
-	}
-	if len(gr.Errors) > 0 {
-		// return first error
-		return gr.Errors[0]
-	}
-	return nil
+	return r, nil
 }
 
-func (c *Client) runWithPostFields(ctx context.Context, req *Request, resp any) error {
+func (c *Client) requestWithPostFields(endpoint string, req *Request, extensions map[string]any) (*http.Request, error) {
 	var requestBody bytes.Buffer
 	writer := multipart.NewWriter(&requestBody)
 	if err := writer.WriteField("query", req.q); err != nil {
-		return fmt.Errorf("write query field: %w", err)
+		return nil, fmt.Errorf("write query field: %w", err)
 	}
 	var variablesBuf bytes.Buffer
 	if len(req.vars) > 0 {
 		variablesField, err := writer.CreateFormField("variables")
 		if err != nil {
-			return fmt.Errorf("create variables field: %w", err)
+			return nil, fmt.Errorf("create variables field: %w", err)
 		}
-		if err := json.NewEncoder(io.MultiWriter(variablesField, &variablesBuf)).Encode(req.vars); err != nil {
-			return fmt.Errorf("encode variables: %w", err)
+		variables, err := c.codec.Marshal(req.vars)
+		if err != nil {
+			return nil, fmt.Errorf("encode variables: %w", err)
+		}
+		variables = append(variables, '\n')
+		if _, err := io.MultiWriter(variablesField, &variablesBuf).Write(variables); err != nil {
+			return nil, fmt.Errorf("write variables field: %w", err)
+		}
+	}
+	if len(extensions) > 0 {
+		extensionsField, err := writer.CreateFormField("extensions")
+		if err != nil {
+			return nil, fmt.Errorf("create extensions field: %w", err)
+		}
+		data, err := c.codec.Marshal(extensions)
+		if err != nil {
+			return nil, fmt.Errorf("encode extensions: %w", err)
+		}
+		if _, err := extensionsField.Write(data); err != nil {
+			return nil, fmt.Errorf("write extensions field: %w", err)
 		}
 	}
 	for i := range req.files {
 		part, err := writer.CreateFormFile(req.files[i].Field, req.files[i].Name)
 		if err != nil {
-			return fmt.Errorf("create form file: %w", err)
+			return nil, fmt.Errorf("create form file: %w", err)
 		}
 		if _, err := io.Copy(part, req.files[i].R); err != nil {
-			return fmt.Errorf("preparing file: %w", err)
+			return nil, fmt.Errorf("preparing file: %w", err)
 		}
 	}
 	if err := writer.Close(); err != nil {
-		return fmt.Errorf("close writer: %w", err)
+		return nil, fmt.Errorf("close writer: %w", err)
 	}
 	c.logf(">> variables: %s", variablesBuf.String())
 	c.logf(">> files: %d", len(req.files))
 	c.logf(">> query: %s", req.q)
-	gr := &graphResponse{
-		Data: resp,
-	}
-	r, err := http.NewRequest(http.MethodPost, c.endpoint, &requestBody)
+	r, err := http.NewRequest(http.MethodPost, endpoint, &requestBody)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	r.Header.Set("Content-Type", writer.FormDataContentType())
 	r.Header.Set("Accept", "application/json; charset=utf-8")
-	for key, values := range req.Header {
-		for _, value := range values {
-			r.Header.Add(key, value)
-		}
-	}
-	c.logf(">> headers: %v", r.Header)
-	r = r.WithContext(ctx)
-	res, err := c.httpClient.Do(r)
+	return r, nil
+}
+
+// readResponse decodes the body of res into resp. The body is decoded
+// as it is read unless it is logged.
+func (c *Client) readResponse(res *http.Response, resp any, call *call) error {
+	start, wait := time.Now(), call.readTime
+	defer func() {
+		call.timer.bodyRead(time.Since(start) - (call.readTime - wait))
+	}()
+	body, err := c.responseBody(res)
 	if err != nil {
 		return err
 	}
-	defer res.Body.Close()
+	body = call.capture(body)
+	if !c.logging() {
+		err := c.decodeResponse(body, res.StatusCode, resp, call)
+		// Drain the rest so that the connection can be reused.
+		io.Copy(io.Discard, body)
+		return err
+	}
 	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, res.Body); err != nil {
+	if _, err := io.Copy(&buf, body); err != nil {
 		return fmt.Errorf("reading body: %w", err)
 	}
 	c.logf("<< %s", buf.String())
-	if err := json.NewDecoder(&buf).Decode(&gr); err != nil {
-		if res.StatusCode != http.StatusOK {
-			return fmt.Errorf("graphql: server returned a non-200 status code: %v", res.StatusCode)
+	return c.decodeResponse(&buf, res.StatusCode, resp, call)
+}
+
+// decodeResponse decodes a response body into resp and returns the
+// first GraphQL error in it.
+func (c *Client) decodeResponse(body io.Reader, statusCode int, resp any, call *call) error {
+	gr := &graphResponse{
+		Data: resp,
+	}
+	var raw json.RawMessage
+	if _, deferred := resp.(*Response); c.decoder != nil && resp != nil && !deferred {
+		gr.Data = &raw
+	}
+	if err := newDecoder(c.codec, body, c.useNumber).Decode(&gr); err != nil {
+		var tooLarge *ResponseTooLargeError
+		if errors.As(err, &tooLarge) {
+			return fmt.Errorf("reading body: %w", err)
+		}
+		if statusCode != http.StatusOK {
+			return fmt.Errorf("graphql: server returned a non-200 status code: %v", statusCode)
 		}
 		return fmt.Errorf("decoding response: %w", err)
 	}
+	if len(raw) > 0 && !isNull(raw) {
+		if err := c.decoder(raw, resp); err != nil {
+			return fmt.Errorf("decoding response: %w", err)
+		}
+	}
+	call.setErrors(gr.Errors)
 	if len(gr.Errors) > 0 {
 		// return first error
 		return gr.Errors[0]
@@ -240,6 +415,32 @@ type ClientOption func(*Client)
 
 type graphErr struct {
 	Message string
+	// code is extensions.code, such as "UNAUTHENTICATED".
+	code string
+}
+
+// UnmarshalJSON decodes the message and code of an error and ignores
+// its other fields, such as locations, even when decoding with
+// DisallowUnknownFields.
+func (e *graphErr) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Message    string
+		Extensions struct {
+			Code any `json:"code"`
+		} `json:"extensions"`
+		// ErrorType is the code of AppSync errors.
+		ErrorType string `json:"errorType"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	e.Message = v.Message
+	if code, ok := v.Extensions.Code.(string); ok {
+		e.code = code
+	} else {
+		e.code = v.ErrorType
+	}
+	return nil
 }
 
 func (e graphErr) Error() string {
@@ -247,8 +448,9 @@ func (e graphErr) Error() string {
 }
 
 type graphResponse struct {
-	Data   any
-	Errors []graphErr
+	Data       any
+	Errors     []graphErr
+	Extensions json.RawMessage `json:"extensions,omitempty"`
 }
 
 // Request is a GraphQL request.
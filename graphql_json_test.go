@@ -207,3 +207,29 @@ func TestHeader(t *testing.T) {
 		t.Errorf("resp.Value got %v, want %v", got, want)
 	}
 }
+
+func TestLogWrapped(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"something":"yes"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	if client.Log == nil {
+		t.Fatalf("Log got nil, want a function that discards messages")
+	}
+	var logs []string
+	prev := client.Log
+	client.Log = func(s string) {
+		prev(s)
+		logs = append(logs, s)
+	}
+	if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(logs); n == 0 || logs[n-1] != `<< {"data":{"something":"yes"}}` {
+		t.Errorf("logs got %q, want the response last", logs)
+	}
+}
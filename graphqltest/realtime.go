@@ -0,0 +1,127 @@
+package graphqltest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/razzkumar/go-graphql/internal/websocket"
+)
+
+// message is a message of the AppSync realtime protocol.
+type message struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// realtime serves subscriptions over the AppSync realtime protocol.
+func (s *Server) realtime(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Upgrade(w, r, []string{"graphql-ws"})
+	if err != nil {
+		s.t.Errorf("graphqltest: upgrading connection: %v", err)
+		return
+	}
+	s.mu.Lock()
+	s.conns[conn] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	header := r.Header.Clone()
+	if value, err := base64.StdEncoding.DecodeString(r.URL.Query().Get("header")); err == nil {
+		var auth map[string]string
+		if json.Unmarshal(value, &auth) == nil {
+			for key, value := range auth {
+				header.Set(key, value)
+			}
+		}
+	}
+	send := func(msg message) bool {
+		data, err := json.Marshal(msg)
+		return err == nil && conn.WriteMessage(websocket.TextMessage, data) == nil
+	}
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			s.t.Errorf("graphqltest: decoding message: %v", err)
+			return
+		}
+		switch msg.Type {
+		case "connection_init":
+			if !send(message{Type: "connection_ack", Payload: json.RawMessage(`{"connectionTimeoutMs":300000}`)}) {
+				return
+			}
+		case "start":
+			if !s.subscribe(r, msg, header, send) {
+				return
+			}
+		}
+	}
+}
+
+// subscribe answers a start message, and reports whether the connection
+// is still usable.
+func (s *Server) subscribe(r *http.Request, msg message, header http.Header, send func(message) bool) bool {
+	var start struct {
+		Data string `json:"data"`
+	}
+	var body struct {
+		Query      string         `json:"query"`
+		Variables  map[string]any `json:"variables"`
+		Extensions map[string]any `json:"extensions"`
+	}
+	err := json.Unmarshal(msg.Payload, &start)
+	if err == nil {
+		err = json.Unmarshal([]byte(start.Data), &body)
+	}
+	if err != nil {
+		s.t.Errorf("graphqltest: decoding subscription: %v", err)
+		return false
+	}
+	req := Request{
+		OperationName: operationName(body.Query),
+		Query:         body.Query,
+		Variables:     body.Variables,
+		Extensions:    body.Extensions,
+		Header:        header,
+		Subscription:  true,
+	}
+	st := s.serve(req)
+	if st == nil {
+		return send(message{ID: msg.ID, Type: "error", Payload: errorsPayload([]Error{{Message: "graphqltest: no stub matches the subscription"}})})
+	}
+	if !send(message{ID: msg.ID, Type: "start_ack"}) {
+		return false
+	}
+	for _, event := range st.events {
+		if !sleep(r.Context(), st.delay) {
+			return false
+		}
+		payload, err := json.Marshal(map[string]any{"data": event})
+		if err != nil {
+			s.t.Errorf("graphqltest: encoding event: %v", err)
+			return false
+		}
+		if !send(message{ID: msg.ID, Type: "data", Payload: payload}) {
+			return false
+		}
+	}
+	if len(st.errors) > 0 {
+		return send(message{ID: msg.ID, Type: "error", Payload: errorsPayload(st.errors)})
+	}
+	return send(message{ID: msg.ID, Type: "complete"})
+}
+
+func errorsPayload(errs []Error) json.RawMessage {
+	payload, _ := json.Marshal(map[string][]Error{"errors": errs})
+	return payload
+}
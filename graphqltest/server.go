@@ -0,0 +1,375 @@
+// Package graphqltest provides a fake GraphQL server for tests:
+//
+//	srv := graphqltest.NewServer(t)
+//	srv.Stub(graphqltest.Match{OperationName: "GetUser", Variables: map[string]any{"id": "1"}}).
+//	    Data(map[string]any{"user": map[string]any{"name": "Ada"}})
+//	client := graphql.NewClient(srv.URL)
+//	// ...
+//	srv.AssertCalled("GetUser", 1)
+//
+// Requests are answered by the first stub that matches them, and are
+// recorded with Requests. A request that no stub matches fails the
+// test. Both JSON and multipart requests are understood, and
+// subscriptions use the AWS AppSync realtime protocol, so a client needs
+// graphql.WithAppSync to subscribe.
+package graphqltest
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/razzkumar/go-graphql/language"
+)
+
+// maxMemory is how much of a multipart request is kept in memory.
+const maxMemory = 32 << 20
+
+// Server is a fake GraphQL server. It is closed when the test ends.
+type Server struct {
+	*httptest.Server
+	t testing.TB
+
+	mu       sync.Mutex
+	stubs    []*Stub
+	requests []Request
+	conns    map[io.Closer]struct{}
+}
+
+// NewServer starts a server that reports unexpected requests to t.
+func NewServer(t testing.TB) *Server {
+	s := &Server{t: t, conns: make(map[io.Closer]struct{})}
+	s.Server = httptest.NewServer(s)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Close closes the open subscriptions and shuts the server down.
+func (s *Server) Close() {
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.Server.Close()
+}
+
+// Match selects the requests a stub answers. Empty fields match any
+// request.
+type Match struct {
+	OperationName string
+	// Query is compared with the query of requests once both are
+	// formatted the same way, so that whitespace and commas do not
+	// matter.
+	Query string
+	// Variables must equal the variables of requests once both are
+	// encoded as JSON, so that 1 matches 1.0. An empty map only matches
+	// requests without variables.
+	Variables map[string]any
+}
+
+// Error is a GraphQL error in a response.
+type Error struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Stub is the canned response to the requests that match it. Its
+// methods return the stub so that they can be chained, and must be
+// called before the requests it answers are sent.
+type Stub struct {
+	match     Match
+	query     string
+	variables any
+	data      any
+	errors    []Error
+	status    int
+	delay     time.Duration
+	events    []any
+	calls     int
+}
+
+// Stub adds a stub for the requests that match m. Stubs are tried in the
+// order they are added.
+func (s *Server) Stub(m Match) *Stub {
+	st := &Stub{match: m, status: http.StatusOK}
+	if m.Query != "" {
+		st.query = normalize(m.Query)
+	}
+	if m.Variables != nil {
+		st.variables = roundTrip(m.Variables)
+	}
+	s.mu.Lock()
+	s.stubs = append(s.stubs, st)
+	s.mu.Unlock()
+	return st
+}
+
+// Data sets the data of the response.
+func (st *Stub) Data(data any) *Stub {
+	st.data = data
+	return st
+}
+
+// Errors sets the errors of the response. Subscriptions fail with them
+// after their events.
+func (st *Stub) Errors(errs ...Error) *Stub {
+	st.errors = errs
+	return st
+}
+
+// Status sets the HTTP status of the response, 200 by default.
+func (st *Stub) Status(code int) *Stub {
+	st.status = code
+	return st
+}
+
+// Delay waits d before responding, and before each subscription event.
+func (st *Stub) Delay(d time.Duration) *Stub {
+	st.delay = d
+	return st
+}
+
+// Events sets the data of the events sent to subscriptions, which are
+// completed afterwards.
+func (st *Stub) Events(events ...any) *Stub {
+	st.events = events
+	return st
+}
+
+// File is a file uploaded with a multipart request.
+type File struct {
+	Field   string
+	Name    string
+	Content []byte
+}
+
+// Request is a request received by a Server.
+type Request struct {
+	OperationName string
+	Query         string
+	Variables     map[string]any
+	Extensions    map[string]any
+	Header        http.Header
+	Files         []File
+	// Subscription is set for subscriptions.
+	Subscription bool
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// AssertCalled fails the test unless the server received times
+// requests for the operation.
+func (s *Server) AssertCalled(operationName string, times int) {
+	s.t.Helper()
+	n := 0
+	for _, r := range s.Requests() {
+		if r.OperationName == operationName {
+			n++
+		}
+	}
+	if n != times {
+		s.t.Errorf("graphqltest: %s got %d requests, want %d", operationName, n, times)
+	}
+}
+
+// AssertStubsCalled fails the test if a stub did not answer any request.
+func (s *Server) AssertStubsCalled() {
+	s.t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range s.stubs {
+		if st.calls == 0 {
+			s.t.Errorf("graphqltest: no request matched %+v", st.match)
+		}
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		s.realtime(w, r)
+		return
+	}
+	req, err := readRequest(r)
+	if err != nil {
+		s.t.Errorf("graphqltest: reading request: %v", err)
+		writeResponse(w, http.StatusBadRequest, nil, []Error{{Message: err.Error()}})
+		return
+	}
+	st := s.serve(req)
+	if st == nil {
+		writeResponse(w, http.StatusOK, nil, []Error{{Message: "graphqltest: no stub matches the request"}})
+		return
+	}
+	if !sleep(r.Context(), st.delay) {
+		return
+	}
+	writeResponse(w, st.status, st.data, st.errors)
+}
+
+// serve records req and returns the stub that answers it, or nil.
+func (s *Server) serve(req Request) *Stub {
+	query := normalize(req.Query)
+	variables := roundTrip(req.Variables)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	for _, st := range s.stubs {
+		if st.matches(req.OperationName, query, variables) {
+			st.calls++
+			return st
+		}
+	}
+	s.t.Errorf("graphqltest: no stub matches %s %q with variables %v", req.OperationName, req.Query, req.Variables)
+	return nil
+}
+
+func (st *Stub) matches(operationName, query string, variables any) bool {
+	if st.match.OperationName != "" && st.match.OperationName != operationName {
+		return false
+	}
+	if st.query != "" && st.query != query {
+		return false
+	}
+	return st.variables == nil || reflect.DeepEqual(st.variables, variables)
+}
+
+// readRequest reads a JSON or multipart request.
+func readRequest(r *http.Request) (Request, error) {
+	req := Request{Header: r.Header.Clone()}
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return req, err
+		}
+		r.Body = zr
+		r.Header.Del("Content-Encoding")
+	}
+	var body struct {
+		OperationName string         `json:"operationName"`
+		Query         string         `json:"query"`
+		Variables     map[string]any `json:"variables"`
+		Extensions    map[string]any `json:"extensions"`
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxMemory); err != nil {
+			return req, err
+		}
+		body.OperationName = r.FormValue("operationName")
+		body.Query = r.FormValue("query")
+		for field, v := range map[string]any{"variables": &body.Variables, "extensions": &body.Extensions} {
+			if value := r.FormValue(field); value != "" {
+				if err := json.Unmarshal([]byte(value), v); err != nil {
+					return req, fmt.Errorf("decoding %s: %w", field, err)
+				}
+			}
+		}
+		fields := slices.Sorted(maps.Keys(r.MultipartForm.File))
+		for _, field := range fields {
+			for _, h := range r.MultipartForm.File[field] {
+				f, err := h.Open()
+				if err != nil {
+					return req, err
+				}
+				content, err := io.ReadAll(f)
+				f.Close()
+				if err != nil {
+					return req, err
+				}
+				req.Files = append(req.Files, File{Field: field, Name: h.Filename, Content: content})
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return req, fmt.Errorf("decoding body: %w", err)
+	}
+	req.Query = body.Query
+	req.Variables = body.Variables
+	req.Extensions = body.Extensions
+	req.OperationName = body.OperationName
+	if req.OperationName == "" {
+		req.OperationName = operationName(body.Query)
+	}
+	return req, nil
+}
+
+// operationName returns the name of the only operation of query.
+func operationName(query string) string {
+	doc, err := language.ParseQuery(query)
+	if err != nil || len(doc.Operations) != 1 {
+		return ""
+	}
+	return doc.Operations[0].Name
+}
+
+// normalize formats query in a canonical layout, or collapses its
+// whitespace if it does not parse.
+func normalize(query string) string {
+	doc, err := language.ParseQuery(query)
+	if err != nil {
+		return strings.Join(strings.Fields(query), " ")
+	}
+	return language.Print(doc)
+}
+
+// roundTrip returns v as decoded from JSON, so that values compare
+// equal whatever their Go types.
+func roundTrip(v map[string]any) any {
+	if v == nil {
+		return map[string]any{}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	json.Unmarshal(data, &out)
+	return out
+}
+
+func writeResponse(w http.ResponseWriter, status int, data any, errs []Error) {
+	body, err := json.Marshal(struct {
+		Data   any     `json:"data"`
+		Errors []Error `json:"errors,omitempty"`
+	}{data, errs})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// sleep waits d, and reports whether ctx was not done meanwhile.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
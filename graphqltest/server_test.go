@@ -0,0 +1,192 @@
+package graphqltest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	graphql "github.com/razzkumar/go-graphql"
+)
+
+// recorder records the errors of a test instead of failing it.
+type recorder struct {
+	testing.TB
+
+	mu     sync.Mutex
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Helper() {}
+
+func TestServerMatch(t *testing.T) {
+	srv := NewServer(t)
+	srv.Stub(Match{OperationName: "GetUser", Variables: map[string]any{"id": 1}}).
+		Data(map[string]any{"user": map[string]any{"name": "Ada"}})
+	srv.Stub(Match{OperationName: "GetUser"}).
+		Data(map[string]any{"user": map[string]any{"name": "Other"}})
+	srv.Stub(Match{Query: "{ viewer { id } }"}).
+		Data(map[string]any{"viewer": map[string]any{"id": "v"}})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := graphql.NewClient(srv.URL)
+	for _, tt := range []struct {
+		id   int
+		want string
+	}{{1, "Ada"}, {2, "Other"}} {
+		req := graphql.NewRequest("query GetUser($id: ID!) { user(id: $id) { name } }")
+		req.Var("id", tt.id)
+		req.Header.Set("X-Test", "yes")
+		var resp struct{ User struct{ Name string } }
+		if err := client.Run(ctx, req, &resp); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.User.Name != tt.want {
+			t.Errorf("id %d: name got %q, want %q", tt.id, resp.User.Name, tt.want)
+		}
+	}
+
+	var resp struct{ Viewer struct{ ID string } }
+	if err := client.Run(ctx, graphql.NewRequest("query {\n  viewer {\n    id,\n  }\n}"), &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Viewer.ID != "v" {
+		t.Errorf("viewer got %q, want v", resp.Viewer.ID)
+	}
+
+	srv.AssertCalled("GetUser", 2)
+	srv.AssertStubsCalled()
+	requests := srv.Requests()
+	if len(requests) != 3 {
+		t.Fatalf("got %d requests, want 3", len(requests))
+	}
+	if requests[0].Header.Get("X-Test") != "yes" || requests[0].Variables["id"] != 1.0 {
+		t.Errorf("got %+v, want the header and variables of the request", requests[0])
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	srv := NewServer(t)
+	srv.Stub(Match{OperationName: "Save"}).
+		Status(http.StatusBadRequest).
+		Errors(Error{Message: "invalid name", Extensions: map[string]any{"code": "BAD_USER_INPUT"}})
+	srv.Stub(Match{OperationName: "Slow"}).Delay(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := graphql.NewClient(srv.URL)
+	err := client.Run(ctx, graphql.NewRequest("mutation Save { save }"), nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid name") {
+		t.Errorf("got %v, want the stub error", err)
+	}
+
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	err = client.Run(short, graphql.NewRequest("query Slow { slow }"), nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestServerUnmatched(t *testing.T) {
+	rec := &recorder{TB: t}
+	srv := NewServer(rec)
+	srv.Stub(Match{OperationName: "GetUser"})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := graphql.NewClient(srv.URL)
+	if err := client.Run(ctx, graphql.NewRequest("query Other { other }"), nil); err == nil {
+		t.Errorf("got no error, want one for the unmatched request")
+	}
+	srv.AssertCalled("Other", 2)
+	srv.AssertStubsCalled()
+	if len(rec.errors) != 3 {
+		t.Errorf("got errors %q, want the unmatched request, the call count and the unused stub", rec.errors)
+	}
+}
+
+func TestServerMultipart(t *testing.T) {
+	srv := NewServer(t)
+	srv.Stub(Match{OperationName: "Upload", Variables: map[string]any{"name": "a.txt"}}).
+		Data(map[string]any{"upload": true})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := graphql.NewClient(srv.URL, graphql.UseMultipartForm())
+	req := graphql.NewRequest("mutation Upload($name: String!) { upload(name: $name) }")
+	req.Var("name", "a.txt")
+	req.File("file", "a.txt", strings.NewReader("hello"))
+	if err := client.Run(ctx, req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	files := srv.Requests()[0].Files
+	if len(files) != 1 || files[0].Field != "file" || files[0].Name != "a.txt" || string(files[0].Content) != "hello" {
+		t.Errorf("files got %+v, want a.txt", files)
+	}
+}
+
+func TestServerSubscription(t *testing.T) {
+	srv := NewServer(t)
+	srv.Stub(Match{OperationName: "OnItem"}).
+		Events(
+			map[string]any{"onItem": map[string]any{"id": "1"}},
+			map[string]any{"onItem": map[string]any{"id": "2"}},
+		)
+	srv.Stub(Match{OperationName: "OnError"}).
+		Events(map[string]any{"onError": map[string]any{"id": "1"}}).
+		Errors(Error{Message: "gone"})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := graphql.NewClient(srv.URL, graphql.WithAppSync(graphql.AppSync{Auth: graphql.AppSyncAPIKey("key")}))
+	ctx = graphql.ContextWithMetadata(ctx, graphql.Metadata{Extensions: map[string]any{"tenant": "acme"}})
+	sub, err := client.Subscribe(ctx, graphql.NewRequest("subscription OnItem { onItem { id } }"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer sub.Close()
+	var ids []string
+	for sub.Next() {
+		var event struct{ OnItem struct{ ID string } }
+		if err := sub.Decode(&event); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, event.OnItem.ID)
+	}
+	if err := sub.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(ids, ",") != "1,2" {
+		t.Errorf("events got %v, want 1,2", ids)
+	}
+
+	sub, err = client.Subscribe(ctx, graphql.NewRequest("subscription OnError { onError { id } }"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer sub.Close()
+	n := 0
+	for sub.Next() {
+		n++
+	}
+	if err := sub.Err(); n != 1 || err == nil || !strings.Contains(err.Error(), "gone") {
+		t.Errorf("got %d events and %v, want 1 and the stub error", n, err)
+	}
+
+	requests := srv.Requests()
+	if !requests[0].Subscription || requests[0].Header.Get("X-Api-Key") != "key" || requests[0].Extensions["tenant"] != "acme" {
+		t.Errorf("got %+v, want a subscription with the API key and extensions", requests[0])
+	}
+}
@@ -0,0 +1,94 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"sync"
+)
+
+// modulePath is the path of this module, for the User-Agent version.
+const modulePath = "github.com/razzkumar/go-graphql"
+
+// userAgent is the default User-Agent header, such as
+// "go-graphql/v1.2.0".
+var userAgent = sync.OnceValue(func() string {
+	version := "devel"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Path == modulePath && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			version = info.Main.Version
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				version = dep.Version
+			}
+		}
+	}
+	return "go-graphql/" + version
+})
+
+// HeaderPrecedence decides whether request or client headers win when
+// both set a header.
+type HeaderPrecedence int
+
+const (
+	// RequestHeadersWin lets Request.Header override the headers of the
+	// client. It is the default.
+	RequestHeadersWin HeaderPrecedence = iota
+	// ClientHeadersWin lets the headers of the client override
+	// Request.Header.
+	ClientHeadersWin
+)
+
+// WithHeader adds the header key with value to every request. The
+// User-Agent header is "go-graphql/" and the version of this module by
+// default, which WithHeader can replace.
+func WithHeader(key, value string) ClientOption {
+	return func(client *Client) {
+		if client.header == nil {
+			client.header = make(http.Header)
+		}
+		client.header.Add(key, value)
+	}
+}
+
+// WithHeaderFunc adds the headers returned by f to every request. f is
+// called with the context of the request, and its headers override
+// those of WithHeader and earlier header funcs.
+func WithHeaderFunc(f func(ctx context.Context) http.Header) ClientOption {
+	return func(client *Client) {
+		client.headerFuncs = append(client.headerFuncs, f)
+	}
+}
+
+// WithHeaderPrecedence sets whether request or client headers win when
+// both set a header. The winner's values replace the other's.
+func WithHeaderPrecedence(p HeaderPrecedence) ClientOption {
+	return func(client *Client) {
+		client.headerPrecedence = p
+	}
+}
+
+// setHeaders sets the client and request headers of r, and the headers
+// of the metadata of the request.
+func (c *Client) setHeaders(ctx context.Context, r *http.Request, req *Request, metadata http.Header) {
+	r.Header.Set("User-Agent", userAgent())
+	if c.headerPrecedence == ClientHeadersWin {
+		replaceHeaders(r.Header, req.Header)
+	}
+	replaceHeaders(r.Header, c.header)
+	for _, f := range c.headerFuncs {
+		replaceHeaders(r.Header, f(ctx))
+	}
+	replaceHeaders(r.Header, metadata)
+	if c.headerPrecedence == RequestHeadersWin {
+		replaceHeaders(r.Header, req.Header)
+	}
+}
+
+// replaceHeaders sets the headers of src in dst, replacing their values.
+func replaceHeaders(dst, src http.Header) {
+	for key, values := range src {
+		dst[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+}
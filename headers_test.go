@@ -0,0 +1,115 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type tenantKey struct{}
+
+func TestHeaders(t *testing.T) {
+	tenantHeader := func(ctx context.Context) http.Header {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return http.Header{"X-Tenant": {tenant}}
+	}
+	tests := []struct {
+		name    string
+		opts    []ClientOption
+		request http.Header
+		want    map[string]string
+	}{
+		{
+			name: "default",
+			want: map[string]string{"User-Agent": userAgent(), "Content-Type": "application/json; charset=utf-8"},
+		},
+		{
+			name: "client",
+			opts: []ClientOption{WithHeader("User-Agent", "app/1.0"), WithHeader("X-Env", "prod"), WithHeader("x-env", "eu")},
+			want: map[string]string{"User-Agent": "app/1.0", "X-Env": "prod,eu"},
+		},
+		{
+			name:    "request wins",
+			opts:    []ClientOption{WithHeader("X-Env", "prod"), WithHeader("X-Team", "core")},
+			request: http.Header{"X-Env": {"staging"}},
+			want:    map[string]string{"X-Env": "staging", "X-Team": "core"},
+		},
+		{
+			name:    "client wins",
+			opts:    []ClientOption{WithHeader("X-Env", "prod"), WithHeaderPrecedence(ClientHeadersWin)},
+			request: http.Header{"X-Env": {"staging"}, "X-Request": {"1"}},
+			want:    map[string]string{"X-Env": "prod", "X-Request": "1"},
+		},
+		{
+			name: "func",
+			opts: []ClientOption{WithHeader("X-Tenant", "none"), WithHeaderFunc(tenantHeader)},
+			want: map[string]string{"X-Tenant": "acme"},
+		},
+		{
+			name:    "request overrides func",
+			opts:    []ClientOption{WithHeaderFunc(tenantHeader)},
+			request: http.Header{"X-Tenant": {"other"}},
+			want:    map[string]string{"X-Tenant": "other"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got http.Header
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header
+				io.WriteString(w, `{"data":{}}`)
+			}))
+			defer srv.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			client := NewClient(srv.URL, tt.opts...)
+			req := NewRequest("query {}")
+			for key, values := range tt.request {
+				req.Header[key] = values
+			}
+			if err := client.Run(context.WithValue(ctx, tenantKey{}, "acme"), req, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for key, want := range tt.want {
+				if value := strings.Join(got.Values(key), ","); value != want {
+					t.Errorf("%s got %q, want %q", key, value, want)
+				}
+			}
+		})
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	if ua := userAgent(); !strings.HasPrefix(ua, "go-graphql/") || len(ua) == len("go-graphql/") {
+		t.Errorf("got %q, want go-graphql/ and a version", ua)
+	}
+}
+
+func TestHeaderAuthorization(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL,
+		WithHeader("Authorization", "Basic abc"),
+		WithTokenSource(TokenSourceFunc(func(context.Context) (*Token, error) {
+			return &Token{AccessToken: "token"}, nil
+		})),
+	)
+	if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth != "Basic abc" {
+		t.Errorf("Authorization got %q, want %q", auth, "Basic abc")
+	}
+}
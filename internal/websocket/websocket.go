@@ -0,0 +1,484 @@
+// Package websocket is a minimal WebSocket (RFC 6455) client and server,
+// enough for GraphQL subscription protocols. It does not support
+// extensions such as compression.
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MessageType is the type of a data message.
+type MessageType int
+
+// The types of data messages, which are their opcodes.
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+)
+
+const (
+	opContinuation = 0x0
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// Close codes.
+const (
+	CloseNormal        = 1000
+	CloseProtocolError = 1002
+	CloseNoStatus      = 1005
+	CloseTooBig        = 1009
+)
+
+// DefaultReadLimit is the limit of the size of messages read, unless
+// SetReadLimit sets another.
+const DefaultReadLimit = 32 << 20
+
+// acceptGUID is hashed with the key of the handshake.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrProtocol is returned when the peer breaks the protocol.
+var ErrProtocol = errors.New("websocket: protocol error")
+
+// CloseError is returned by ReadMessage when the peer closed the
+// connection.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket: closed with code %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: closed with code %d: %s", e.Code, e.Reason)
+}
+
+// Conn is a WebSocket connection. Messages may be written concurrently
+// with reading them, but only one goroutine may read.
+type Conn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	client      bool
+	subprotocol string
+	readLimit   int64
+
+	wmu       sync.Mutex
+	closeOnce sync.Once
+	closeSent bool
+}
+
+// Dialer dials WebSocket servers.
+type Dialer struct {
+	// NetDial dials the TCP connection, with a net.Dialer if nil.
+	NetDial func(ctx context.Context, network, addr string) (net.Conn, error)
+	// TLSConfig is used for wss URLs.
+	TLSConfig *tls.Config
+	// Subprotocols are offered to the server in order of preference.
+	Subprotocols []string
+}
+
+// Dial opens a connection to the ws or wss URL rawURL with the extra
+// handshake headers header. The handshake is abandoned when ctx is done,
+// but the connection outlives ctx.
+func (d *Dialer) Dial(ctx context.Context, rawURL string, header http.Header) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	addr := u.Host
+	var secure bool
+	switch u.Scheme {
+	case "ws":
+		if u.Port() == "" {
+			addr = net.JoinHostPort(u.Hostname(), "80")
+		}
+	case "wss":
+		secure = true
+		if u.Port() == "" {
+			addr = net.JoinHostPort(u.Hostname(), "443")
+		}
+	default:
+		return nil, fmt.Errorf("websocket: unsupported scheme %q", u.Scheme)
+	}
+	netDial := d.NetDial
+	if netDial == nil {
+		netDial = (&net.Dialer{}).DialContext
+	}
+	conn, err := netDial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, func() {
+		// Interrupt the handshake.
+		conn.SetDeadline(time.Unix(1, 0))
+	})
+	c, err := d.handshake(ctx, conn, u, secure, header)
+	if !stop() || err != nil {
+		conn.Close()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return c, nil
+}
+
+func (d *Dialer) handshake(ctx context.Context, conn net.Conn, u *url.URL, secure bool, header http.Header) (*Conn, error) {
+	if secure {
+		cfg := &tls.Config{}
+		if d.TLSConfig != nil {
+			cfg = d.TLSConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = u.Hostname()
+		}
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Host:       u.Host,
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if len(d.Subprotocols) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(d.Subprotocols, ", "))
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		res.Body.Close()
+		return nil, fmt.Errorf("websocket: handshake failed with status %s", res.Status)
+	}
+	if !strings.EqualFold(res.Header.Get("Upgrade"), "websocket") ||
+		!hasToken(res.Header, "Connection", "upgrade") ||
+		res.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		return nil, errors.New("websocket: invalid handshake response")
+	}
+	protocol := res.Header.Get("Sec-WebSocket-Protocol")
+	if protocol != "" && !contains(d.Subprotocols, protocol) {
+		return nil, fmt.Errorf("websocket: server chose unoffered subprotocol %q", protocol)
+	}
+	return &Conn{conn: conn, br: br, client: true, subprotocol: protocol}, nil
+}
+
+// Upgrade upgrades the HTTP server connection of r to a WebSocket
+// connection, choosing the first subprotocol the client offers that is
+// in subprotocols. On failure it replies with an HTTP error.
+func Upgrade(w http.ResponseWriter, r *http.Request, subprotocols []string) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet ||
+		!strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		!hasToken(r.Header, "Connection", "upgrade") ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" || key == "" {
+		http.Error(w, "websocket: not a websocket handshake", http.StatusBadRequest)
+		return nil, errors.New("websocket: not a websocket handshake")
+	}
+	var protocol string
+	for _, offered := range strings.Split(r.Header.Get("Sec-WebSocket-Protocol"), ",") {
+		if offered = strings.TrimSpace(offered); contains(subprotocols, offered) {
+			protocol = offered
+			break
+		}
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket: cannot hijack connection", http.StatusInternalServerError)
+		return nil, errors.New("websocket: cannot hijack connection")
+	}
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	response := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " + acceptKey(key) + "\r\n"
+	if protocol != "" {
+		response += "Sec-WebSocket-Protocol: " + protocol + "\r\n"
+	}
+	if _, err := io.WriteString(conn, response+"\r\n"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Conn{conn: conn, br: brw.Reader, subprotocol: protocol}, nil
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// hasToken reports whether the comma-separated header name has token.
+func hasToken(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Subprotocol returns the subprotocol agreed in the handshake.
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// SetReadLimit limits the size of messages read to n bytes. Zero means
+// DefaultReadLimit.
+func (c *Conn) SetReadLimit(n int64) {
+	c.readLimit = n
+}
+
+func (c *Conn) limit() int64 {
+	if c.readLimit > 0 {
+		return c.readLimit
+	}
+	return DefaultReadLimit
+}
+
+// SetReadDeadline sets the deadline of reads.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// ReadMessage reads the next data message. It answers pings and close
+// messages; once the peer closes the connection it returns a
+// *CloseError.
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	var typ MessageType
+	var msg []byte
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			closeErr := &CloseError{Code: CloseNoStatus}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			c.writeClose(payload)
+			c.conn.Close()
+			return 0, nil, closeErr
+		case opContinuation:
+			if typ == 0 {
+				return 0, nil, c.fail(CloseProtocolError, ErrProtocol)
+			}
+			msg = append(msg, payload...)
+		case byte(TextMessage), byte(BinaryMessage):
+			if typ != 0 {
+				return 0, nil, c.fail(CloseProtocolError, ErrProtocol)
+			}
+			typ, msg = MessageType(op), payload
+		default:
+			return 0, nil, c.fail(CloseProtocolError, ErrProtocol)
+		}
+		if int64(len(msg)) > c.limit() {
+			return 0, nil, c.fail(CloseTooBig, fmt.Errorf("websocket: message exceeds %d bytes", c.limit()))
+		}
+		if fin {
+			return typ, msg, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var h [2]byte
+	if _, err := io.ReadFull(c.br, h[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, op = h[0]&0x80 != 0, h[0]&0x0f
+	masked := h[1]&0x80 != 0
+	if h[0]&0x70 != 0 || masked == c.client {
+		// Extensions are not negotiated, and only clients mask.
+		return false, 0, nil, c.fail(CloseProtocolError, ErrProtocol)
+	}
+	n := uint64(h[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+		if n>>63 != 0 {
+			// The most significant bit must be 0.
+			return false, 0, nil, c.fail(CloseProtocolError, ErrProtocol)
+		}
+	}
+	if op >= opClose && (n > 125 || !fin) {
+		return false, 0, nil, c.fail(CloseProtocolError, ErrProtocol)
+	}
+	if n > uint64(c.limit()) {
+		return false, 0, nil, c.fail(CloseTooBig, fmt.Errorf("websocket: message exceeds %d bytes", c.limit()))
+	}
+	var key [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, key[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	// The buffer grows as the payload arrives, so that the length in
+	// the header alone does not decide how much is allocated.
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, c.br, int64(n)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return false, 0, nil, err
+	}
+	payload = buf.Bytes()
+	if masked {
+		mask(payload, key)
+	}
+	return fin, op, payload, nil
+}
+
+// WriteMessage writes a data message.
+func (c *Conn) WriteMessage(typ MessageType, data []byte) error {
+	return c.writeFrame(byte(typ), data)
+}
+
+func (c *Conn) writeFrame(op byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closeSent {
+		return net.ErrClosed
+	}
+	if op == opClose {
+		c.closeSent = true
+	}
+	buf := make([]byte, 0, 14+len(payload))
+	buf = append(buf, 0x80|op)
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		buf = append(buf, maskBit|byte(n))
+	case n <= 0xffff:
+		buf = append(buf, maskBit|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, maskBit|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+	start := len(buf)
+	if c.client {
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		buf = append(buf, key[:]...)
+		start = len(buf)
+		buf = append(buf, payload...)
+		mask(buf[start:], key)
+	} else {
+		buf = append(buf, payload...)
+	}
+	_, err := c.conn.Write(buf)
+	return err
+}
+
+func mask(b []byte, key [4]byte) {
+	for i := range b {
+		b[i] ^= key[i%4]
+	}
+}
+
+// writeClose sends a close message with payload, if none was sent.
+func (c *Conn) writeClose(payload []byte) {
+	c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+	c.writeFrame(opClose, payload)
+}
+
+// fail closes the connection with code and returns err.
+func (c *Conn) fail(code int, err error) error {
+	c.writeClose(binary.BigEndian.AppendUint16(nil, uint16(code)))
+	c.conn.Close()
+	return err
+}
+
+// CloseWith sends a close message with code and reason and closes the
+// connection, without waiting for the peer to answer.
+func (c *Conn) CloseWith(code int, reason string) error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.writeClose(append(binary.BigEndian.AppendUint16(nil, uint16(code)), reason...))
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// Close closes the connection normally.
+func (c *Conn) Close() error {
+	return c.CloseWith(CloseNormal, "")
+}
@@ -0,0 +1,176 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echoServer echoes messages until the client closes the connection.
+func echoServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, []string{"echo"})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			typ, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if string(data) == "close" {
+				conn.CloseWith(4400, "bye")
+				return
+			}
+			if err := conn.WriteMessage(typ, data); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func wsURL(srv *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestEcho(t *testing.T) {
+	srv := echoServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	d := &Dialer{Subprotocols: []string{"other", "echo"}}
+	conn, err := d.Dial(ctx, wsURL(srv), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	if got := conn.Subprotocol(); got != "echo" {
+		t.Errorf("subprotocol got %q, want echo", got)
+	}
+	tests := []struct {
+		typ  MessageType
+		data []byte
+	}{
+		{TextMessage, []byte("hello")},
+		{TextMessage, []byte{}},
+		{BinaryMessage, bytes.Repeat([]byte{1, 2, 3}, 100)},
+		{TextMessage, bytes.Repeat([]byte("a"), 70000)},
+	}
+	for _, tt := range tests {
+		if err := conn.WriteMessage(tt.typ, tt.data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		typ, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if typ != tt.typ || !bytes.Equal(data, tt.data) {
+			t.Errorf("got type %d with %d bytes, want type %d with %d bytes", typ, len(data), tt.typ, len(tt.data))
+		}
+	}
+
+	if err := conn.WriteMessage(TextMessage, []byte("close")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, _, err = conn.ReadMessage()
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != 4400 || closeErr.Reason != "bye" {
+		t.Errorf("error got %v, want close 4400 bye", err)
+	}
+}
+
+func TestReadLimit(t *testing.T) {
+	srv := echoServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	conn, err := (&Dialer{}).Dial(ctx, wsURL(srv), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadLimit(10)
+	if err := conn.WriteMessage(TextMessage, []byte("more than ten bytes")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := conn.ReadMessage(); err == nil || !strings.Contains(err.Error(), "exceeds 10 bytes") {
+		t.Errorf("error got %v, want the message to exceed the limit", err)
+	}
+}
+
+func TestFrameLength(t *testing.T) {
+	tests := []struct {
+		length uint64
+		want   error
+	}{
+		// The most significant bit must be 0.
+		{1 << 63, ErrProtocol},
+		// Lengths above the default limit are refused before reading.
+		{1 << 40, nil},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, err := Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			header := []byte{0x80 | byte(BinaryMessage), 127, 0, 0, 0, 0, 0, 0, 0, 0}
+			binary.BigEndian.PutUint64(header[2:], tt.length)
+			conn.conn.Write(header)
+			conn.ReadMessage()
+		}))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+
+		conn, err := (&Dialer{}).Dial(ctx, wsURL(srv), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, _, err = conn.ReadMessage()
+		switch {
+		case tt.want != nil && !errors.Is(err, tt.want):
+			t.Errorf("length %d: got %v, want %v", tt.length, err, tt.want)
+		case tt.want == nil && (err == nil || !strings.Contains(err.Error(), "exceeds")):
+			t.Errorf("length %d: got %v, want the message to exceed the limit", tt.length, err)
+		}
+		conn.Close()
+		cancel()
+		srv.Close()
+	}
+}
+
+func TestDialErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := (&Dialer{}).Dial(ctx, wsURL(srv), nil); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("error got %v, want the handshake to fail with 403", err)
+	}
+	if _, err := (&Dialer{}).Dial(ctx, srv.URL, nil); err == nil {
+		t.Errorf("expected an error for an http URL")
+	}
+
+	// A server that never answers the handshake.
+	hang := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer hang.Close()
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := (&Dialer{}).Dial(ctx, wsURL(hang), nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,259 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Iterate runs req and iterates the elements of the list at path in the
+// response data, such as "items.edges". Elements are decoded one at a
+// time as the body is read, so a large list is never held in memory as
+// a whole:
+//
+//	it, err := client.Iterate(ctx, req, "items.edges")
+//	if err != nil {
+//	    return err
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//	    var edge Edge
+//	    if err := it.Decode(&edge); err != nil {
+//	        return err
+//	    }
+//	}
+//	if err := it.Err(); err != nil {
+//	    return err
+//	}
+//
+// The body is tokenized with encoding/json and each element is decoded
+// with the Client's Codec. The body is not logged.
+func (c *Client) Iterate(ctx context.Context, req *Request, path string) (*Iterator, error) {
+	if path == "" {
+		return nil, errors.New("graphql: empty iterate path")
+	}
+	ctx, call := c.startCall(ctx, req)
+	var res *http.Response
+	var err error
+	for {
+		res, _, err = c.send(ctx, req, nil, call)
+		// The body is not read before iterating, so only a 401 status
+		// gets a new token.
+		unauthorized := err == nil && res.StatusCode == http.StatusUnauthorized
+		if !(unauthorized && c.retryAuth(req, call)) && !c.failover(ctx, req, call) {
+			break
+		}
+		if err == nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+	}
+	if err != nil {
+		c.endCall(ctx, call, err)
+		return nil, err
+	}
+	body, err := c.responseBody(res)
+	if err != nil {
+		res.Body.Close()
+		c.endCall(ctx, call, err)
+		return nil, err
+	}
+	body = call.capture(body)
+	it := &Iterator{
+		c:    c,
+		ctx:  ctx,
+		call: call,
+		res:  res,
+		body: body,
+		dec:  json.NewDecoder(body),
+		d:    &decoder{scalars: c.scalars, codec: c.codec, useNumber: c.useNumber},
+		path: append([]string{"data"}, strings.Split(path, ".")...),
+	}
+	if tok, err := it.dec.Token(); err != nil || tok != json.Delim('{') {
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			err := fmt.Errorf("graphql: server returned a non-200 status code: %v", res.StatusCode)
+			c.endCall(ctx, call, err)
+			return nil, err
+		}
+		if err == nil {
+			err = errors.New("response is not an object")
+		}
+		err = fmt.Errorf("decoding response: %w", err)
+		c.endCall(ctx, call, err)
+		return nil, err
+	}
+	it.depth = 1
+	return it, nil
+}
+
+// Iterator iterates the elements of a list in a response. See
+// Client.Iterate.
+type Iterator struct {
+	c    *Client
+	ctx  context.Context
+	call *call
+	res  *http.Response
+	body io.Reader
+	dec  *json.Decoder
+	d    *decoder
+	// path is the keys of the list from the top of the response.
+	path []string
+	// depth is the number of objects the decoder is in.
+	depth int
+
+	started  bool
+	listRead bool
+	pending  bool
+	done     bool
+	errors   []graphErr
+	err      error
+	// decodeTime is the time spent in Decode.
+	decodeTime time.Duration
+}
+
+// Next advances to the next element, and reports whether there is one.
+// An element that is not decoded is skipped.
+func (it *Iterator) Next() bool {
+	if it.done {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		found, err := it.scan()
+		if err != nil || !found {
+			it.finish(err)
+			return false
+		}
+	} else if it.pending {
+		it.pending = false
+		var skip json.RawMessage
+		if err := it.dec.Decode(&skip); err != nil {
+			it.finish(err)
+			return false
+		}
+	}
+	if it.dec.More() {
+		it.pending = true
+		return true
+	}
+	if _, err := it.dec.Token(); err != nil {
+		it.finish(err)
+		return false
+	}
+	it.listRead = true
+	_, err := it.scan()
+	it.finish(err)
+	return false
+}
+
+// Decode decodes the current element into v, a non-nil pointer.
+func (it *Iterator) Decode(v any) error {
+	if !it.pending {
+		return errors.New("graphql: Decode called without Next")
+	}
+	it.pending = false
+	var data json.RawMessage
+	if err := it.dec.Decode(&data); err != nil {
+		it.finish(err)
+		return it.err
+	}
+	pv := reflect.ValueOf(v)
+	if pv.Kind() != reflect.Pointer || pv.IsNil() {
+		return fmt.Errorf("graphql: decode into non-pointer %T", v)
+	}
+	start := time.Now()
+	err := it.d.decode(data, pv.Elem(), typeInfo{}, "")
+	it.decodeTime += time.Since(start)
+	if err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// Err returns the error that ended the iteration, or the first error in
+// the response. It is only complete once Next has returned false.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Close closes the response body. It may be called before the list has
+// been read to stop reading.
+func (it *Iterator) Close() error {
+	if !it.done {
+		it.finish(nil)
+	}
+	if it.depth == 0 {
+		// Drain the rest so that the connection can be reused.
+		io.Copy(io.Discard, it.body)
+	}
+	return it.res.Body.Close()
+}
+
+// scan reads the response up to the start of the list and reports
+// whether it was found. Once the list has been read, it reads the rest
+// of the response. It collects the errors in the response as it goes.
+func (it *Iterator) scan() (bool, error) {
+	for it.depth > 0 {
+		for it.dec.More() {
+			tok, err := it.dec.Token()
+			if err != nil {
+				return false, err
+			}
+			key, _ := tok.(string)
+			switch {
+			case it.depth == 1 && key == "errors":
+				if err := it.dec.Decode(&it.errors); err != nil {
+					return false, err
+				}
+			case !it.listRead && key == it.path[it.depth-1]:
+				tok, err := it.dec.Token()
+				if err != nil {
+					return false, err
+				}
+				switch {
+				case tok == nil:
+				case tok == json.Delim('[') && it.depth == len(it.path):
+					return true, nil
+				case tok == json.Delim('{') && it.depth < len(it.path):
+					it.depth++
+				default:
+					return false, fmt.Errorf("%s is not a list", strings.Join(it.path[1:], "."))
+				}
+			default:
+				var skip json.RawMessage
+				if err := it.dec.Decode(&skip); err != nil {
+					return false, err
+				}
+			}
+		}
+		if _, err := it.dec.Token(); err != nil {
+			return false, err
+		}
+		it.depth--
+	}
+	return false, nil
+}
+
+// finish ends the iteration with err, or with the first error in the
+// response.
+func (it *Iterator) finish(err error) {
+	it.done = true
+	it.pending = false
+	switch {
+	case err != nil:
+		it.err = fmt.Errorf("decoding response: %w", err)
+	case len(it.errors) > 0:
+		// return first error
+		it.err = it.errors[0]
+	}
+	it.call.setErrors(it.errors)
+	it.call.timer.bodyRead(it.decodeTime)
+	it.c.endCall(it.ctx, it.call, it.err)
+}
@@ -0,0 +1,134 @@
+package graphql
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIterate(t *testing.T) {
+	srv := responseServer(t, `{"data":{"total":3,"items":{"pageInfo":{"end":"c"},"edges":[{"node":{"id":"a"}},{"node":{"id":"b"}},{"node":{"id":"c"}}],"count":3}},"extensions":{"cost":1}}`)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	it, err := client.Iterate(ctx, NewRequest("query {}"), "items.edges")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer it.Close()
+	var ids []string
+	for i := 0; it.Next(); i++ {
+		if i == 1 {
+			continue // skipped without decoding
+		}
+		var edge struct {
+			Node struct{ ID string }
+		}
+		if err := it.Decode(&edge); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, edge.Node.ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := strings.Join(ids, ","), "a,c"; got != want {
+		t.Errorf("ids got %s, want %s", got, want)
+	}
+	if it.Next() {
+		t.Errorf("Next after the end got true, want false")
+	}
+}
+
+func TestIterateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		wantN    int
+		wantErr  string
+	}{
+		{
+			name:     "errors after data",
+			response: `{"data":{"items":[1,2]},"errors":[{"message":"partial"}]}`,
+			wantN:    2,
+			wantErr:  "graphql: partial",
+		},
+		{
+			name:     "errors before null data",
+			response: `{"errors":[{"message":"boom"}],"data":null}`,
+			wantErr:  "graphql: boom",
+		},
+		{
+			name:     "null list",
+			response: `{"data":{"items":null}}`,
+		},
+		{
+			name:     "missing list",
+			response: `{"data":{"other":[1]}}`,
+		},
+		{
+			name:     "not a list",
+			response: `{"data":{"items":{"a":1}}}`,
+			wantErr:  "decoding response: items is not a list",
+		},
+		{
+			name:     "truncated",
+			response: `{"data":{"items":[1,2`,
+			wantN:    2,
+			wantErr:  "decoding response: unexpected end of JSON input",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := responseServer(t, tt.response)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			it, err := NewClient(srv.URL).Iterate(ctx, NewRequest("query {}"), "items")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer it.Close()
+			n := 0
+			for it.Next() {
+				var v int
+				if err := it.Decode(&v); err != nil {
+					break
+				}
+				n++
+			}
+			if n != tt.wantN {
+				t.Errorf("elements got %d, want %d", n, tt.wantN)
+			}
+			err = it.Err()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Errorf("err got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunLogsResponse(t *testing.T) {
+	srv := responseServer(t, `{"data":{"name":"Ada"}}`)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	var logs []string
+	client.Log = func(s string) { logs = append(logs, s) }
+	var resp struct{ Name string }
+	if err := client.Run(ctx, NewRequest("query {}"), &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Name != "Ada" {
+		t.Errorf("name got %q, want Ada", resp.Name)
+	}
+	if got, want := logs[len(logs)-1], `<< {"data":{"name":"Ada"}}`; got != want {
+		t.Errorf("last log got %s, want %s", got, want)
+	}
+}
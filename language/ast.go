@@ -0,0 +1,395 @@
+// Package language parses GraphQL documents.
+//
+// It understands both executable documents (operations and fragments)
+// and type system documents written in the schema definition language:
+//
+//	doc, err := language.ParseQuery(`query ($id: ID!) { user(id: $id) { name } }`)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, op := range doc.Operations {
+//	    fmt.Println(op.Operation, op.Name)
+//	}
+package language
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Position is a location in a source document. Lines and columns start
+// at 1.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// SyntaxError is returned when a document cannot be parsed.
+type SyntaxError struct {
+	Message  string
+	Position Position
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("graphql: syntax error at %s: %s", e.Position, e.Message)
+}
+
+// Operation is the type of an operation.
+type Operation string
+
+// Operation types.
+const (
+	Query        Operation = "query"
+	Mutation     Operation = "mutation"
+	Subscription Operation = "subscription"
+)
+
+// QueryDocument is a parsed executable document.
+type QueryDocument struct {
+	Operations []*OperationDefinition
+	Fragments  []*FragmentDefinition
+}
+
+// Operation gets the operation with the given name. An empty name
+// selects the only operation in the document.
+func (d *QueryDocument) Operation(name string) *OperationDefinition {
+	if name == "" {
+		if len(d.Operations) == 1 {
+			return d.Operations[0]
+		}
+		return nil
+	}
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op
+		}
+	}
+	return nil
+}
+
+// Fragment gets the fragment definition with the given name.
+func (d *QueryDocument) Fragment(name string) *FragmentDefinition {
+	for _, f := range d.Fragments {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// OperationDefinition is a query, mutation or subscription.
+type OperationDefinition struct {
+	Operation           Operation
+	Name                string
+	VariableDefinitions []*VariableDefinition
+	Directives          []*Directive
+	SelectionSet        SelectionSet
+	Position            Position
+}
+
+// VariableDefinition declares a variable of an operation.
+type VariableDefinition struct {
+	Variable     string
+	Type         *Type
+	DefaultValue *Value
+	Directives   []*Directive
+	Position     Position
+}
+
+// FragmentDefinition is a named fragment.
+type FragmentDefinition struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  SelectionSet
+	Position      Position
+}
+
+// SelectionSet is a list of fields and fragments.
+type SelectionSet []Selection
+
+// Selection is one of *Field, *FragmentSpread or *InlineFragment.
+type Selection interface {
+	GetPosition() Position
+	isSelection()
+}
+
+// Field is a field selection.
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet SelectionSet
+	Position     Position
+}
+
+// ResponseKey gets the key this field will have in the response.
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// FragmentSpread is a ...Name spread of a named fragment.
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Position   Position
+}
+
+// InlineFragment is a ... on Type { } selection.
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  SelectionSet
+	Position      Position
+}
+
+func (f *Field) GetPosition() Position          { return f.Position }
+func (f *FragmentSpread) GetPosition() Position { return f.Position }
+func (f *InlineFragment) GetPosition() Position { return f.Position }
+
+func (*Field) isSelection()          {}
+func (*FragmentSpread) isSelection() {}
+func (*InlineFragment) isSelection() {}
+
+// Argument is a named argument of a field or directive.
+type Argument struct {
+	Name     string
+	Value    *Value
+	Position Position
+}
+
+// Directive is an @directive applied to a definition or selection.
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Position  Position
+}
+
+// ArgumentValue gets the value of the named argument, or nil.
+func (d *Directive) ArgumentValue(name string) *Value {
+	return argumentValue(d.Arguments, name)
+}
+
+// ArgumentValue gets the value of the named argument, or nil.
+func (f *Field) ArgumentValue(name string) *Value {
+	return argumentValue(f.Arguments, name)
+}
+
+func argumentValue(args []*Argument, name string) *Value {
+	for _, arg := range args {
+		if arg.Name == name {
+			return arg.Value
+		}
+	}
+	return nil
+}
+
+// Type is a reference to a type, such as [String!]!.
+type Type struct {
+	// NamedType is set for named types and empty for lists.
+	NamedType string
+	// Elem is the element type of a list.
+	Elem     *Type
+	NonNull  bool
+	Position Position
+}
+
+// NamedTypeRef makes a reference to the named type.
+func NamedTypeRef(name string) *Type {
+	return &Type{NamedType: name}
+}
+
+// ListTypeRef makes a reference to a list of elem.
+func ListTypeRef(elem *Type) *Type {
+	return &Type{Elem: elem}
+}
+
+// NonNullTypeRef makes a non-null copy of t.
+func NonNullTypeRef(t *Type) *Type {
+	cp := *t
+	cp.NonNull = true
+	return &cp
+}
+
+// Name gets the innermost named type.
+func (t *Type) Name() string {
+	for t.Elem != nil {
+		t = t.Elem
+	}
+	return t.NamedType
+}
+
+// IsList reports whether t is a list type.
+func (t *Type) IsList() bool {
+	return t.Elem != nil
+}
+
+// Nullable gets a nullable copy of t.
+func (t *Type) Nullable() *Type {
+	if !t.NonNull {
+		return t
+	}
+	cp := *t
+	cp.NonNull = false
+	return &cp
+}
+
+func (t *Type) String() string {
+	var s string
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	} else {
+		s = t.NamedType
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Equal reports whether t and other reference the same type.
+func (t *Type) Equal(other *Type) bool {
+	if t == nil || other == nil {
+		return t == other
+	}
+	if t.NonNull != other.NonNull || t.NamedType != other.NamedType {
+		return false
+	}
+	if t.Elem == nil || other.Elem == nil {
+		return t.Elem == other.Elem
+	}
+	return t.Elem.Equal(other.Elem)
+}
+
+// ValueKind is the kind of a literal Value.
+type ValueKind int
+
+// Value kinds.
+const (
+	Variable ValueKind = iota
+	IntValue
+	FloatValue
+	StringValue
+	BlockValue
+	BooleanValue
+	NullValue
+	EnumValue
+	ListValue
+	ObjectValue
+)
+
+func (k ValueKind) String() string {
+	switch k {
+	case Variable:
+		return "Variable"
+	case IntValue:
+		return "Int"
+	case FloatValue:
+		return "Float"
+	case StringValue, BlockValue:
+		return "String"
+	case BooleanValue:
+		return "Boolean"
+	case NullValue:
+		return "Null"
+	case EnumValue:
+		return "Enum"
+	case ListValue:
+		return "List"
+	case ObjectValue:
+		return "Object"
+	}
+	return "unknown"
+}
+
+// Value is a literal value or variable reference.
+type Value struct {
+	Kind ValueKind
+	// Raw is the variable name, the unquoted string, or the literal
+	// text for other scalars.
+	Raw string
+	// Children holds list items or object fields.
+	Children []*ChildValue
+	Position Position
+}
+
+// ChildValue is a list item (with an empty Name) or an object field.
+type ChildValue struct {
+	Name     string
+	Value    *Value
+	Position Position
+}
+
+// Child gets the value of the named object field, or nil.
+func (v *Value) Child(name string) *Value {
+	for _, c := range v.Children {
+		if c.Name == name {
+			return c.Value
+		}
+	}
+	return nil
+}
+
+func (v *Value) String() string {
+	if v == nil {
+		return "<nil>"
+	}
+	switch v.Kind {
+	case Variable:
+		return "$" + v.Raw
+	case StringValue, BlockValue:
+		return quote(v.Raw)
+	case ListValue:
+		items := make([]string, len(v.Children))
+		for i, c := range v.Children {
+			items[i] = c.Value.String()
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case ObjectValue:
+		fields := make([]string, len(v.Children))
+		for i, c := range v.Children {
+			fields[i] = c.Name + ": " + c.Value.String()
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return v.Raw
+}
+
+func quote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&sb, `\u%04x`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,390 @@
+package language
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenBang
+	tokenDollar
+	tokenAmp
+	tokenParenL
+	tokenParenR
+	tokenSpread
+	tokenColon
+	tokenEquals
+	tokenAt
+	tokenBracketL
+	tokenBracketR
+	tokenBraceL
+	tokenPipe
+	tokenBraceR
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+	tokenBlockString
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "<EOF>"
+	case tokenBang:
+		return "!"
+	case tokenDollar:
+		return "$"
+	case tokenAmp:
+		return "&"
+	case tokenParenL:
+		return "("
+	case tokenParenR:
+		return ")"
+	case tokenSpread:
+		return "..."
+	case tokenColon:
+		return ":"
+	case tokenEquals:
+		return "="
+	case tokenAt:
+		return "@"
+	case tokenBracketL:
+		return "["
+	case tokenBracketR:
+		return "]"
+	case tokenBraceL:
+		return "{"
+	case tokenPipe:
+		return "|"
+	case tokenBraceR:
+		return "}"
+	case tokenName:
+		return "Name"
+	case tokenInt:
+		return "Int"
+	case tokenFloat:
+		return "Float"
+	case tokenString:
+		return "String"
+	case tokenBlockString:
+		return "BlockString"
+	}
+	return "unknown token"
+}
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   Position
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenName, tokenInt, tokenFloat:
+		return fmt.Sprintf("%s %q", t.kind, t.value)
+	case tokenString, tokenBlockString:
+		return "String"
+	}
+	return fmt.Sprintf("%q", t.kind.String())
+}
+
+// lexer splits a GraphQL source document into tokens. Whitespace,
+// commas and comments are skipped.
+type lexer struct {
+	src       string
+	offset    int
+	line      int
+	lineStart int
+}
+
+func newLexer(src string) *lexer {
+	src = strings.TrimPrefix(src, "\ufeff")
+	return &lexer{src: src, line: 1}
+}
+
+func (l *lexer) position(offset int) Position {
+	return Position{Line: l.line, Column: offset - l.lineStart + 1}
+}
+
+func (l *lexer) errorf(offset int, format string, args ...any) error {
+	return &SyntaxError{
+		Message:  fmt.Sprintf(format, args...),
+		Position: l.position(offset),
+	}
+}
+
+func (l *lexer) newline(offset int) {
+	l.line++
+	l.lineStart = offset
+}
+
+func (l *lexer) skipIgnored() {
+	for l.offset < len(l.src) {
+		switch c := l.src[l.offset]; c {
+		case ' ', '\t', ',':
+			l.offset++
+		case '\n':
+			l.offset++
+			l.newline(l.offset)
+		case '\r':
+			l.offset++
+			if l.offset < len(l.src) && l.src[l.offset] == '\n' {
+				l.offset++
+			}
+			l.newline(l.offset)
+		case '#':
+			for l.offset < len(l.src) && l.src[l.offset] != '\n' && l.src[l.offset] != '\r' {
+				l.offset++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	start := l.offset
+	pos := l.position(start)
+	if start >= len(l.src) {
+		return token{kind: tokenEOF, pos: pos}, nil
+	}
+	punct := func(k tokenKind, n int) (token, error) {
+		l.offset += n
+		return token{kind: k, value: l.src[start:l.offset], pos: pos}, nil
+	}
+	switch c := l.src[start]; {
+	case c == '!':
+		return punct(tokenBang, 1)
+	case c == '$':
+		return punct(tokenDollar, 1)
+	case c == '&':
+		return punct(tokenAmp, 1)
+	case c == '(':
+		return punct(tokenParenL, 1)
+	case c == ')':
+		return punct(tokenParenR, 1)
+	case c == '.':
+		if strings.HasPrefix(l.src[start:], "...") {
+			return punct(tokenSpread, 3)
+		}
+		return token{}, l.errorf(start, `Unexpected character "."`)
+	case c == ':':
+		return punct(tokenColon, 1)
+	case c == '=':
+		return punct(tokenEquals, 1)
+	case c == '@':
+		return punct(tokenAt, 1)
+	case c == '[':
+		return punct(tokenBracketL, 1)
+	case c == ']':
+		return punct(tokenBracketR, 1)
+	case c == '{':
+		return punct(tokenBraceL, 1)
+	case c == '|':
+		return punct(tokenPipe, 1)
+	case c == '}':
+		return punct(tokenBraceR, 1)
+	case c == '_' || isLetter(c):
+		l.offset++
+		for l.offset < len(l.src) && isNameContinue(l.src[l.offset]) {
+			l.offset++
+		}
+		return token{kind: tokenName, value: l.src[start:l.offset], pos: pos}, nil
+	case c == '-' || isDigit(c):
+		return l.readNumber(start, pos)
+	case c == '"':
+		if strings.HasPrefix(l.src[start:], `"""`) {
+			return l.readBlockString(start, pos)
+		}
+		return l.readString(start, pos)
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[start:])
+	return token{}, l.errorf(start, "Unexpected character %q", r)
+}
+
+func (l *lexer) readNumber(start int, pos Position) (token, error) {
+	kind := tokenInt
+	if l.src[l.offset] == '-' {
+		l.offset++
+	}
+	if l.offset < len(l.src) && l.src[l.offset] == '0' {
+		l.offset++
+		if l.offset < len(l.src) && isDigit(l.src[l.offset]) {
+			return token{}, l.errorf(l.offset, "Invalid number, unexpected digit after 0")
+		}
+	} else if err := l.readDigits(); err != nil {
+		return token{}, err
+	}
+	if l.offset < len(l.src) && l.src[l.offset] == '.' {
+		kind = tokenFloat
+		l.offset++
+		if err := l.readDigits(); err != nil {
+			return token{}, err
+		}
+	}
+	if l.offset < len(l.src) && (l.src[l.offset] == 'e' || l.src[l.offset] == 'E') {
+		kind = tokenFloat
+		l.offset++
+		if l.offset < len(l.src) && (l.src[l.offset] == '+' || l.src[l.offset] == '-') {
+			l.offset++
+		}
+		if err := l.readDigits(); err != nil {
+			return token{}, err
+		}
+	}
+	if l.offset < len(l.src) && (l.src[l.offset] == '.' || l.src[l.offset] == '_' || isLetter(l.src[l.offset])) {
+		return token{}, l.errorf(l.offset, "Invalid number, expected digit but got %q", l.src[l.offset])
+	}
+	return token{kind: kind, value: l.src[start:l.offset], pos: pos}, nil
+}
+
+func (l *lexer) readDigits() error {
+	if l.offset >= len(l.src) || !isDigit(l.src[l.offset]) {
+		return l.errorf(l.offset, "Invalid number, expected digit")
+	}
+	for l.offset < len(l.src) && isDigit(l.src[l.offset]) {
+		l.offset++
+	}
+	return nil
+}
+
+func (l *lexer) readString(start int, pos Position) (token, error) {
+	var sb strings.Builder
+	l.offset++
+	for l.offset < len(l.src) {
+		c := l.src[l.offset]
+		switch {
+		case c == '"':
+			l.offset++
+			return token{kind: tokenString, value: sb.String(), pos: pos}, nil
+		case c == '\n' || c == '\r':
+			return token{}, l.errorf(l.offset, "Unterminated string")
+		case c == '\\':
+			if l.offset+1 >= len(l.src) {
+				return token{}, l.errorf(l.offset, "Unterminated string")
+			}
+			esc := l.src[l.offset+1]
+			switch esc {
+			case '"', '\\', '/':
+				sb.WriteByte(esc)
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'u':
+				if l.offset+6 > len(l.src) {
+					return token{}, l.errorf(l.offset, "Invalid Unicode escape sequence")
+				}
+				code, err := strconv.ParseUint(l.src[l.offset+2:l.offset+6], 16, 32)
+				if err != nil {
+					return token{}, l.errorf(l.offset, "Invalid Unicode escape sequence %q", l.src[l.offset:l.offset+6])
+				}
+				sb.WriteRune(rune(code))
+				l.offset += 4
+			default:
+				return token{}, l.errorf(l.offset, "Invalid character escape sequence \\%c", esc)
+			}
+			l.offset += 2
+		default:
+			sb.WriteByte(c)
+			l.offset++
+		}
+	}
+	return token{}, l.errorf(l.offset, "Unterminated string")
+}
+
+func (l *lexer) readBlockString(start int, pos Position) (token, error) {
+	var sb strings.Builder
+	l.offset += 3
+	for l.offset < len(l.src) {
+		rest := l.src[l.offset:]
+		switch {
+		case strings.HasPrefix(rest, `"""`):
+			l.offset += 3
+			return token{kind: tokenBlockString, value: blockStringValue(sb.String()), pos: pos}, nil
+		case strings.HasPrefix(rest, `\"""`):
+			sb.WriteString(`"""`)
+			l.offset += 4
+		case rest[0] == '\n':
+			sb.WriteByte('\n')
+			l.offset++
+			l.newline(l.offset)
+		case rest[0] == '\r':
+			sb.WriteByte('\n')
+			l.offset++
+			if l.offset < len(l.src) && l.src[l.offset] == '\n' {
+				l.offset++
+			}
+			l.newline(l.offset)
+		default:
+			sb.WriteByte(rest[0])
+			l.offset++
+		}
+	}
+	return token{}, l.errorf(l.offset, "Unterminated string")
+}
+
+// blockStringValue implements the BlockStringValue algorithm from the
+// spec: common indentation and leading/trailing blank lines are removed.
+func blockStringValue(raw string) string {
+	lines := strings.Split(raw, "\n")
+	common := -1
+	for i, line := range lines {
+		if i == 0 {
+			continue
+		}
+		indent := leadingWhitespace(line)
+		if indent < len(line) && (common < 0 || indent < common) {
+			common = indent
+		}
+	}
+	if common > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= common {
+				lines[i] = lines[i][common:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+	for len(lines) > 0 && leadingWhitespace(lines[0]) == len(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && leadingWhitespace(lines[len(lines)-1]) == len(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func leadingWhitespace(s string) int {
+	i := 0
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameContinue(c byte) bool {
+	return c == '_' || isLetter(c) || isDigit(c)
+}
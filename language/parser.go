@@ -0,0 +1,543 @@
+package language
+
+import "fmt"
+
+type parser struct {
+	lex  *lexer
+	tok  token
+	prev token
+	err  error
+}
+
+func newParser(src string) *parser {
+	p := &parser{lex: newLexer(src)}
+	p.advance()
+	return p
+}
+
+// ParseQuery parses an executable document containing operations and
+// fragments.
+func ParseQuery(src string) (*QueryDocument, error) {
+	p := newParser(src)
+	doc := &QueryDocument{}
+	for p.err == nil && p.tok.kind != tokenEOF {
+		switch {
+		case p.tok.kind == tokenBraceL:
+			op := &OperationDefinition{Operation: Query, Position: p.tok.pos}
+			op.SelectionSet = p.parseSelectionSet()
+			doc.Operations = append(doc.Operations, op)
+		case p.peekKeyword("query"), p.peekKeyword("mutation"), p.peekKeyword("subscription"):
+			doc.Operations = append(doc.Operations, p.parseOperationDefinition())
+		case p.peekKeyword("fragment"):
+			doc.Fragments = append(doc.Fragments, p.parseFragmentDefinition())
+		default:
+			p.unexpected()
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return doc, nil
+}
+
+// ParseSchema parses a type system document written in the schema
+// definition language.
+func ParseSchema(src string) (*SchemaDocument, error) {
+	p := newParser(src)
+	doc := &SchemaDocument{}
+	for p.err == nil && p.tok.kind != tokenEOF {
+		description := p.parseDescription()
+		if p.peekKeyword("extend") {
+			if description != "" {
+				p.unexpected()
+				break
+			}
+			p.advance()
+			if p.peekKeyword("schema") {
+				doc.SchemaExtensions = append(doc.SchemaExtensions, p.parseSchemaDefinition(""))
+				continue
+			}
+			doc.Extensions = append(doc.Extensions, p.parseTypeDefinition(""))
+			continue
+		}
+		switch {
+		case p.peekKeyword("schema"):
+			doc.Schema = append(doc.Schema, p.parseSchemaDefinition(description))
+		case p.peekKeyword("directive"):
+			doc.Directives = append(doc.Directives, p.parseDirectiveDefinition(description))
+		default:
+			doc.Definitions = append(doc.Definitions, p.parseTypeDefinition(description))
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return doc, nil
+}
+
+// ParseValue parses a constant or variable value literal such as
+// {name: "x", tags: [A, B]}.
+func ParseValue(src string) (*Value, error) {
+	p := newParser(src)
+	v := p.parseValue(false)
+	if p.err == nil && p.tok.kind != tokenEOF {
+		p.unexpected()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return v, nil
+}
+
+// ParseType parses a type reference such as [String!]!.
+func ParseType(src string) (*Type, error) {
+	p := newParser(src)
+	t := p.parseType()
+	if p.err == nil && p.tok.kind != tokenEOF {
+		p.unexpected()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return t, nil
+}
+
+func (p *parser) advance() {
+	if p.err != nil {
+		return
+	}
+	p.prev = p.tok
+	tok, err := p.lex.next()
+	if err != nil {
+		p.err = err
+		p.tok = token{kind: tokenEOF}
+		return
+	}
+	p.tok = tok
+}
+
+func (p *parser) errorf(pos Position, format string, args ...any) {
+	if p.err != nil {
+		return
+	}
+	p.err = &SyntaxError{Message: fmt.Sprintf(format, args...), Position: pos}
+	p.tok = token{kind: tokenEOF}
+}
+
+func (p *parser) unexpected() {
+	p.errorf(p.tok.pos, "Unexpected %s", p.tok)
+}
+
+func (p *parser) peek(kind tokenKind) bool {
+	return p.err == nil && p.tok.kind == kind
+}
+
+func (p *parser) peekKeyword(keyword string) bool {
+	return p.peek(tokenName) && p.tok.value == keyword
+}
+
+func (p *parser) skip(kind tokenKind) bool {
+	if p.peek(kind) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind) token {
+	if p.err != nil {
+		return token{}
+	}
+	if p.tok.kind != kind {
+		p.errorf(p.tok.pos, "Expected %s, found %s", kind, p.tok)
+		return token{}
+	}
+	tok := p.tok
+	p.advance()
+	return tok
+}
+
+func (p *parser) expectKeyword(keyword string) {
+	if p.err != nil {
+		return
+	}
+	if !p.peekKeyword(keyword) {
+		p.errorf(p.tok.pos, "Expected %q, found %s", keyword, p.tok)
+		return
+	}
+	p.advance()
+}
+
+func (p *parser) parseName() string {
+	return p.expect(tokenName).value
+}
+
+// many parses open item... close, requiring at least one item.
+func (p *parser) many(open, close tokenKind, item func()) {
+	p.expect(open)
+	for p.err == nil {
+		item()
+		if p.skip(close) {
+			return
+		}
+	}
+}
+
+// optionalMany is like many but does nothing if open is not next.
+func (p *parser) optionalMany(open, close tokenKind, item func()) {
+	if p.peek(open) {
+		p.many(open, close, item)
+	}
+}
+
+func (p *parser) parseOperationDefinition() *OperationDefinition {
+	op := &OperationDefinition{Position: p.tok.pos}
+	op.Operation = Operation(p.parseName())
+	if p.peek(tokenName) {
+		op.Name = p.parseName()
+	}
+	op.VariableDefinitions = p.parseVariableDefinitions()
+	op.Directives = p.parseDirectives(false)
+	op.SelectionSet = p.parseSelectionSet()
+	return op
+}
+
+func (p *parser) parseVariableDefinitions() []*VariableDefinition {
+	var defs []*VariableDefinition
+	p.optionalMany(tokenParenL, tokenParenR, func() {
+		def := &VariableDefinition{Position: p.tok.pos}
+		p.expect(tokenDollar)
+		def.Variable = p.parseName()
+		p.expect(tokenColon)
+		def.Type = p.parseType()
+		if p.skip(tokenEquals) {
+			def.DefaultValue = p.parseValue(true)
+		}
+		def.Directives = p.parseDirectives(true)
+		defs = append(defs, def)
+	})
+	return defs
+}
+
+func (p *parser) parseFragmentDefinition() *FragmentDefinition {
+	def := &FragmentDefinition{Position: p.tok.pos}
+	p.expectKeyword("fragment")
+	if p.peekKeyword("on") {
+		p.unexpected()
+		return def
+	}
+	def.Name = p.parseName()
+	p.expectKeyword("on")
+	def.TypeCondition = p.parseName()
+	def.Directives = p.parseDirectives(false)
+	def.SelectionSet = p.parseSelectionSet()
+	return def
+}
+
+func (p *parser) parseSelectionSet() SelectionSet {
+	var set SelectionSet
+	p.many(tokenBraceL, tokenBraceR, func() {
+		set = append(set, p.parseSelection())
+	})
+	return set
+}
+
+func (p *parser) parseSelection() Selection {
+	if p.peek(tokenSpread) {
+		return p.parseFragment()
+	}
+	return p.parseField()
+}
+
+func (p *parser) parseField() *Field {
+	f := &Field{Position: p.tok.pos}
+	f.Name = p.parseName()
+	if p.skip(tokenColon) {
+		f.Alias = f.Name
+		f.Name = p.parseName()
+	}
+	f.Arguments = p.parseArguments(false)
+	f.Directives = p.parseDirectives(false)
+	if p.peek(tokenBraceL) {
+		f.SelectionSet = p.parseSelectionSet()
+	}
+	return f
+}
+
+func (p *parser) parseFragment() Selection {
+	pos := p.tok.pos
+	p.expect(tokenSpread)
+	if p.peek(tokenName) && !p.peekKeyword("on") {
+		return &FragmentSpread{
+			Name:       p.parseName(),
+			Directives: p.parseDirectives(false),
+			Position:   pos,
+		}
+	}
+	f := &InlineFragment{Position: pos}
+	if p.peekKeyword("on") {
+		p.advance()
+		f.TypeCondition = p.parseName()
+	}
+	f.Directives = p.parseDirectives(false)
+	f.SelectionSet = p.parseSelectionSet()
+	return f
+}
+
+func (p *parser) parseArguments(isConst bool) []*Argument {
+	var args []*Argument
+	p.optionalMany(tokenParenL, tokenParenR, func() {
+		arg := &Argument{Position: p.tok.pos}
+		arg.Name = p.parseName()
+		p.expect(tokenColon)
+		arg.Value = p.parseValue(isConst)
+		args = append(args, arg)
+	})
+	return args
+}
+
+func (p *parser) parseDirectives(isConst bool) []*Directive {
+	var directives []*Directive
+	for p.peek(tokenAt) {
+		d := &Directive{Position: p.tok.pos}
+		p.advance()
+		d.Name = p.parseName()
+		d.Arguments = p.parseArguments(isConst)
+		directives = append(directives, d)
+	}
+	return directives
+}
+
+func (p *parser) parseValue(isConst bool) *Value {
+	tok := p.tok
+	v := &Value{Position: tok.pos}
+	switch tok.kind {
+	case tokenBracketL:
+		v.Kind = ListValue
+		p.advance()
+		for p.err == nil && !p.skip(tokenBracketR) {
+			pos := p.tok.pos
+			v.Children = append(v.Children, &ChildValue{Value: p.parseValue(isConst), Position: pos})
+		}
+		return v
+	case tokenBraceL:
+		v.Kind = ObjectValue
+		p.advance()
+		for p.err == nil && !p.skip(tokenBraceR) {
+			pos := p.tok.pos
+			name := p.parseName()
+			p.expect(tokenColon)
+			v.Children = append(v.Children, &ChildValue{Name: name, Value: p.parseValue(isConst), Position: pos})
+		}
+		return v
+	case tokenInt:
+		v.Kind = IntValue
+	case tokenFloat:
+		v.Kind = FloatValue
+	case tokenString:
+		v.Kind = StringValue
+	case tokenBlockString:
+		v.Kind = BlockValue
+	case tokenName:
+		switch tok.value {
+		case "true", "false":
+			v.Kind = BooleanValue
+		case "null":
+			v.Kind = NullValue
+		default:
+			v.Kind = EnumValue
+		}
+	case tokenDollar:
+		if isConst {
+			p.unexpected()
+			return v
+		}
+		p.advance()
+		v.Kind = Variable
+		v.Raw = p.parseName()
+		return v
+	default:
+		p.unexpected()
+		return v
+	}
+	v.Raw = tok.value
+	p.advance()
+	return v
+}
+
+func (p *parser) parseType() *Type {
+	pos := p.tok.pos
+	var t *Type
+	if p.skip(tokenBracketL) {
+		t = &Type{Elem: p.parseType(), Position: pos}
+		p.expect(tokenBracketR)
+	} else {
+		t = &Type{NamedType: p.parseName(), Position: pos}
+	}
+	if p.skip(tokenBang) {
+		t.NonNull = true
+	}
+	return t
+}
+
+func (p *parser) parseDescription() string {
+	if p.peek(tokenString) || p.peek(tokenBlockString) {
+		desc := p.tok.value
+		p.advance()
+		return desc
+	}
+	return ""
+}
+
+func (p *parser) parseSchemaDefinition(description string) *SchemaDefinition {
+	def := &SchemaDefinition{Description: description, Position: p.tok.pos}
+	p.expectKeyword("schema")
+	def.Directives = p.parseDirectives(true)
+	p.optionalMany(tokenBraceL, tokenBraceR, func() {
+		ot := &OperationTypeDefinition{Position: p.tok.pos}
+		ot.Operation = Operation(p.parseName())
+		switch ot.Operation {
+		case Query, Mutation, Subscription:
+		default:
+			p.errorf(ot.Position, "Unexpected operation type %q", ot.Operation)
+		}
+		p.expect(tokenColon)
+		ot.Type = p.parseName()
+		def.OperationTypes = append(def.OperationTypes, ot)
+	})
+	return def
+}
+
+func (p *parser) parseTypeDefinition(description string) *TypeDefinition {
+	def := &TypeDefinition{Description: description, Position: p.tok.pos}
+	keyword := p.parseName()
+	switch keyword {
+	case "scalar":
+		def.Kind = Scalar
+		def.Name = p.parseName()
+		def.Directives = p.parseDirectives(true)
+	case "type", "interface":
+		def.Kind = Object
+		if keyword == "interface" {
+			def.Kind = Interface
+		}
+		def.Name = p.parseName()
+		def.Interfaces = p.parseImplementsInterfaces()
+		def.Directives = p.parseDirectives(true)
+		p.optionalMany(tokenBraceL, tokenBraceR, func() {
+			def.Fields = append(def.Fields, p.parseFieldDefinition())
+		})
+	case "union":
+		def.Kind = Union
+		def.Name = p.parseName()
+		def.Directives = p.parseDirectives(true)
+		if p.skip(tokenEquals) {
+			p.skip(tokenPipe)
+			def.Types = append(def.Types, p.parseName())
+			for p.skip(tokenPipe) {
+				def.Types = append(def.Types, p.parseName())
+			}
+		}
+	case "enum":
+		def.Kind = Enum
+		def.Name = p.parseName()
+		def.Directives = p.parseDirectives(true)
+		p.optionalMany(tokenBraceL, tokenBraceR, func() {
+			v := &EnumValueDefinition{Position: p.tok.pos}
+			v.Description = p.parseDescription()
+			v.Name = p.parseName()
+			switch v.Name {
+			case "true", "false", "null":
+				p.errorf(v.Position, "Name %q is reserved and cannot be used for an enum value", v.Name)
+			}
+			v.Directives = p.parseDirectives(true)
+			def.EnumValues = append(def.EnumValues, v)
+		})
+	case "input":
+		def.Kind = InputObject
+		def.Name = p.parseName()
+		def.Directives = p.parseDirectives(true)
+		p.optionalMany(tokenBraceL, tokenBraceR, func() {
+			arg := p.parseArgumentDefinition()
+			def.Fields = append(def.Fields, &FieldDefinition{
+				Description:  arg.Description,
+				Name:         arg.Name,
+				Type:         arg.Type,
+				DefaultValue: arg.DefaultValue,
+				Directives:   arg.Directives,
+				Position:     arg.Position,
+			})
+		})
+	default:
+		p.errorf(p.prev.pos, "Unexpected %s", p.prev)
+	}
+	return def
+}
+
+func (p *parser) parseImplementsInterfaces() []string {
+	if !p.peekKeyword("implements") {
+		return nil
+	}
+	p.advance()
+	p.skip(tokenAmp)
+	names := []string{p.parseName()}
+	for p.skip(tokenAmp) {
+		names = append(names, p.parseName())
+	}
+	return names
+}
+
+func (p *parser) parseFieldDefinition() *FieldDefinition {
+	def := &FieldDefinition{Position: p.tok.pos}
+	def.Description = p.parseDescription()
+	def.Position = p.tok.pos
+	def.Name = p.parseName()
+	p.optionalMany(tokenParenL, tokenParenR, func() {
+		def.Arguments = append(def.Arguments, p.parseArgumentDefinition())
+	})
+	p.expect(tokenColon)
+	def.Type = p.parseType()
+	def.Directives = p.parseDirectives(true)
+	return def
+}
+
+func (p *parser) parseArgumentDefinition() *ArgumentDefinition {
+	def := &ArgumentDefinition{}
+	def.Description = p.parseDescription()
+	def.Position = p.tok.pos
+	def.Name = p.parseName()
+	p.expect(tokenColon)
+	def.Type = p.parseType()
+	if p.skip(tokenEquals) {
+		def.DefaultValue = p.parseValue(true)
+	}
+	def.Directives = p.parseDirectives(true)
+	return def
+}
+
+func (p *parser) parseDirectiveDefinition(description string) *DirectiveDefinition {
+	def := &DirectiveDefinition{Description: description, Position: p.tok.pos}
+	p.expectKeyword("directive")
+	p.expect(tokenAt)
+	def.Name = p.parseName()
+	p.optionalMany(tokenParenL, tokenParenR, func() {
+		def.Arguments = append(def.Arguments, p.parseArgumentDefinition())
+	})
+	if p.peekKeyword("repeatable") {
+		p.advance()
+		def.Repeatable = true
+	}
+	p.expectKeyword("on")
+	p.skip(tokenPipe)
+	for p.err == nil {
+		tok := p.expect(tokenName)
+		if p.err == nil && !directiveLocations[tok.value] {
+			p.errorf(tok.pos, "Unexpected directive location %q", tok.value)
+		}
+		def.Locations = append(def.Locations, DirectiveLocation(tok.value))
+		if !p.skip(tokenPipe) {
+			break
+		}
+	}
+	return def
+}
@@ -0,0 +1,169 @@
+package language
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	doc, err := ParseQuery(`
+		query GetUser($id: ID!, $sizes: [Int!] = [1, 2]) @live {
+			user(id: $id) {
+				id
+				avatar: picture(size: 64)
+				...UserFields
+				... on Admin @include(if: true) {
+					level
+				}
+			}
+		}
+
+		fragment UserFields on User {
+			name
+			bio(format: MARKDOWN, opts: {trim: true, tags: ["a", "b"]})
+		}
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := len(doc.Operations), 1; got != want {
+		t.Fatalf("len(doc.Operations) got %v, want %v", got, want)
+	}
+	op := doc.Operations[0]
+	if got, want := op.Operation, Query; got != want {
+		t.Errorf("op.Operation got %v, want %v", got, want)
+	}
+	if got, want := op.Name, "GetUser"; got != want {
+		t.Errorf("op.Name got %v, want %v", got, want)
+	}
+	if got, want := len(op.VariableDefinitions), 2; got != want {
+		t.Fatalf("len(op.VariableDefinitions) got %v, want %v", got, want)
+	}
+	if got, want := op.VariableDefinitions[1].Type.String(), "[Int!]"; got != want {
+		t.Errorf("variable type got %v, want %v", got, want)
+	}
+	if got, want := op.VariableDefinitions[1].DefaultValue.String(), "[1, 2]"; got != want {
+		t.Errorf("default value got %v, want %v", got, want)
+	}
+	user := op.SelectionSet[0].(*Field)
+	if got, want := user.ArgumentValue("id").Kind, Variable; got != want {
+		t.Errorf("id argument kind got %v, want %v", got, want)
+	}
+	avatar := user.SelectionSet[1].(*Field)
+	if got, want := avatar.ResponseKey(), "avatar"; got != want {
+		t.Errorf("avatar.ResponseKey() got %v, want %v", got, want)
+	}
+	if got, want := avatar.Position, (Position{Line: 5, Column: 5}); got != want {
+		t.Errorf("avatar.Position got %v, want %v", got, want)
+	}
+	if _, ok := user.SelectionSet[2].(*FragmentSpread); !ok {
+		t.Errorf("selection 2 got %T, want *FragmentSpread", user.SelectionSet[2])
+	}
+	inline := user.SelectionSet[3].(*InlineFragment)
+	if got, want := inline.TypeCondition, "Admin"; got != want {
+		t.Errorf("inline.TypeCondition got %v, want %v", got, want)
+	}
+	frag := doc.Fragment("UserFields")
+	if frag == nil {
+		t.Fatal("fragment UserFields not found")
+	}
+	bio := frag.SelectionSet[1].(*Field)
+	if got, want := bio.ArgumentValue("opts").String(), `{trim: true, tags: ["a", "b"]}`; got != want {
+		t.Errorf("opts got %v, want %v", got, want)
+	}
+}
+
+func TestParseQueryShorthand(t *testing.T) {
+	doc, err := ParseQuery(`{ a b }`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := len(doc.Operations[0].SelectionSet), 2; got != want {
+		t.Errorf("len(SelectionSet) got %v, want %v", got, want)
+	}
+}
+
+func TestParseQuerySyntaxError(t *testing.T) {
+	_, err := ParseQuery("query {\n  user(id: ) { name }\n}")
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("err got %v, want *SyntaxError", err)
+	}
+	if got, want := syntaxErr.Position, (Position{Line: 2, Column: 12}); got != want {
+		t.Errorf("syntaxErr.Position got %v, want %v", got, want)
+	}
+}
+
+func TestParseStrings(t *testing.T) {
+	v, err := ParseValue(`"café \"ok\"\n"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := v.Raw, "café \"ok\"\n"; got != want {
+		t.Errorf("v.Raw got %q, want %q", got, want)
+	}
+	v, err = ParseValue("\"\"\"\n    Hello,\n      World!\n\n    Bye\n  \"\"\"")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := v.Raw, "Hello,\n  World!\n\nBye"; got != want {
+		t.Errorf("block string got %q, want %q", got, want)
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	doc, err := ParseSchema(`
+		schema { query: Root }
+
+		"""
+		A person.
+		"""
+		type User implements Node & Named @key(fields: "id") {
+			id: ID!
+			"The name"
+			name(upper: Boolean = false): String @deprecated(reason: "use fullName")
+		}
+
+		interface Node { id: ID! }
+		union SearchResult = | User | Post
+		enum Role { ADMIN USER }
+		input Filter { role: Role = USER, limit: Int! }
+		scalar DateTime @specifiedBy(url: "https://example.com")
+		directive @key(fields: String!) repeatable on OBJECT | INTERFACE
+		extend type User { email: String }
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := doc.Schema[0].OperationTypes[0].Type, "Root"; got != want {
+		t.Errorf("query root got %v, want %v", got, want)
+	}
+	if got, want := len(doc.Definitions), 6; got != want {
+		t.Fatalf("len(doc.Definitions) got %v, want %v", got, want)
+	}
+	user := doc.Definitions[0]
+	if got, want := user.Description, "A person."; got != want {
+		t.Errorf("user.Description got %q, want %q", got, want)
+	}
+	if got, want := len(user.Interfaces), 2; got != want {
+		t.Errorf("len(user.Interfaces) got %v, want %v", got, want)
+	}
+	if got, want := user.Fields[1].Arguments[0].DefaultValue.String(), "false"; got != want {
+		t.Errorf("default got %v, want %v", got, want)
+	}
+	if got, want := doc.Definitions[2].Types, []string{"User", "Post"}; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("union types got %v, want %v", got, want)
+	}
+	if got, want := doc.Definitions[4].Fields[1].Type.String(), "Int!"; got != want {
+		t.Errorf("input field type got %v, want %v", got, want)
+	}
+	if got, want := doc.Directives[0].Locations, []DirectiveLocation{LocationObject, LocationInterface}; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("locations got %v, want %v", got, want)
+	}
+	if !doc.Directives[0].Repeatable {
+		t.Error("directive should be repeatable")
+	}
+	if got, want := doc.Extensions[0].Name, "User"; got != want {
+		t.Errorf("extension name got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+package language
+
+import (
+	"strings"
+)
+
+// Print formats doc as a GraphQL document in a canonical layout:
+// operations first, then fragments, indented with two spaces.
+func Print(doc *QueryDocument) string {
+	p := &printer{}
+	for i, op := range doc.Operations {
+		if i > 0 {
+			p.sb.WriteString("\n")
+		}
+		p.operation(op)
+	}
+	for i, frag := range doc.Fragments {
+		if i > 0 || len(doc.Operations) > 0 {
+			p.sb.WriteString("\n")
+		}
+		p.fragment(frag)
+	}
+	return p.sb.String()
+}
+
+type printer struct {
+	sb     strings.Builder
+	indent int
+}
+
+func (p *printer) operation(op *OperationDefinition) {
+	anonymousQuery := op.Operation == Query && op.Name == "" && len(op.VariableDefinitions) == 0 && len(op.Directives) == 0
+	if !anonymousQuery {
+		p.sb.WriteString(string(op.Operation))
+		if op.Name != "" {
+			p.sb.WriteString(" " + op.Name)
+		}
+		if len(op.VariableDefinitions) > 0 {
+			p.sb.WriteString("(")
+			for i, def := range op.VariableDefinitions {
+				if i > 0 {
+					p.sb.WriteString(", ")
+				}
+				p.sb.WriteString("$" + def.Variable + ": " + def.Type.String())
+				if def.DefaultValue != nil {
+					p.sb.WriteString(" = " + def.DefaultValue.String())
+				}
+				p.directives(def.Directives)
+			}
+			p.sb.WriteString(")")
+		}
+		p.directives(op.Directives)
+		p.sb.WriteString(" ")
+	}
+	p.selectionSet(op.SelectionSet)
+	p.sb.WriteString("\n")
+}
+
+func (p *printer) fragment(frag *FragmentDefinition) {
+	p.sb.WriteString("fragment " + frag.Name + " on " + frag.TypeCondition)
+	p.directives(frag.Directives)
+	p.sb.WriteString(" ")
+	p.selectionSet(frag.SelectionSet)
+	p.sb.WriteString("\n")
+}
+
+func (p *printer) selectionSet(set SelectionSet) {
+	p.sb.WriteString("{")
+	p.indent++
+	for _, sel := range set {
+		p.sb.WriteString("\n" + strings.Repeat("  ", p.indent))
+		p.selection(sel)
+	}
+	p.indent--
+	p.sb.WriteString("\n" + strings.Repeat("  ", p.indent) + "}")
+}
+
+func (p *printer) selection(sel Selection) {
+	switch sel := sel.(type) {
+	case *Field:
+		if sel.Alias != "" {
+			p.sb.WriteString(sel.Alias + ": ")
+		}
+		p.sb.WriteString(sel.Name)
+		p.arguments(sel.Arguments)
+		p.directives(sel.Directives)
+		if len(sel.SelectionSet) > 0 {
+			p.sb.WriteString(" ")
+			p.selectionSet(sel.SelectionSet)
+		}
+	case *FragmentSpread:
+		p.sb.WriteString("..." + sel.Name)
+		p.directives(sel.Directives)
+	case *InlineFragment:
+		p.sb.WriteString("...")
+		if sel.TypeCondition != "" {
+			p.sb.WriteString(" on " + sel.TypeCondition)
+		}
+		p.directives(sel.Directives)
+		p.sb.WriteString(" ")
+		p.selectionSet(sel.SelectionSet)
+	}
+}
+
+func (p *printer) arguments(args []*Argument) {
+	if len(args) == 0 {
+		return
+	}
+	p.sb.WriteString("(")
+	for i, arg := range args {
+		if i > 0 {
+			p.sb.WriteString(", ")
+		}
+		p.sb.WriteString(arg.Name + ": " + arg.Value.String())
+	}
+	p.sb.WriteString(")")
+}
+
+func (p *printer) directives(directives []*Directive) {
+	for _, d := range directives {
+		p.sb.WriteString(" @" + d.Name)
+		p.arguments(d.Arguments)
+	}
+}
@@ -0,0 +1,48 @@
+package language
+
+import "testing"
+
+func TestPrint(t *testing.T) {
+	doc, err := ParseQuery(`query GetUser($id: ID!, $n: Int = 10) @live { user(id: $id) { id, ...F ... on Admin @include(if: true) { level } friends(first: $n, filter: {name: "a\"b"}) { name } } } fragment F on User { name }`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `query GetUser($id: ID!, $n: Int = 10) @live {
+  user(id: $id) {
+    id
+    ...F
+    ... on Admin @include(if: true) {
+      level
+    }
+    friends(first: $n, filter: {name: "a\"b"}) {
+      name
+    }
+  }
+}
+
+fragment F on User {
+  name
+}
+`
+	got := Print(doc)
+	if got != want {
+		t.Errorf("Print got:\n%s\nwant:\n%s", got, want)
+	}
+	reparsed, err := ParseQuery(got)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again := Print(reparsed); again != got {
+		t.Errorf("Print is not stable:\n%s", again)
+	}
+}
+
+func TestPrintShorthand(t *testing.T) {
+	doc, err := ParseQuery(`{ a }`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := Print(doc), "{\n  a\n}\n"; got != want {
+		t.Errorf("Print got %q, want %q", got, want)
+	}
+}
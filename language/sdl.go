@@ -0,0 +1,134 @@
+package language
+
+// SchemaDocument is a parsed type system document.
+type SchemaDocument struct {
+	Schema           []*SchemaDefinition
+	SchemaExtensions []*SchemaDefinition
+	Definitions      []*TypeDefinition
+	Extensions       []*TypeDefinition
+	Directives       []*DirectiveDefinition
+}
+
+// SchemaDefinition is a schema { query: Query } definition or extension.
+type SchemaDefinition struct {
+	Description    string
+	Directives     []*Directive
+	OperationTypes []*OperationTypeDefinition
+	Position       Position
+}
+
+// OperationTypeDefinition maps an operation to its root type.
+type OperationTypeDefinition struct {
+	Operation Operation
+	Type      string
+	Position  Position
+}
+
+// DefinitionKind is the kind of a type definition. The values match
+// the __TypeKind enum used by introspection.
+type DefinitionKind string
+
+// Definition kinds.
+const (
+	Scalar      DefinitionKind = "SCALAR"
+	Object      DefinitionKind = "OBJECT"
+	Interface   DefinitionKind = "INTERFACE"
+	Union       DefinitionKind = "UNION"
+	Enum        DefinitionKind = "ENUM"
+	InputObject DefinitionKind = "INPUT_OBJECT"
+)
+
+// TypeDefinition is a named type definition or extension.
+type TypeDefinition struct {
+	Kind        DefinitionKind
+	Description string
+	Name        string
+	// Interfaces lists implemented interfaces of objects and interfaces.
+	Interfaces []string
+	Directives []*Directive
+	// Fields holds object and interface fields, and input object fields.
+	Fields []*FieldDefinition
+	// Types lists the members of a union.
+	Types      []string
+	EnumValues []*EnumValueDefinition
+	Position   Position
+}
+
+// FieldDefinition is a field of an object, interface or input object.
+type FieldDefinition struct {
+	Description string
+	Name        string
+	Arguments   []*ArgumentDefinition
+	// DefaultValue is only set for input object fields.
+	DefaultValue *Value
+	Type         *Type
+	Directives   []*Directive
+	Position     Position
+}
+
+// ArgumentDefinition is an argument of a field or directive.
+type ArgumentDefinition struct {
+	Description  string
+	Name         string
+	Type         *Type
+	DefaultValue *Value
+	Directives   []*Directive
+	Position     Position
+}
+
+// EnumValueDefinition is a value of an enum.
+type EnumValueDefinition struct {
+	Description string
+	Name        string
+	Directives  []*Directive
+	Position    Position
+}
+
+// DirectiveLocation is a place a directive may be used.
+type DirectiveLocation string
+
+// Executable directive locations.
+const (
+	LocationQuery              DirectiveLocation = "QUERY"
+	LocationMutation           DirectiveLocation = "MUTATION"
+	LocationSubscription       DirectiveLocation = "SUBSCRIPTION"
+	LocationField              DirectiveLocation = "FIELD"
+	LocationFragmentDefinition DirectiveLocation = "FRAGMENT_DEFINITION"
+	LocationFragmentSpread     DirectiveLocation = "FRAGMENT_SPREAD"
+	LocationInlineFragment     DirectiveLocation = "INLINE_FRAGMENT"
+	LocationVariableDefinition DirectiveLocation = "VARIABLE_DEFINITION"
+)
+
+// Type system directive locations.
+const (
+	LocationSchema               DirectiveLocation = "SCHEMA"
+	LocationScalar               DirectiveLocation = "SCALAR"
+	LocationObject               DirectiveLocation = "OBJECT"
+	LocationFieldDefinition      DirectiveLocation = "FIELD_DEFINITION"
+	LocationArgumentDefinition   DirectiveLocation = "ARGUMENT_DEFINITION"
+	LocationInterface            DirectiveLocation = "INTERFACE"
+	LocationUnion                DirectiveLocation = "UNION"
+	LocationEnum                 DirectiveLocation = "ENUM"
+	LocationEnumValue            DirectiveLocation = "ENUM_VALUE"
+	LocationInputObject          DirectiveLocation = "INPUT_OBJECT"
+	LocationInputFieldDefinition DirectiveLocation = "INPUT_FIELD_DEFINITION"
+)
+
+var directiveLocations = map[string]bool{
+	"QUERY": true, "MUTATION": true, "SUBSCRIPTION": true, "FIELD": true,
+	"FRAGMENT_DEFINITION": true, "FRAGMENT_SPREAD": true, "INLINE_FRAGMENT": true,
+	"VARIABLE_DEFINITION": true, "SCHEMA": true, "SCALAR": true, "OBJECT": true,
+	"FIELD_DEFINITION": true, "ARGUMENT_DEFINITION": true, "INTERFACE": true,
+	"UNION": true, "ENUM": true, "ENUM_VALUE": true, "INPUT_OBJECT": true,
+	"INPUT_FIELD_DEFINITION": true,
+}
+
+// DirectiveDefinition is a directive @name on LOCATIONS definition.
+type DirectiveDefinition struct {
+	Description string
+	Name        string
+	Arguments   []*ArgumentDefinition
+	Repeatable  bool
+	Locations   []DirectiveLocation
+	Position    Position
+}
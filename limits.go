@@ -0,0 +1,117 @@
+package graphql
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ResponseTooLargeError is returned when a response body is larger than
+// the limit set with WithMaxResponseSize or WithMaxDecompressedSize.
+// Reading stops at the limit.
+type ResponseTooLargeError struct {
+	// Limit is the limit in bytes.
+	Limit int64
+	// Decompressed reports whether the limit was on the decompressed
+	// body.
+	Decompressed bool
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	if e.Decompressed {
+		return fmt.Sprintf("graphql: decompressed response body exceeds %d bytes", e.Limit)
+	}
+	return fmt.Sprintf("graphql: response body exceeds %d bytes", e.Limit)
+}
+
+// WithMaxResponseSize limits response bodies to n bytes as they are
+// received. If the http.Client's Transport decompresses a body itself,
+// which it does when the request does not set Accept-Encoding, the
+// received size is not known and the limit applies to the decompressed
+// body.
+func WithMaxResponseSize(n int64) ClientOption {
+	return func(client *Client) {
+		client.maxResponseSize = n
+	}
+}
+
+// WithMaxDecompressedSize limits response bodies to n bytes after they
+// are decompressed, to protect against compression bombs.
+func WithMaxDecompressedSize(n int64) ClientOption {
+	return func(client *Client) {
+		client.maxDecompressedSize = n
+	}
+}
+
+// responseBody returns a reader of the decompressed body of res, with
+// the size limits applied.
+func (c *Client) responseBody(res *http.Response) (io.Reader, error) {
+	var body io.Reader = res.Body
+	if res.Uncompressed {
+		return limit(body, c.maxResponseSize, c.maxDecompressedSize), nil
+	}
+	if c.maxResponseSize > 0 && res.ContentLength > c.maxResponseSize {
+		return nil, &ResponseTooLargeError{Limit: c.maxResponseSize}
+	}
+	if c.maxResponseSize > 0 {
+		body = &limitReader{r: body, n: c.maxResponseSize, err: &ResponseTooLargeError{Limit: c.maxResponseSize}}
+	}
+	encodings := strings.Split(res.Header.Get("Content-Encoding"), ",")
+	decompressed := false
+	// Encodings are listed in the order they were applied.
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if encoding == "" || encoding == "identity" {
+			continue
+		}
+		decompress := c.decompressor(encoding)
+		if decompress == nil {
+			return nil, fmt.Errorf("graphql: unsupported Content-Encoding %q", encoding)
+		}
+		var err error
+		if body, err = decompress(body); err != nil {
+			return nil, fmt.Errorf("decompressing response: %w", err)
+		}
+		decompressed = true
+	}
+	if decompressed && c.maxDecompressedSize > 0 {
+		body = &limitReader{r: body, n: c.maxDecompressedSize, err: &ResponseTooLargeError{Limit: c.maxDecompressedSize, Decompressed: true}}
+	}
+	return body, nil
+}
+
+// limit applies the smaller of the limits max and maxDecompressed to a
+// body that was decompressed by the Transport.
+func limit(body io.Reader, max, maxDecompressed int64) io.Reader {
+	err := &ResponseTooLargeError{Limit: max}
+	if maxDecompressed > 0 && (max <= 0 || maxDecompressed < max) {
+		err = &ResponseTooLargeError{Limit: maxDecompressed, Decompressed: true}
+	}
+	if err.Limit <= 0 {
+		return body
+	}
+	return &limitReader{r: body, n: err.Limit, err: err}
+}
+
+// limitReader reads from r until more than n bytes have been read, and
+// then fails with err.
+type limitReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.n {
+		n = int(l.n)
+		l.n = 0
+		return n, l.err
+	}
+	l.n -= int64(n)
+	return n, err
+}
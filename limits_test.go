@@ -0,0 +1,163 @@
+package graphql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func bigResponse(n int) string {
+	return `{"data":{"name":"` + strings.Repeat("a", n) + `"}}`
+}
+
+func gzipServer(t *testing.T, response string) *httptest.Server {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	io.WriteString(zw, response)
+	zw.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(buf.Bytes())
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		chunked bool
+		size    int
+		wantErr bool
+	}{
+		{name: "under the limit", size: 100},
+		{name: "over the limit", size: 2000, wantErr: true},
+		{name: "over the limit chunked", size: 2000, chunked: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, `{"data":`)
+				if tt.chunked {
+					w.(http.Flusher).Flush()
+				}
+				io.WriteString(w, bigResponse(tt.size)[8:])
+			}))
+			defer srv.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			client := NewClient(srv.URL, WithMaxResponseSize(1000))
+			var resp struct{ Name string }
+			err := client.Run(ctx, NewRequest("query {}"), &resp)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var tooLarge *ResponseTooLargeError
+			if !errors.As(err, &tooLarge) {
+				t.Fatalf("err got %v, want a ResponseTooLargeError", err)
+			}
+			if tooLarge.Limit != 1000 || tooLarge.Decompressed {
+				t.Errorf("got %+v, want a limit of 1000 on the received body", tooLarge)
+			}
+		})
+	}
+}
+
+func TestMaxDecompressedSize(t *testing.T) {
+	// A body that compresses to far below the limit.
+	srv := gzipServer(t, bigResponse(1<<20))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for _, acceptEncoding := range []string{"gzip", ""} {
+		client := NewClient(srv.URL, WithMaxResponseSize(1<<20), WithMaxDecompressedSize(1<<16))
+		req := NewRequest("query {}")
+		if acceptEncoding != "" {
+			// The Transport leaves the body compressed.
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		var resp struct{ Name string }
+		err := client.Run(ctx, req, &resp)
+		var tooLarge *ResponseTooLargeError
+		if !errors.As(err, &tooLarge) {
+			t.Fatalf("Accept-Encoding %q: err got %v, want a ResponseTooLargeError", acceptEncoding, err)
+		}
+		if tooLarge.Limit != 1<<16 || !tooLarge.Decompressed {
+			t.Errorf("Accept-Encoding %q: got %+v, want a limit of %d on the decompressed body", acceptEncoding, tooLarge, 1<<16)
+		}
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	srv := gzipServer(t, `{"data":{"name":"Ada"}}`)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	req := NewRequest("query {}")
+	req.Header.Set("Accept-Encoding", "gzip")
+	var resp struct{ Name string }
+	if err := NewClient(srv.URL, WithMaxDecompressedSize(100)).Run(ctx, req, &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Name != "Ada" {
+		t.Errorf("name got %q, want Ada", resp.Name)
+	}
+}
+
+func TestWithDecompressor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		io.WriteString(w, `{"data":{"name":"Ada"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var resp struct{ Name string }
+	err := NewClient(srv.URL).Run(ctx, NewRequest("query {}"), &resp)
+	if err == nil || err.Error() != `graphql: unsupported Content-Encoding "br"` {
+		t.Fatalf("err got %v, want unsupported Content-Encoding", err)
+	}
+
+	called := false
+	identity := func(r io.Reader) (io.Reader, error) {
+		called = true
+		return r, nil
+	}
+	client := NewClient(srv.URL, WithDecompressor("br", identity))
+	if err := client.Run(ctx, NewRequest("query {}"), &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !called || resp.Name != "Ada" {
+		t.Errorf("got called %v and name %q, want true and Ada", called, resp.Name)
+	}
+}
+
+func TestIterateMaxResponseSize(t *testing.T) {
+	srv := responseServer(t, `{"data":{"items":[`+strings.Repeat(`"aaaa",`, 1000)+`"a"]}}`)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	it, err := NewClient(srv.URL, WithMaxResponseSize(1000)).Iterate(ctx, NewRequest("query {}"), "items")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer it.Close()
+	for it.Next() {
+	}
+	var tooLarge *ResponseTooLargeError
+	if !errors.As(it.Err(), &tooLarge) {
+		t.Errorf("err got %v, want a ResponseTooLargeError", it.Err())
+	}
+}
@@ -0,0 +1,254 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// redacted replaces the values of redacted headers and variables.
+const redacted = "[REDACTED]"
+
+// defaultLogBodyLimit is the number of bytes of bodies logged by default.
+const defaultLogBodyLimit = 4096
+
+// defaultRedactedHeaders are the headers that are always redacted.
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// WithLogger logs every request to logger. A record is logged when the
+// request completes, with the operation name and type, duration and its
+// Timing, HTTP status, number of GraphQL errors and body sizes: at Info
+// level if it succeeded, at Warn if the response has errors, and at
+// Error if the request failed.
+//
+// At Debug level the query, variables, headers and response body are
+// logged as well. Bodies are truncated, see WithLogBodyLimit, and
+// secrets are redacted: the Authorization, Cookie and API key headers,
+// and the headers and variables given to WithRedactedHeaders and
+// WithRedactedVariables.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(client *Client) {
+		client.logger = logger
+	}
+}
+
+// WithRedactedHeaders redacts the values of the headers names in logs.
+func WithRedactedHeaders(names ...string) ClientOption {
+	return func(client *Client) {
+		client.redactedHeaders = append(client.redactedHeaders, names...)
+	}
+}
+
+// WithRedactedVariables redacts variable values in logs. Each path is a
+// variable name followed by the keys of input object fields, separated
+// by dots, such as "input.password". Lists along the path are redacted
+// element by element.
+func WithRedactedVariables(paths ...string) ClientOption {
+	return func(client *Client) {
+		client.redactedVariables = append(client.redactedVariables, paths...)
+	}
+}
+
+// WithLogBodyLimit truncates the query, variables and response body in
+// debug logs to n bytes each. The default is 4096; a negative n logs
+// them whole.
+func WithLogBodyLimit(n int) ClientOption {
+	return func(client *Client) {
+		client.logBodyLimit = n
+	}
+}
+
+func (c *Client) bodyLimit() int {
+	if c.logBodyLimit == 0 {
+		return defaultLogBodyLimit
+	}
+	return c.logBodyLimit
+}
+
+// logRequest logs the request at debug level.
+func (c *Client) logRequest(ctx context.Context, cl *call, req *Request, r *http.Request) {
+	if c.logger == nil || !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "graphql request",
+		slog.String("operation", cl.operation),
+		slog.String("query", truncate(req.q, c.bodyLimit())),
+		slog.String("variables", truncate(c.redactVariables(req.vars), c.bodyLimit())),
+		slog.Any("headers", c.redactHeaders(r.Header)),
+	)
+}
+
+// logCall logs the outcome of a request.
+func (c *Client) logCall(ctx context.Context, cl *call, err error) {
+	if c.logger == nil {
+		return
+	}
+	level := slog.LevelInfo
+	switch {
+	case cl.errors > 0:
+		level = slog.LevelWarn
+	case err != nil:
+		level = slog.LevelError
+	}
+	if !c.logger.Enabled(ctx, level) {
+		return
+	}
+	if cl.body != nil && cl.body.size > 0 {
+		c.logger.LogAttrs(ctx, slog.LevelDebug, "graphql response body",
+			slog.String("operation", cl.operation),
+			slog.String("body", cl.body.String()),
+		)
+	}
+	attrs := []slog.Attr{
+		slog.String("operation", cl.operation),
+		slog.String("operation_type", cl.operationType),
+		slog.Duration("duration", cl.timing.Total),
+		slog.Int("status", cl.status),
+		slog.Int("errors", cl.errors),
+		slog.Int64("request_size", cl.requestSize),
+		slog.Int64("response_size", cl.responseSize),
+		slog.Int("retries", cl.retries),
+		slog.Group("timing",
+			slog.Duration("dns", cl.timing.DNS),
+			slog.Duration("connect", cl.timing.Connect),
+			slog.Duration("tls", cl.timing.TLS),
+			slog.Duration("ttfb", cl.timing.TTFB),
+			slog.Duration("download", cl.timing.Download),
+			slog.Duration("decode", cl.timing.Decode),
+		),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	c.logger.LogAttrs(ctx, level, "graphql response", attrs...)
+}
+
+// redactHeaders returns the headers h as a group, with the values of
+// secret headers redacted.
+func (c *Client) redactHeaders(h http.Header) slog.Value {
+	secret := make(map[string]bool)
+	for _, name := range defaultRedactedHeaders {
+		secret[http.CanonicalHeaderKey(name)] = true
+	}
+	for _, name := range c.redactedHeaders {
+		secret[http.CanonicalHeaderKey(name)] = true
+	}
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attrs := make([]slog.Attr, len(keys))
+	for i, key := range keys {
+		value := strings.Join(h[key], ", ")
+		if secret[http.CanonicalHeaderKey(key)] {
+			value = redacted
+		}
+		attrs[i] = slog.String(key, value)
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// redactVariables returns vars as JSON, with the values at the paths of
+// WithRedactedVariables redacted.
+func (c *Client) redactVariables(vars map[string]any) string {
+	if len(vars) == 0 {
+		return "{}"
+	}
+	data, err := json.Marshal(vars)
+	if err != nil {
+		return fmt.Sprintf("!ERROR: %v", err)
+	}
+	if len(c.redactedVariables) == 0 {
+		return string(data)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Sprintf("!ERROR: %v", err)
+	}
+	for _, path := range c.redactedVariables {
+		redactPath(v, strings.Split(path, "."))
+	}
+	data, err = json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("!ERROR: %v", err)
+	}
+	return string(data)
+}
+
+func redactPath(v any, path []string) {
+	switch v := v.(type) {
+	case map[string]any:
+		child, ok := v[path[0]]
+		switch {
+		case !ok:
+		case len(path) == 1:
+			v[path[0]] = redacted
+		default:
+			redactPath(child, path[1:])
+		}
+	case []any:
+		for _, item := range v {
+			redactPath(item, path)
+		}
+	}
+}
+
+// truncate cuts s to at most n bytes, noting how much was cut. A
+// negative n leaves s whole.
+func truncate(s string, n int) string {
+	if n < 0 || len(s) <= n {
+		return s
+	}
+	return cut(s, n, len(s))
+}
+
+// cut cuts s, the start of size bytes, to at most n bytes without
+// splitting a rune.
+func cut(s string, n, size int) string {
+	for n > 0 && n < len(s) && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return fmt.Sprintf("%s... (%d bytes truncated)", s[:n], size-n)
+}
+
+// bodyCapture keeps the first bytes written to it, enough to log limit
+// bytes, and counts them all.
+type bodyCapture struct {
+	buf   bytes.Buffer
+	limit int
+	size  int
+}
+
+func (b *bodyCapture) Write(p []byte) (int, error) {
+	b.size += len(p)
+	room := len(p)
+	if b.limit >= 0 {
+		room = min(room, b.limit+utf8.UTFMax-b.buf.Len())
+	}
+	if room > 0 {
+		b.buf.Write(p[:room])
+	}
+	return len(p), nil
+}
+
+func (b *bodyCapture) String() string {
+	if b.limit < 0 || b.size <= b.limit {
+		return b.buf.String()
+	}
+	return cut(b.buf.String(), b.limit, b.size)
+}
@@ -0,0 +1,181 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// logRecords decodes the records logged by a slog.JSONHandler.
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var record map[string]any
+		if err := dec.Decode(&record); err != nil {
+			t.Fatalf("decoding log: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestWithLogger(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   string
+		status     int
+		wantLevel  string
+		wantErrors float64
+	}{
+		{name: "success", response: `{"data":{"name":"Ada"}}`, status: http.StatusOK, wantLevel: "INFO"},
+		{name: "graphql errors", response: `{"data":null,"errors":[{"message":"a"},{"message":"b"}]}`, status: http.StatusOK, wantLevel: "WARN", wantErrors: 2},
+		{name: "failure", response: `oops`, status: http.StatusBadGateway, wantLevel: "ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.response)
+			}))
+			defer srv.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+			client := NewClient(srv.URL, WithLogger(logger))
+			client.Run(ctx, NewRequest(`query GetUser { user { name } }`), nil)
+
+			records := logRecords(t, &buf)
+			if len(records) != 1 {
+				t.Fatalf("got %d records, want 1: %v", len(records), records)
+			}
+			r := records[0]
+			if r["level"] != tt.wantLevel || r["msg"] != "graphql response" {
+				t.Errorf("got %v %v, want %s graphql response", r["level"], r["msg"], tt.wantLevel)
+			}
+			if r["operation"] != "GetUser" || r["operation_type"] != "query" {
+				t.Errorf("operation got %v %v, want query GetUser", r["operation_type"], r["operation"])
+			}
+			if r["status"] != float64(tt.status) || r["errors"] != tt.wantErrors {
+				t.Errorf("got status %v and %v errors, want %d and %v", r["status"], r["errors"], tt.status, tt.wantErrors)
+			}
+			if r["response_size"] != float64(len(tt.response)) {
+				t.Errorf("response_size got %v, want %d", r["response_size"], len(tt.response))
+			}
+			if _, ok := r["duration"]; !ok {
+				t.Errorf("no duration in %v", r)
+			}
+		})
+	}
+}
+
+func TestWithLoggerDebug(t *testing.T) {
+	srv := responseServer(t, `{"data":{"user":{"name":"`+strings.Repeat("é", 20)+`"}}}`)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewClient(srv.URL,
+		WithLogger(logger),
+		WithRedactedHeaders("X-Session"),
+		WithRedactedVariables("input.password", "input.users.token"),
+		WithLogBodyLimit(-1),
+	)
+	req := NewRequest(`mutation Login($input: LoginInput!) { login(input: $input) }`)
+	req.Var("input", map[string]any{
+		"name":     "ada",
+		"password": "secret",
+		"users":    []map[string]any{{"token": "t1", "id": 1}, {"token": "t2", "id": 2}},
+	})
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Session", "secret")
+	req.Header.Set("X-Trace", "visible")
+	if err := client.Run(ctx, req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records := logRecords(t, &buf)
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3: %v", len(records), records)
+	}
+	request, body := records[0], records[1]
+	if got, want := request["variables"], `{"input":{"name":"ada","password":"[REDACTED]","users":[{"id":1,"token":"[REDACTED]"},{"id":2,"token":"[REDACTED]"}]}}`; got != want {
+		t.Errorf("variables got %v, want %s", got, want)
+	}
+	headers, _ := request["headers"].(map[string]any)
+	if headers["Authorization"] != redacted || headers["X-Session"] != redacted || headers["X-Trace"] != "visible" {
+		t.Errorf("headers got %v, want Authorization and X-Session redacted", headers)
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("log contains a secret")
+	}
+	if got, want := body["body"], `{"data":{"user":{"name":"`+strings.Repeat("é", 20)+`"}}}`; got != want {
+		t.Errorf("body got %v, want %s", got, want)
+	}
+}
+
+func TestWithLogBodyLimit(t *testing.T) {
+	srv := responseServer(t, `{"data":{"name":"`+strings.Repeat("é", 20)+`"}}`)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewClient(srv.URL, WithLogger(logger), WithLogBodyLimit(20))
+	if err := client.Run(ctx, NewRequest(`query { name }`), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	records := logRecords(t, &buf)
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3: %v", len(records), records)
+	}
+	// The limit falls inside the second é.
+	if got, want := records[1]["body"], `{"data":{"name":"é... (41 bytes truncated)`; got != want {
+		t.Errorf("body got %v, want %s", got, want)
+	}
+}
+
+func TestWithLoggerInfoLevel(t *testing.T) {
+	srv := responseServer(t, `{"data":{}}`)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var buf bytes.Buffer
+	client := NewClient(srv.URL, WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	req := NewRequest(`query { a }`)
+	req.Var("password", "secret")
+	if err := client.Run(ctx, req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if records := logRecords(t, &buf); len(records) != 1 {
+		t.Errorf("got %d records, want only the summary: %v", len(records), records)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{s: "hello", n: 10, want: "hello"},
+		{s: "hello", n: -1, want: "hello"},
+		{s: "hello world", n: 5, want: "hello... (6 bytes truncated)"},
+		{s: "héllo", n: 2, want: "h... (5 bytes truncated)"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) got %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
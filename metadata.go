@@ -0,0 +1,75 @@
+package graphql
+
+import (
+	"context"
+	"maps"
+	"net/http"
+)
+
+// Metadata is sent with a request without being part of it: headers,
+// and the extensions of the request body, such as a tenant ID, request
+// ID or locale.
+type Metadata struct {
+	Header     http.Header
+	Extensions map[string]any
+}
+
+type metadataKey struct{}
+
+// ContextWithMetadata returns a copy of ctx carrying md, which is sent
+// with the requests made with the context. Metadata in ctx already is
+// kept, unless md replaces its headers or extensions.
+func ContextWithMetadata(ctx context.Context, md Metadata) context.Context {
+	merged := MetadataFromContext(ctx)
+	merged.merge(md)
+	return context.WithValue(ctx, metadataKey{}, merged)
+}
+
+// MetadataFromContext returns the metadata in ctx.
+func MetadataFromContext(ctx context.Context) Metadata {
+	md, _ := ctx.Value(metadataKey{}).(Metadata)
+	return Metadata{Header: md.Header.Clone(), Extensions: maps.Clone(md.Extensions)}
+}
+
+// WithMetadataFunc derives metadata from the context of every request
+// with f, for values the application keeps in the context itself:
+//
+//	WithMetadataFunc(func(ctx context.Context) Metadata {
+//	    return Metadata{
+//	        Header:     http.Header{"X-Request-Id": {requestID(ctx)}},
+//	        Extensions: map[string]any{"locale": locale(ctx)},
+//	    }
+//	})
+//
+// Its metadata overrides that of ContextWithMetadata and earlier
+// funcs. Headers are set like those of WithHeaderFunc.
+func WithMetadataFunc(f func(ctx context.Context) Metadata) ClientOption {
+	return func(client *Client) {
+		client.metadataFuncs = append(client.metadataFuncs, f)
+	}
+}
+
+// metadata returns the metadata of a request made with ctx.
+func (c *Client) metadata(ctx context.Context) Metadata {
+	md := MetadataFromContext(ctx)
+	for _, f := range c.metadataFuncs {
+		md.merge(f(ctx))
+	}
+	return md
+}
+
+// merge sets the headers and extensions of other in md.
+func (md *Metadata) merge(other Metadata) {
+	if len(other.Header) > 0 {
+		if md.Header == nil {
+			md.Header = make(http.Header)
+		}
+		replaceHeaders(md.Header, other.Header)
+	}
+	if len(other.Extensions) > 0 {
+		if md.Extensions == nil {
+			md.Extensions = make(map[string]any)
+		}
+		maps.Copy(md.Extensions, other.Extensions)
+	}
+}
@@ -0,0 +1,111 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type localeKey struct{}
+
+func TestMetadata(t *testing.T) {
+	var header http.Header
+	var body struct {
+		Query      string
+		Extensions map[string]any
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body.Extensions = nil
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithMetadataFunc(func(ctx context.Context) Metadata {
+		locale, ok := ctx.Value(localeKey{}).(string)
+		if !ok {
+			return Metadata{}
+		}
+		return Metadata{
+			Header:     http.Header{"Accept-Language": {locale}},
+			Extensions: map[string]any{"locale": locale},
+		}
+	}))
+
+	if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body.Extensions != nil {
+		t.Errorf("extensions got %v, want none", body.Extensions)
+	}
+
+	ctx = ContextWithMetadata(ctx, Metadata{
+		Header:     http.Header{"X-Tenant-Id": {"acme"}},
+		Extensions: map[string]any{"tenant": "acme"},
+	})
+	ctx = ContextWithMetadata(ctx, Metadata{
+		Header:     http.Header{"X-Request-Id": {"r1"}},
+		Extensions: map[string]any{"requestId": "r1"},
+	})
+	ctx = context.WithValue(ctx, localeKey{}, "fr")
+	req := NewRequest("query {}")
+	req.Header.Set("X-Request-Id", "mine")
+	if err := client.Run(ctx, req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]any{"tenant": "acme", "requestId": "r1", "locale": "fr"}
+	if !reflect.DeepEqual(body.Extensions, want) {
+		t.Errorf("extensions got %v, want %v", body.Extensions, want)
+	}
+	for key, want := range map[string]string{"X-Tenant-Id": "acme", "X-Request-Id": "mine", "Accept-Language": "fr"} {
+		if got := header.Get(key); got != want {
+			t.Errorf("%s got %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestMetadataMultipart(t *testing.T) {
+	var extensions string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		extensions = r.FormValue("extensions")
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, UseMultipartForm())
+	ctx = ContextWithMetadata(ctx, Metadata{Extensions: map[string]any{"tenant": "acme"}})
+	if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if extensions != `{"tenant":"acme"}` {
+		t.Errorf("extensions got %q, want %q", extensions, `{"tenant":"acme"}`)
+	}
+}
+
+func TestContextWithMetadataCopies(t *testing.T) {
+	parent := ContextWithMetadata(context.Background(), Metadata{Extensions: map[string]any{"a": 1}})
+	child := ContextWithMetadata(parent, Metadata{Extensions: map[string]any{"a": 2, "b": 3}})
+	if got := MetadataFromContext(parent).Extensions; !reflect.DeepEqual(got, map[string]any{"a": 1}) {
+		t.Errorf("parent got %v, want it unchanged", got)
+	}
+	if got := MetadataFromContext(child).Extensions; !reflect.DeepEqual(got, map[string]any{"a": 2, "b": 3}) {
+		t.Errorf("child got %v", got)
+	}
+	md := MetadataFromContext(child)
+	md.Extensions["c"] = 4
+	if _, ok := MetadataFromContext(child).Extensions["c"]; ok {
+		t.Errorf("changing the returned metadata changed the context")
+	}
+}
@@ -0,0 +1,392 @@
+package graphql
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics receives measurements of the requests and subscriptions of a
+// Client. Its methods may be called concurrently.
+type Metrics interface {
+	// ObserveRequest is called when a request completes, after its last
+	// HTTP round trip.
+	ObserveRequest(m RequestMetrics)
+	// ObserveSubscription is called when a subscription starts and when
+	// it ends.
+	ObserveSubscription(e SubscriptionEvent)
+}
+
+// RequestMetrics are the measurements of one request.
+type RequestMetrics struct {
+	Operation     string
+	OperationType string
+	Duration      time.Duration
+	// StatusCode is the HTTP status, or 0 if no response was received.
+	StatusCode int
+	// ErrorCodes has the extensions.code of each GraphQL error in the
+	// response, or "" for errors without one.
+	ErrorCodes []string
+	// Err is the error returned for the request, if any.
+	Err          error
+	RequestSize  int64
+	ResponseSize int64
+	// Retries is the number of times the request was sent again.
+	Retries int
+	// Timing breaks Duration down.
+	Timing Timing
+}
+
+// SubscriptionState is the state a subscription entered.
+type SubscriptionState int
+
+// The states of a subscription.
+const (
+	SubscriptionStarted SubscriptionState = iota
+	SubscriptionEnded
+)
+
+// SubscriptionEvent is a change in the state of a subscription.
+type SubscriptionEvent struct {
+	Operation string
+	State     SubscriptionState
+	// Duration is how long the subscription was active, and Messages
+	// how many data messages it received, when it ends.
+	Duration time.Duration
+	Messages int
+	// Err is the error that ended the subscription, if any.
+	Err error
+}
+
+// WithMetrics reports measurements of every request to metrics.
+func WithMetrics(metrics Metrics) ClientOption {
+	return func(client *Client) {
+		client.metrics = metrics
+	}
+}
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the latency
+// histogram buckets used by NewMemoryMetrics by default.
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// MemoryMetrics is a Metrics that aggregates measurements in memory per
+// operation. It can be served to Prometheus:
+//
+//	metrics := graphql.NewMemoryMetrics(nil)
+//	client := graphql.NewClient(endpoint, graphql.WithMetrics(metrics))
+//	http.Handle("/metrics", metrics)
+type MemoryMetrics struct {
+	buckets []float64
+
+	mu            sync.Mutex
+	operations    map[OperationKey]*OperationStats
+	subscriptions map[string]*SubscriptionStats
+}
+
+// OperationKey identifies an operation.
+type OperationKey struct {
+	Type string
+	Name string
+}
+
+// OperationStats are the aggregated measurements of an operation.
+type OperationStats struct {
+	Requests int64
+	// Failures counts requests that returned an error other than a
+	// GraphQL error.
+	Failures      int64
+	StatusCodes   map[int]int64
+	ErrorCodes    map[string]int64
+	RequestBytes  int64
+	ResponseBytes int64
+	Retries       int64
+	Latency       Histogram
+}
+
+// SubscriptionStats are the aggregated measurements of the
+// subscriptions of an operation.
+type SubscriptionStats struct {
+	Active   int64
+	Started  int64
+	Failures int64
+	Messages int64
+}
+
+// Histogram counts observations in buckets.
+type Histogram struct {
+	// Buckets are the upper bounds of the buckets, and Counts the number
+	// of observations in each, excluding those in earlier buckets.
+	// Observations above the last bound are only in Count.
+	Buckets []float64
+	Counts  []int64
+	Count   int64
+	Sum     float64
+}
+
+func (h *Histogram) observe(v float64) {
+	h.Count++
+	h.Sum += v
+	for i, bound := range h.Buckets {
+		if v <= bound {
+			h.Counts[i]++
+			return
+		}
+	}
+}
+
+// MetricsSnapshot is a copy of the measurements in a MemoryMetrics.
+type MetricsSnapshot struct {
+	Operations    map[OperationKey]OperationStats
+	Subscriptions map[string]SubscriptionStats
+}
+
+// NewMemoryMetrics makes a MemoryMetrics with latency histogram buckets
+// with the upper bounds buckets, in seconds, or DefaultLatencyBuckets if
+// buckets is nil.
+func NewMemoryMetrics(buckets []float64) *MemoryMetrics {
+	if buckets == nil {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &MemoryMetrics{
+		buckets:       buckets,
+		operations:    make(map[OperationKey]*OperationStats),
+		subscriptions: make(map[string]*SubscriptionStats),
+	}
+}
+
+// ObserveRequest adds the measurements of a request.
+func (m *MemoryMetrics) ObserveRequest(r RequestMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := OperationKey{Type: r.OperationType, Name: r.Operation}
+	s := m.operations[key]
+	if s == nil {
+		s = &OperationStats{
+			StatusCodes: make(map[int]int64),
+			ErrorCodes:  make(map[string]int64),
+			Latency:     Histogram{Buckets: m.buckets, Counts: make([]int64, len(m.buckets))},
+		}
+		m.operations[key] = s
+	}
+	s.Requests++
+	if r.Err != nil && len(r.ErrorCodes) == 0 {
+		s.Failures++
+	}
+	if r.StatusCode != 0 {
+		s.StatusCodes[r.StatusCode]++
+	}
+	for _, code := range r.ErrorCodes {
+		s.ErrorCodes[code]++
+	}
+	s.RequestBytes += r.RequestSize
+	s.ResponseBytes += r.ResponseSize
+	s.Retries += int64(r.Retries)
+	s.Latency.observe(r.Duration.Seconds())
+}
+
+// ObserveSubscription adds a change in the state of a subscription.
+func (m *MemoryMetrics) ObserveSubscription(e SubscriptionEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.subscriptions[e.Operation]
+	if s == nil {
+		s = &SubscriptionStats{}
+		m.subscriptions[e.Operation] = s
+	}
+	switch e.State {
+	case SubscriptionStarted:
+		s.Active++
+		s.Started++
+	case SubscriptionEnded:
+		s.Active--
+		s.Messages += int64(e.Messages)
+		if e.Err != nil {
+			s.Failures++
+		}
+	}
+}
+
+// Snapshot returns a copy of the measurements so far.
+func (m *MemoryMetrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snap := MetricsSnapshot{
+		Operations:    make(map[OperationKey]OperationStats, len(m.operations)),
+		Subscriptions: make(map[string]SubscriptionStats, len(m.subscriptions)),
+	}
+	for key, s := range m.operations {
+		c := *s
+		c.StatusCodes = make(map[int]int64, len(s.StatusCodes))
+		for k, v := range s.StatusCodes {
+			c.StatusCodes[k] = v
+		}
+		c.ErrorCodes = make(map[string]int64, len(s.ErrorCodes))
+		for k, v := range s.ErrorCodes {
+			c.ErrorCodes[k] = v
+		}
+		c.Latency.Counts = append([]int64(nil), s.Latency.Counts...)
+		snap.Operations[key] = c
+	}
+	for key, s := range m.subscriptions {
+		snap.Subscriptions[key] = *s
+	}
+	return snap
+}
+
+// ServeHTTP serves the measurements in the Prometheus text format.
+func (m *MemoryMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WritePrometheus(w)
+}
+
+// WritePrometheus writes the measurements in the Prometheus text
+// exposition format.
+func (m *MemoryMetrics) WritePrometheus(w io.Writer) error {
+	snap := m.Snapshot()
+	keys := make([]OperationKey, 0, len(snap.Operations))
+	for key := range snap.Operations {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Type != keys[j].Type {
+			return keys[i].Type < keys[j].Type
+		}
+		return keys[i].Name < keys[j].Name
+	})
+	subs := make([]string, 0, len(snap.Subscriptions))
+	for name := range snap.Subscriptions {
+		subs = append(subs, name)
+	}
+	sort.Strings(subs)
+
+	bw := bufio.NewWriter(w)
+	p := &promWriter{w: bw}
+	p.header("graphql_client_requests_total", "counter", "GraphQL requests by HTTP status code.")
+	for _, key := range keys {
+		s := snap.Operations[key]
+		statuses := make([]int, 0, len(s.StatusCodes))
+		for status := range s.StatusCodes {
+			statuses = append(statuses, status)
+		}
+		sort.Ints(statuses)
+		for _, status := range statuses {
+			p.sample("graphql_client_requests_total", s.StatusCodes[status], opLabels(key, "status_code", strconv.Itoa(status))...)
+		}
+		if n := s.Requests - sumValues(s.StatusCodes); n > 0 {
+			p.sample("graphql_client_requests_total", n, opLabels(key, "status_code", "")...)
+		}
+	}
+	p.header("graphql_client_request_failures_total", "counter", "GraphQL requests that failed without a GraphQL error.")
+	for _, key := range keys {
+		p.sample("graphql_client_request_failures_total", snap.Operations[key].Failures, opLabels(key)...)
+	}
+	p.header("graphql_client_errors_total", "counter", "GraphQL errors in responses by extensions.code.")
+	for _, key := range keys {
+		s := snap.Operations[key]
+		codes := make([]string, 0, len(s.ErrorCodes))
+		for code := range s.ErrorCodes {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			p.sample("graphql_client_errors_total", s.ErrorCodes[code], opLabels(key, "code", code)...)
+		}
+	}
+	p.header("graphql_client_request_bytes_total", "counter", "Bytes of request bodies sent.")
+	for _, key := range keys {
+		p.sample("graphql_client_request_bytes_total", snap.Operations[key].RequestBytes, opLabels(key)...)
+	}
+	p.header("graphql_client_response_bytes_total", "counter", "Bytes of response bodies received.")
+	for _, key := range keys {
+		p.sample("graphql_client_response_bytes_total", snap.Operations[key].ResponseBytes, opLabels(key)...)
+	}
+	p.header("graphql_client_retries_total", "counter", "Requests sent again.")
+	for _, key := range keys {
+		p.sample("graphql_client_retries_total", snap.Operations[key].Retries, opLabels(key)...)
+	}
+	p.header("graphql_client_request_duration_seconds", "histogram", "Duration of GraphQL requests.")
+	for _, key := range keys {
+		h := snap.Operations[key].Latency
+		var cumulative int64
+		for i, bound := range h.Buckets {
+			cumulative += h.Counts[i]
+			p.sample("graphql_client_request_duration_seconds_bucket", cumulative, opLabels(key, "le", formatFloat(bound))...)
+		}
+		p.sample("graphql_client_request_duration_seconds_bucket", h.Count, opLabels(key, "le", "+Inf")...)
+		p.sampleFloat("graphql_client_request_duration_seconds_sum", h.Sum, opLabels(key)...)
+		p.sample("graphql_client_request_duration_seconds_count", h.Count, opLabels(key)...)
+	}
+	if len(subs) > 0 {
+		p.header("graphql_client_subscriptions_active", "gauge", "Active subscriptions.")
+		for _, name := range subs {
+			p.sample("graphql_client_subscriptions_active", snap.Subscriptions[name].Active, "operation", name)
+		}
+		p.header("graphql_client_subscriptions_total", "counter", "Subscriptions started.")
+		for _, name := range subs {
+			p.sample("graphql_client_subscriptions_total", snap.Subscriptions[name].Started, "operation", name)
+		}
+		p.header("graphql_client_subscription_failures_total", "counter", "Subscriptions ended by an error.")
+		for _, name := range subs {
+			p.sample("graphql_client_subscription_failures_total", snap.Subscriptions[name].Failures, "operation", name)
+		}
+		p.header("graphql_client_subscription_messages_total", "counter", "Data messages received by subscriptions.")
+		for _, name := range subs {
+			p.sample("graphql_client_subscription_messages_total", snap.Subscriptions[name].Messages, "operation", name)
+		}
+	}
+	return bw.Flush()
+}
+
+func opLabels(key OperationKey, extra ...string) []string {
+	return append([]string{"operation", key.Name, "type", key.Type}, extra...)
+}
+
+func sumValues[K comparable](m map[K]int64) int64 {
+	var n int64
+	for _, v := range m {
+		n += v
+	}
+	return n
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// promWriter writes the Prometheus text exposition format.
+type promWriter struct {
+	w *bufio.Writer
+}
+
+func (p *promWriter) header(name, typ, help string) {
+	fmt.Fprintf(p.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func (p *promWriter) sample(name string, v int64, labels ...string) {
+	p.sampleFloat(name, float64(v), labels...)
+}
+
+func (p *promWriter) sampleFloat(name string, v float64, labels ...string) {
+	p.w.WriteString(name)
+	if len(labels) > 0 {
+		p.w.WriteByte('{')
+		for i := 0; i < len(labels); i += 2 {
+			if i > 0 {
+				p.w.WriteByte(',')
+			}
+			fmt.Fprintf(p.w, "%s=\"%s\"", labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		p.w.WriteByte('}')
+	}
+	fmt.Fprintf(p.w, " %s\n", formatFloat(v))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
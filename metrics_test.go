@@ -0,0 +1,134 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithMetrics(t *testing.T) {
+	response := `{"data":{}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, response)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	metrics := NewMemoryMetrics(nil)
+	client := NewClient(srv.URL, WithMetrics(metrics))
+	q := `query GetUser { user { name } }`
+	if err := client.Run(ctx, NewRequest(q), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response = `{"data":null,"errors":[{"message":"a","extensions":{"code":"UNAUTHENTICATED"}},{"message":"b"}]}`
+	if err := client.Run(ctx, NewRequest(q), nil); err == nil {
+		t.Fatalf("want an error")
+	}
+
+	snap := metrics.Snapshot()
+	s, ok := snap.Operations[OperationKey{Type: "query", Name: "GetUser"}]
+	if !ok {
+		t.Fatalf("no stats for GetUser in %v", snap.Operations)
+	}
+	if s.Requests != 2 || s.Failures != 0 || s.StatusCodes[200] != 2 {
+		t.Errorf("got %d requests, %d failures and status codes %v", s.Requests, s.Failures, s.StatusCodes)
+	}
+	if s.ErrorCodes["UNAUTHENTICATED"] != 1 || s.ErrorCodes[""] != 1 {
+		t.Errorf("error codes got %v", s.ErrorCodes)
+	}
+	if s.RequestBytes == 0 || s.ResponseBytes != int64(len(`{"data":{}}`)+len(response)) {
+		t.Errorf("got %d bytes out and %d in", s.RequestBytes, s.ResponseBytes)
+	}
+	if s.Latency.Count != 2 {
+		t.Errorf("latency count got %d, want 2", s.Latency.Count)
+	}
+}
+
+func TestMemoryMetricsFailures(t *testing.T) {
+	metrics := NewMemoryMetrics([]float64{1})
+	metrics.ObserveRequest(RequestMetrics{OperationType: "mutation", Err: errors.New("connection refused"), Duration: 2 * time.Second})
+	s := metrics.Snapshot().Operations[OperationKey{Type: "mutation"}]
+	if s.Failures != 1 || len(s.StatusCodes) != 0 {
+		t.Errorf("got %d failures and status codes %v, want 1 and none", s.Failures, s.StatusCodes)
+	}
+	if s.Latency.Counts[0] != 0 || s.Latency.Count != 1 {
+		t.Errorf("got %v in buckets and count %d, want only the count", s.Latency.Counts, s.Latency.Count)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	metrics := NewMemoryMetrics([]float64{0.1, 1})
+	metrics.ObserveRequest(RequestMetrics{
+		Operation:     `Get"User`,
+		OperationType: "query",
+		Duration:      50 * time.Millisecond,
+		StatusCode:    200,
+		ErrorCodes:    []string{"NOT_FOUND"},
+		RequestSize:   10,
+		ResponseSize:  20,
+		Retries:       1,
+	})
+	metrics.ObserveRequest(RequestMetrics{Operation: `Get"User`, OperationType: "query", Duration: 500 * time.Millisecond, StatusCode: 200})
+	metrics.ObserveSubscription(SubscriptionEvent{Operation: "OnEvent", State: SubscriptionStarted})
+
+	var b strings.Builder
+	if err := metrics.WritePrometheus(&b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `# HELP graphql_client_requests_total GraphQL requests by HTTP status code.
+# TYPE graphql_client_requests_total counter
+graphql_client_requests_total{operation="Get\"User",type="query",status_code="200"} 2
+# HELP graphql_client_request_failures_total GraphQL requests that failed without a GraphQL error.
+# TYPE graphql_client_request_failures_total counter
+graphql_client_request_failures_total{operation="Get\"User",type="query"} 0
+# HELP graphql_client_errors_total GraphQL errors in responses by extensions.code.
+# TYPE graphql_client_errors_total counter
+graphql_client_errors_total{operation="Get\"User",type="query",code="NOT_FOUND"} 1
+# HELP graphql_client_request_bytes_total Bytes of request bodies sent.
+# TYPE graphql_client_request_bytes_total counter
+graphql_client_request_bytes_total{operation="Get\"User",type="query"} 10
+# HELP graphql_client_response_bytes_total Bytes of response bodies received.
+# TYPE graphql_client_response_bytes_total counter
+graphql_client_response_bytes_total{operation="Get\"User",type="query"} 20
+# HELP graphql_client_retries_total Requests sent again.
+# TYPE graphql_client_retries_total counter
+graphql_client_retries_total{operation="Get\"User",type="query"} 1
+# HELP graphql_client_request_duration_seconds Duration of GraphQL requests.
+# TYPE graphql_client_request_duration_seconds histogram
+graphql_client_request_duration_seconds_bucket{operation="Get\"User",type="query",le="0.1"} 1
+graphql_client_request_duration_seconds_bucket{operation="Get\"User",type="query",le="1"} 2
+graphql_client_request_duration_seconds_bucket{operation="Get\"User",type="query",le="+Inf"} 2
+graphql_client_request_duration_seconds_sum{operation="Get\"User",type="query"} 0.55
+graphql_client_request_duration_seconds_count{operation="Get\"User",type="query"} 2
+# HELP graphql_client_subscriptions_active Active subscriptions.
+# TYPE graphql_client_subscriptions_active gauge
+graphql_client_subscriptions_active{operation="OnEvent"} 1
+# HELP graphql_client_subscriptions_total Subscriptions started.
+# TYPE graphql_client_subscriptions_total counter
+graphql_client_subscriptions_total{operation="OnEvent"} 1
+# HELP graphql_client_subscription_failures_total Subscriptions ended by an error.
+# TYPE graphql_client_subscription_failures_total counter
+graphql_client_subscription_failures_total{operation="OnEvent"} 0
+# HELP graphql_client_subscription_messages_total Data messages received by subscriptions.
+# TYPE graphql_client_subscription_messages_total counter
+graphql_client_subscription_messages_total{operation="OnEvent"} 0
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type got %q", ct)
+	}
+	if rec.Body.String() != want {
+		t.Errorf("ServeHTTP body differs from WritePrometheus")
+	}
+}
@@ -0,0 +1,172 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// EndpointResolver returns the URL to send a request to. A relative URL
+// is resolved against the endpoint given to NewClient.
+type EndpointResolver func(ctx context.Context, req *Request) (string, error)
+
+// WithEndpointResolver sends every request to the URL returned by
+// resolve, such as the endpoint of the tenant in ctx. It overrides
+// WithEndpoints. Requests share the HTTP client and all other options
+// whatever their endpoint.
+func WithEndpointResolver(resolve EndpointResolver) ClientOption {
+	return func(client *Client) {
+		client.resolver = resolve
+	}
+}
+
+// EndpointParams are the values of an endpoint template.
+type EndpointParams struct {
+	// Path has the values of the {name} placeholders of the template.
+	// Values may not contain "/", "@", ":", "#", "?" or "..", so that
+	// they cannot change the host or leave their path segment, and are
+	// escaped for where they sit. In the host, only letters, digits, "-"
+	// and "." are allowed.
+	Path map[string]string
+	// Query is added to the query of the URL.
+	Query url.Values
+}
+
+// EndpointTemplate returns a resolver that expands the {name}
+// placeholders of tmpl, in its host, path or query, with the values
+// returned by params:
+//
+//	WithEndpointResolver(EndpointTemplate("https://{tenant}.example.com/graphql",
+//	    func(ctx context.Context, req *Request) (EndpointParams, error) {
+//	        return EndpointParams{Path: map[string]string{"tenant": tenantFrom(ctx)}}, nil
+//	    }))
+func EndpointTemplate(tmpl string, params func(ctx context.Context, req *Request) (EndpointParams, error)) EndpointResolver {
+	return func(ctx context.Context, req *Request) (string, error) {
+		p, err := params(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		return expandEndpoint(tmpl, p)
+	}
+}
+
+// The parts of an endpoint template a placeholder can be in.
+const (
+	inHost = iota
+	inPath
+	inQuery
+)
+
+// expandEndpoint expands the placeholders of tmpl and adds the query
+// parameters of p.
+func expandEndpoint(tmpl string, p EndpointParams) (string, error) {
+	hostStart, hostEnd := -1, -1
+	if i := strings.Index(tmpl, "://"); i >= 0 {
+		hostStart = i + len("://")
+	} else if strings.HasPrefix(tmpl, "//") {
+		hostStart = len("//")
+	}
+	if hostStart >= 0 {
+		hostEnd = len(tmpl)
+		if i := strings.IndexAny(tmpl[hostStart:], "/?#"); i >= 0 {
+			hostEnd = hostStart + i
+		}
+	}
+	queryStart := strings.IndexAny(tmpl, "?#")
+
+	var b strings.Builder
+	pos := 0
+	for {
+		start := strings.IndexByte(tmpl[pos:], '{')
+		if start < 0 {
+			b.WriteString(tmpl[pos:])
+			break
+		}
+		start += pos
+		end := strings.IndexByte(tmpl[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("graphql: unclosed placeholder in endpoint template %q", tmpl)
+		}
+		end += start
+		name := tmpl[start+1 : end]
+		part := inPath
+		switch {
+		case start < hostStart:
+			return "", fmt.Errorf("graphql: {%s} is not in the host, path or query of endpoint template %q", name, tmpl)
+		case start < hostEnd:
+			part = inHost
+		case queryStart >= 0 && start > queryStart:
+			part = inQuery
+		}
+		value, err := placeholderValue(p.Path, name, part)
+		if err != nil {
+			return "", fmt.Errorf("graphql: endpoint template %q: %w", tmpl, err)
+		}
+		b.WriteString(tmpl[pos:start])
+		b.WriteString(value)
+		pos = end + 1
+	}
+	if len(p.Query) == 0 {
+		return b.String(), nil
+	}
+	u, err := url.Parse(b.String())
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for key, values := range p.Query {
+		q[key] = append(q[key], values...)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// placeholderValue returns the value of the placeholder name, escaped
+// for the part of the URL it is in.
+func placeholderValue(values map[string]string, name string, part int) (string, error) {
+	value, ok := values[name]
+	switch {
+	case !ok:
+		return "", fmt.Errorf("no value for {%s}", name)
+	case value == "":
+		return "", fmt.Errorf("empty value for {%s}", name)
+	case strings.ContainsAny(value, "/@:#?") || strings.Contains(value, ".."):
+		return "", fmt.Errorf("invalid value %q for {%s}", value, name)
+	}
+	switch part {
+	case inHost:
+		for _, r := range value {
+			if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '-' || r == '.') {
+				return "", fmt.Errorf("invalid host value %q for {%s}", value, name)
+			}
+		}
+		return value, nil
+	case inQuery:
+		return url.QueryEscape(value), nil
+	}
+	if value == "." {
+		return "", fmt.Errorf("invalid value %q for {%s}", value, name)
+	}
+	return url.PathEscape(value), nil
+}
+
+// resolveEndpoint returns the endpoint of req from the resolver.
+func (c *Client) resolveEndpoint(ctx context.Context, req *Request) (string, error) {
+	endpoint, err := c.resolver(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("graphql: resolving endpoint: %w", err)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("graphql: resolving endpoint: %w", err)
+	}
+	if !u.IsAbs() && c.endpoint != "" {
+		base, err := url.Parse(c.endpoint)
+		if err != nil {
+			return "", err
+		}
+		u = base.ResolveReference(u)
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,162 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEndpointResolver(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL+"/api/", WithEndpointResolver(EndpointTemplate("tenants/{tenant}/graphql",
+		func(ctx context.Context, req *Request) (EndpointParams, error) {
+			tenant, ok := ctx.Value(tenantKey{}).(string)
+			if !ok {
+				return EndpointParams{}, errors.New("no tenant")
+			}
+			return EndpointParams{
+				Path:  map[string]string{"tenant": tenant},
+				Query: url.Values{"region": {"eu"}},
+			}, nil
+		})))
+
+	for _, tenant := range []string{"acme", "a b"} {
+		ctx := context.WithValue(ctx, tenantKey{}, tenant)
+		if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	want := []string{"/api/tenants/acme/graphql?region=eu", "/api/tenants/a%20b/graphql?region=eu"}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Errorf("paths got %v, want %v", paths, want)
+	}
+
+	err := client.Run(ctx, NewRequest("query {}"), nil)
+	if err == nil || !strings.Contains(err.Error(), "no tenant") {
+		t.Errorf("got %v, want the resolver error", err)
+	}
+	if len(paths) != 2 {
+		t.Errorf("got %d requests, want none sent without an endpoint", len(paths)-2)
+	}
+}
+
+func TestEndpointResolverOverridesEndpoints(t *testing.T) {
+	resolved := newCountingServer(t, http.StatusOK, 0)
+	listed := newCountingServer(t, http.StatusOK, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient("",
+		WithEndpoints(RoundRobin, Endpoint{URL: listed.URL}),
+		WithEndpointResolver(func(ctx context.Context, req *Request) (string, error) {
+			return resolved.URL, nil
+		}),
+	)
+	if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.requests.Load() != 1 || listed.requests.Load() != 0 {
+		t.Errorf("got %d and %d requests, want the resolved endpoint only", resolved.requests.Load(), listed.requests.Load())
+	}
+	if client.Endpoints() != nil {
+		t.Errorf("got endpoint statuses, want none")
+	}
+}
+
+func TestExpandEndpoint(t *testing.T) {
+	tests := []struct {
+		tmpl    string
+		params  EndpointParams
+		want    string
+		wantErr bool
+	}{
+		{
+			tmpl:   "https://{tenant}.example.com/graphql",
+			params: EndpointParams{Path: map[string]string{"tenant": "acme"}},
+			want:   "https://acme.example.com/graphql",
+		},
+		{
+			tmpl: "https://example.com/{tenant}/{version}?key=1",
+			params: EndpointParams{
+				Path:  map[string]string{"tenant": "a b", "version": "v2"},
+				Query: url.Values{"key": {"2"}, "debug": {"true"}},
+			},
+			want: "https://example.com/a%20b/v2?debug=true&key=1&key=2",
+		},
+		{
+			tmpl:   "https://example.com/graphql?tenant={tenant}",
+			params: EndpointParams{Path: map[string]string{"tenant": "a&b=c"}},
+			want:   "https://example.com/graphql?tenant=a%26b%3Dc",
+		},
+		{
+			tmpl:   "//{region}.example.com/graphql",
+			params: EndpointParams{Path: map[string]string{"region": "eu-west-1"}},
+			want:   "//eu-west-1.example.com/graphql",
+		},
+		{tmpl: "https://example.com/{tenant}", wantErr: true},
+		{tmpl: "{scheme}://example.com", params: EndpointParams{Path: map[string]string{"scheme": "https"}}, wantErr: true},
+		{tmpl: "https://example.com/{tenant}", params: EndpointParams{Path: map[string]string{"tenant": ""}}, wantErr: true},
+		{tmpl: "https://example.com/{tenant", params: EndpointParams{Path: map[string]string{"tenant": "acme"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := expandEndpoint(tt.tmpl, tt.params)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: got %q, want an error", tt.tmpl, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.tmpl, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
+
+func TestExpandEndpointInjection(t *testing.T) {
+	const (
+		host = "https://{tenant}.example.com/graphql"
+		path = "https://example.com/{tenant}/graphql"
+	)
+	// Values that would change the host or leave their path segment.
+	tests := []struct {
+		tmpl, value string
+	}{
+		{host, "evil.com#"},
+		{host, "x@evil.com"},
+		{host, "evil.com:8080"},
+		{host, "evil.com/x"},
+		{host, "a..b"},
+		{host, "a b"},
+		{path, "evil.com#"},
+		{path, "x@evil.com"},
+		{path, "a:b"},
+		{path, "a/b"},
+		{path, "a?b"},
+		{path, ".."},
+		{path, "."},
+	}
+	for _, tt := range tests {
+		got, err := expandEndpoint(tt.tmpl, EndpointParams{Path: map[string]string{"tenant": tt.value}})
+		if err == nil {
+			t.Errorf("%s with %q: got %q, want an error", tt.tmpl, tt.value, got)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package graphql
+
+import (
+	"encoding/json"
+)
+
+// Response holds the data of a response undecoded, for callers that
+// decode it later or only in part. Pass a *Response to Run:
+//
+//	var resp graphql.Response
+//	if err := client.Run(ctx, req, &resp); err != nil {
+//	    return err
+//	}
+//	var user User
+//	err := resp.Decode(&user)
+type Response struct {
+	// Raw is the data field of the response, or nil if it was null.
+	Raw json.RawMessage
+	// Timing is the timing of the request.
+	Timing Timing
+
+	decode func(data []byte, v any) error
+}
+
+// UnmarshalJSON keeps a copy of data in Raw.
+func (r *Response) UnmarshalJSON(data []byte) error {
+	r.Raw = append(r.Raw[:0], data...)
+	return nil
+}
+
+// Decode decodes the data into v, a non-nil pointer, the way Run would
+// have: with the Client's decoder, number handling and scalar codecs.
+// Null data leaves v unchanged.
+func (r *Response) Decode(v any) error {
+	if len(r.Raw) == 0 || isNull(r.Raw) {
+		return nil
+	}
+	if r.decode == nil {
+		return json.Unmarshal(r.Raw, v)
+	}
+	return r.decode(r.Raw, v)
+}
+
+// UseNumber decodes numbers in the response into an any as json.Number
+// rather than float64, so that large integers and exact decimals keep
+// their precision.
+func UseNumber() ClientOption {
+	return func(client *Client) {
+		client.useNumber = true
+	}
+}
+
+// WithDecoder decodes the data of responses with decode rather than
+// encoding/json, such as to use a faster JSON package:
+//
+//	NewClient(endpoint, WithDecoder(jsoniter.Unmarshal))
+//
+// decode is given the data field of the response and the response
+// object passed to Run. It takes the place of the Codec for the data,
+// and UseNumber does not apply to it.
+func WithDecoder(decode func(data []byte, v any) error) ClientOption {
+	return func(client *Client) {
+		client.decoder = decode
+	}
+}
@@ -0,0 +1,152 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func responseServer(t *testing.T, response string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestUseNumber(t *testing.T) {
+	srv := responseServer(t, `{"data":{"id":9007199254740993,"price":0.1,"nested":{"n":12345678901234567890}}}`)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var resp map[string]any
+	client := NewClient(srv.URL, UseNumber())
+	if err := client.Run(ctx, NewRequest("query {}"), &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := resp["id"], json.Number("9007199254740993"); got != want {
+		t.Errorf("id got %#v, want %#v", got, want)
+	}
+	if got, want := resp["price"], json.Number("0.1"); got != want {
+		t.Errorf("price got %#v, want %#v", got, want)
+	}
+	nested, _ := resp["nested"].(map[string]any)
+	if got, want := nested["n"], json.Number("12345678901234567890"); got != want {
+		t.Errorf("nested.n got %#v, want %#v", got, want)
+	}
+
+	resp = nil
+	if err := NewClient(srv.URL).Run(ctx, NewRequest("query {}"), &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := resp["id"].(float64); !ok {
+		t.Errorf("id got %T, want float64 without UseNumber", resp["id"])
+	}
+}
+
+func TestUseNumberStructQuery(t *testing.T) {
+	srv := responseServer(t, `{"data":{"stats":{"total":9007199254740993}}}`)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var q struct {
+		Stats struct {
+			Total any
+		}
+	}
+	client := NewClient(srv.URL, UseNumber())
+	if err := client.Query(ctx, &q, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := q.Stats.Total, json.Number("9007199254740993"); got != want {
+		t.Errorf("total got %#v, want %#v", got, want)
+	}
+}
+
+func TestResponseRaw(t *testing.T) {
+	srv := responseServer(t, `{"data":{"user":{"name":"Ada","score":1.5}}}`)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var resp Response
+	client := NewClient(srv.URL, UseNumber())
+	if err := client.Run(ctx, NewRequest("query {}"), &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := string(resp.Raw), `{"user":{"name":"Ada","score":1.5}}`; got != want {
+		t.Errorf("Raw got %s, want %s", got, want)
+	}
+	var v struct {
+		User map[string]any
+	}
+	if err := resp.Decode(&v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := v.User["score"], json.Number("1.5"); got != want {
+		t.Errorf("score got %#v, want %#v", got, want)
+	}
+}
+
+func TestResponseNullData(t *testing.T) {
+	srv := responseServer(t, `{"data":null,"errors":[{"message":"boom"}]}`)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var resp Response
+	err := NewClient(srv.URL).Run(ctx, NewRequest("query {}"), &resp)
+	if err == nil || err.Error() != "graphql: boom" {
+		t.Fatalf("err got %v, want graphql: boom", err)
+	}
+	if resp.Raw != nil {
+		t.Errorf("Raw got %s, want nil", resp.Raw)
+	}
+	var v struct{ Name string }
+	if err := resp.Decode(&v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWithDecoder(t *testing.T) {
+	srv := responseServer(t, `{"data":{"name":"Ada"}}`)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var decoded []string
+	decode := func(data []byte, v any) error {
+		decoded = append(decoded, string(data))
+		return json.Unmarshal(data, v)
+	}
+	client := NewClient(srv.URL, WithDecoder(decode))
+	var resp struct{ Name string }
+	if err := client.Run(ctx, NewRequest("query {}"), &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Name != "Ada" {
+		t.Errorf("name got %q, want Ada", resp.Name)
+	}
+	var raw Response
+	if err := client.Run(ctx, NewRequest("query {}"), &raw); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(decoded) != 1 {
+		t.Fatalf("decoder called %d times before Decode, want 1", len(decoded))
+	}
+	var later struct{ Name string }
+	if err := raw.Decode(&later); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if later.Name != "Ada" || len(decoded) != 2 {
+		t.Errorf("Decode got %q after %d calls, want Ada after 2", later.Name, len(decoded))
+	}
+
+	boom := errors.New("boom")
+	client = NewClient(srv.URL, WithDecoder(func([]byte, any) error { return boom }))
+	if err := client.Run(ctx, NewRequest("query {}"), &resp); !errors.Is(err, boom) {
+		t.Errorf("err got %v, want %v", err, boom)
+	}
+}
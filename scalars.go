@@ -0,0 +1,600 @@
+package graphql
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/razzkumar/go-graphql/language"
+	"github.com/razzkumar/go-graphql/schema"
+)
+
+// ScalarCodec converts the values of a custom scalar, such as DateTime,
+// between Go and JSON.
+type ScalarCodec interface {
+	// EncodeScalar converts a variable value to a value encoding/json
+	// marshals to the scalar's JSON form.
+	EncodeScalar(v any) (any, error)
+	// DecodeScalar decodes the scalar's JSON form into dst, a non-nil
+	// pointer to the Go value in the response.
+	DecodeScalar(data []byte, dst any) error
+}
+
+// ScalarFuncs adapts a pair of functions to a ScalarCodec. A nil
+// function leaves values to encoding/json.
+type ScalarFuncs struct {
+	Encode func(v any) (any, error)
+	Decode func(data []byte, dst any) error
+}
+
+// EncodeScalar calls f.Encode.
+func (f ScalarFuncs) EncodeScalar(v any) (any, error) {
+	if f.Encode == nil {
+		return v, nil
+	}
+	return f.Encode(v)
+}
+
+// DecodeScalar calls f.Decode.
+func (f ScalarFuncs) DecodeScalar(data []byte, dst any) error {
+	if f.Decode == nil {
+		return json.Unmarshal(data, dst)
+	}
+	return f.Decode(data, dst)
+}
+
+// WithScalar registers codec for the custom scalar name.
+//
+// Variables declared with the scalar's type are encoded with the codec.
+// Response values are decoded with it when their type is known: from
+// the schema given to WithSchemaValidation, or from a scalar tag on the
+// struct field they are decoded into:
+//
+//	type User struct {
+//	    Name      string
+//	    CreatedAt time.Time `scalar:"DateTime"`
+//	}
+//
+// Input object fields are only encoded with the codec when the schema
+// is known.
+func WithScalar(name string, codec ScalarCodec) ClientOption {
+	return func(client *Client) {
+		if client.scalars == nil {
+			client.scalars = make(map[string]ScalarCodec)
+		}
+		client.scalars[name] = codec
+	}
+}
+
+// prepare applies the scalar codecs to the variables of req and sets up
+// the decoding of resp. It returns the request and response object to
+// use.
+func (c *Client) prepare(req *Request, resp any) (*Request, any, error) {
+	d := &decoder{
+		schema:    c.schema,
+		scalars:   c.scalars,
+		codec:     c.codec,
+		useNumber: c.useNumber,
+	}
+	var info typeInfo
+	if len(c.scalars) > 0 {
+		var err error
+		if req, info, err = c.encodeVars(req, d); err != nil {
+			return nil, nil, err
+		}
+	}
+	switch r := resp.(type) {
+	case *responseValue:
+		r.d, r.info = d, info
+	case *Response:
+		r.decode = func(data []byte, v any) error {
+			if c.decoder != nil {
+				return c.decoder(data, v)
+			}
+			pv := reflect.ValueOf(v)
+			if pv.Kind() != reflect.Pointer || pv.IsNil() {
+				return fmt.Errorf("graphql: decode into non-pointer %T", v)
+			}
+			return d.decode(data, pv.Elem(), info, "")
+		}
+	default:
+		if len(c.scalars) == 0 {
+			break
+		}
+		if pv := reflect.ValueOf(resp); pv.Kind() == reflect.Pointer && !pv.IsNil() {
+			resp = &responseValue{v: pv.Elem(), d: d, info: info}
+		}
+	}
+	return req, resp, nil
+}
+
+// encodeVars parses the query of req for the decoder d and returns the
+// type of the response data and a copy of req with its variables
+// encoded.
+func (c *Client) encodeVars(req *Request, d *decoder) (*Request, typeInfo, error) {
+	var info typeInfo
+	doc, err := language.ParseQuery(req.q)
+	if err != nil {
+		// Leave the error to the server.
+		return req, info, nil
+	}
+	d.fragments = make(map[string]*language.FragmentDefinition)
+	for _, frag := range doc.Fragments {
+		d.fragments[frag.Name] = frag
+	}
+	if len(doc.Operations) != 1 {
+		return req, info, nil
+	}
+	op := doc.Operations[0]
+	if c.schema != nil {
+		if root := c.schema.RootType(op.Operation); root != nil {
+			info = typeInfo{ref: language.NamedTypeRef(root.Name), set: op.SelectionSet}
+		}
+	}
+	if len(req.vars) == 0 {
+		return req, info, nil
+	}
+	vars := make(map[string]any, len(req.vars))
+	for name, value := range req.vars {
+		vars[name] = value
+	}
+	for _, def := range op.VariableDefinitions {
+		value, ok := vars[def.Variable]
+		if !ok {
+			continue
+		}
+		encoded, err := c.encodeValue(value, def.Type)
+		if err != nil {
+			return nil, info, fmt.Errorf("graphql: variable $%s: %w", def.Variable, err)
+		}
+		vars[def.Variable] = encoded
+	}
+	encoded := *req
+	encoded.vars = vars
+	return &encoded, info, nil
+}
+
+// encodeValue encodes the scalars in a variable value of type t.
+func (c *Client) encodeValue(v any, t *language.Type) (any, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	if t.Elem != nil {
+		if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Type().Elem().Kind() == reflect.Uint8 {
+			return c.encodeValue(v, t.Elem)
+		}
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		list := make([]any, rv.Len())
+		for i := range list {
+			item, err := c.encodeValue(rv.Index(i).Interface(), t.Elem)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			list[i] = item
+		}
+		return list, nil
+	}
+	if codec, ok := c.scalars[t.NamedType]; ok {
+		return codec.EncodeScalar(rv.Interface())
+	}
+	if c.schema == nil {
+		return v, nil
+	}
+	named := c.schema.Types[t.NamedType]
+	if named == nil || named.Kind != schema.InputObject {
+		return v, nil
+	}
+	fields := inputFields(rv)
+	if fields == nil {
+		return v, nil
+	}
+	obj := make(map[string]any, len(fields))
+	for name, value := range fields {
+		def := named.InputField(name)
+		if def == nil {
+			obj[name] = value
+			continue
+		}
+		encoded, err := c.encodeValue(value, def.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		obj[name] = encoded
+	}
+	return obj, nil
+}
+
+// inputFields gets the fields of a map or struct by their JSON names,
+// or nil for other values.
+func inputFields(rv reflect.Value) map[string]any {
+	switch {
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		fields := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			fields[iter.Key().String()] = iter.Value().Interface()
+		}
+		return fields
+	case rv.Kind() == reflect.Struct && !isLeaf(rv.Type()):
+		if _, ok := rv.Interface().(json.Marshaler); ok {
+			return nil
+		}
+		fields := make(map[string]any)
+		addStructFields(fields, rv)
+		return fields
+	}
+	return nil
+}
+
+func addStructFields(fields map[string]any, rv reflect.Value) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := rv.Field(i)
+		switch {
+		case tag == "-":
+			continue
+		case f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct:
+			addStructFields(fields, fv)
+			continue
+		case !f.IsExported():
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if strings.Contains(","+opts+",", ",omitempty,") && fv.IsZero() {
+			continue
+		}
+		fields[name] = fv.Interface()
+	}
+}
+
+// TimeScalar encodes time.Time values as strings in the given layout,
+// such as time.RFC3339 or time.DateOnly, and decodes them into
+// time.Time, string or any.
+func TimeScalar(layout string) ScalarCodec {
+	return ScalarFuncs{
+		Encode: func(v any) (any, error) {
+			if t, ok := v.(time.Time); ok {
+				return t.Format(layout), nil
+			}
+			return v, nil
+		},
+		Decode: func(data []byte, dst any) error {
+			var s string
+			if err := json.Unmarshal(data, &s); err != nil {
+				return err
+			}
+			if p, ok := dst.(*string); ok {
+				*p = s
+				return nil
+			}
+			t, err := time.Parse(layout, s)
+			if err != nil {
+				return err
+			}
+			return setDecoded(dst, t, data)
+		},
+	}
+}
+
+// UnixTimeScalar encodes time.Time values as the number of units, such
+// as time.Second or time.Millisecond, since the Unix epoch.
+func UnixTimeScalar(unit time.Duration) ScalarCodec {
+	return ScalarFuncs{
+		Encode: func(v any) (any, error) {
+			if t, ok := v.(time.Time); ok {
+				return json.Number(strconv.FormatInt(t.UnixNano()/int64(unit), 10)), nil
+			}
+			return v, nil
+		},
+		Decode: func(data []byte, dst any) error {
+			var n json.Number
+			if err := json.Unmarshal(data, &n); err != nil {
+				return err
+			}
+			f, ok := new(big.Float).SetString(string(n))
+			if !ok {
+				return fmt.Errorf("invalid time %s", n)
+			}
+			ns, _ := f.Mul(f, big.NewFloat(float64(unit))).Int64()
+			return setDecoded(dst, time.Unix(0, ns).UTC(), data)
+		},
+	}
+}
+
+// DecimalScalar encodes exact decimal numbers as strings and decodes
+// numbers or strings without loss of precision. Go values may be
+// *big.Rat, *big.Float, *big.Int, json.Number, strings of decimal
+// digits, or integer and float types. Decoded values may be stored in
+// the same types; any receives a json.Number.
+func DecimalScalar() ScalarCodec {
+	return ScalarFuncs{
+		Encode: func(v any) (any, error) {
+			s, err := decimalString(v)
+			if err != nil {
+				return nil, err
+			}
+			return s, nil
+		},
+		Decode: func(data []byte, dst any) error {
+			s, err := numberText(data)
+			if err != nil {
+				return err
+			}
+			r, ok := new(big.Rat).SetString(s)
+			if !ok {
+				return fmt.Errorf("invalid decimal %q", s)
+			}
+			switch p := dst.(type) {
+			case *big.Rat:
+				p.Set(r)
+			case *big.Float:
+				prec := uint(len(s))*4 + 64
+				if _, ok := p.SetPrec(prec).SetString(s); !ok {
+					return fmt.Errorf("invalid decimal %q", s)
+				}
+			case *string:
+				*p = s
+			case *json.Number, *any:
+				return setDecoded(dst, json.Number(s), data)
+			default:
+				return json.Unmarshal([]byte(s), dst)
+			}
+			return nil
+		},
+	}
+}
+
+// BigIntScalar encodes integers of any size as JSON numbers and decodes
+// numbers or strings without loss of precision. Go values may be
+// *big.Int, json.Number, strings of digits or integer types; any
+// receives a *big.Int.
+func BigIntScalar() ScalarCodec {
+	return ScalarFuncs{
+		Encode: func(v any) (any, error) {
+			var s string
+			switch v := v.(type) {
+			case big.Int:
+				s = v.String()
+			case json.Number:
+				s = string(v)
+			case string:
+				s = v
+			default:
+				rv := reflect.ValueOf(v)
+				switch {
+				case rv.CanInt():
+					s = strconv.FormatInt(rv.Int(), 10)
+				case rv.CanUint():
+					s = strconv.FormatUint(rv.Uint(), 10)
+				default:
+					return v, nil
+				}
+			}
+			if _, ok := new(big.Int).SetString(s, 10); !ok {
+				return nil, fmt.Errorf("invalid integer %q", s)
+			}
+			return json.Number(s), nil
+		},
+		Decode: func(data []byte, dst any) error {
+			s, err := numberText(data)
+			if err != nil {
+				return err
+			}
+			n, ok := new(big.Int).SetString(s, 10)
+			if !ok {
+				return fmt.Errorf("invalid integer %q", s)
+			}
+			switch p := dst.(type) {
+			case *big.Int:
+				p.Set(n)
+			case *string:
+				*p = s
+			case *any:
+				*p = n
+			default:
+				return json.Unmarshal([]byte(s), dst)
+			}
+			return nil
+		},
+	}
+}
+
+// UUIDScalar checks that UUIDs are in the canonical textual form. Go
+// values may be strings, [16]byte arrays or types implementing
+// encoding.TextMarshaler and encoding.TextUnmarshaler.
+func UUIDScalar() ScalarCodec {
+	return ScalarFuncs{
+		Encode: func(v any) (any, error) {
+			var s string
+			switch v := v.(type) {
+			case string:
+				s = v
+			case [16]byte:
+				s = formatUUID(v)
+			case encoding.TextMarshaler:
+				text, err := v.MarshalText()
+				if err != nil {
+					return nil, err
+				}
+				s = string(text)
+			default:
+				return v, nil
+			}
+			if _, err := parseUUID(s); err != nil {
+				return nil, err
+			}
+			return s, nil
+		},
+		Decode: func(data []byte, dst any) error {
+			var s string
+			if err := json.Unmarshal(data, &s); err != nil {
+				return err
+			}
+			b, err := parseUUID(s)
+			if err != nil {
+				return err
+			}
+			switch p := dst.(type) {
+			case *string:
+				*p = s
+			case *[16]byte:
+				*p = b
+			case *any:
+				*p = s
+			case encoding.TextUnmarshaler:
+				return p.UnmarshalText([]byte(s))
+			default:
+				return json.Unmarshal(data, dst)
+			}
+			return nil
+		},
+	}
+}
+
+// JSONScalar passes arbitrary JSON through. Decoded values may be
+// stored in a json.RawMessage, any, or any type encoding/json can
+// decode the value into; numbers in an any are json.Number.
+func JSONScalar() ScalarCodec {
+	return ScalarFuncs{
+		Decode: func(data []byte, dst any) error {
+			switch p := dst.(type) {
+			case *json.RawMessage:
+				*p = append((*p)[:0], data...)
+				return nil
+			case *any:
+				dec := json.NewDecoder(strings.NewReader(string(data)))
+				dec.UseNumber()
+				return dec.Decode(p)
+			}
+			return json.Unmarshal(data, dst)
+		},
+	}
+}
+
+// setDecoded stores value in dst if it has a matching type, and
+// otherwise decodes data into dst with encoding/json.
+func setDecoded(dst any, value any, data []byte) error {
+	pv := reflect.ValueOf(dst).Elem()
+	vv := reflect.ValueOf(value)
+	switch {
+	case pv.Kind() == reflect.Interface && vv.Type().Implements(pv.Type()):
+		pv.Set(vv)
+	case vv.Type().AssignableTo(pv.Type()):
+		pv.Set(vv)
+	default:
+		return json.Unmarshal(data, dst)
+	}
+	return nil
+}
+
+// numberText gets the text of a JSON number or of a string holding one.
+func numberText(data []byte) (string, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return strings.TrimSpace(s), nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return "", err
+	}
+	return string(n), nil
+}
+
+// decimalString formats v as an exact decimal.
+func decimalString(v any) (string, error) {
+	switch v := v.(type) {
+	case big.Rat:
+		return exactDecimal(&v)
+	case big.Float:
+		return v.Text('f', -1), nil
+	case big.Int:
+		return v.String(), nil
+	case json.Number:
+		return validDecimal(string(v))
+	case string:
+		return validDecimal(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanInt():
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case rv.CanUint():
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("cannot encode %T as a decimal", v)
+}
+
+func validDecimal(s string) (string, error) {
+	if _, ok := new(big.Rat).SetString(s); !ok || strings.ContainsAny(s, "/") {
+		return "", fmt.Errorf("invalid decimal %q", s)
+	}
+	return s, nil
+}
+
+// exactDecimal formats r with as many digits as it needs, or fails if
+// it has no finite decimal form.
+func exactDecimal(r *big.Rat) (string, error) {
+	denom := new(big.Int).Set(r.Denom())
+	digits := 0
+	two, five := big.NewInt(2), big.NewInt(5)
+	for _, f := range []*big.Int{two, five} {
+		n := 0
+		m := new(big.Int)
+		for {
+			q, rem := new(big.Int).QuoRem(denom, f, m)
+			if rem.Sign() != 0 {
+				break
+			}
+			denom = q
+			n++
+		}
+		digits = max(digits, n)
+	}
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		return "", fmt.Errorf("%s has no exact decimal form", r)
+	}
+	return r.FloatString(digits), nil
+}
+
+func formatUUID(b [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func parseUUID(s string) ([16]byte, error) {
+	var b [16]byte
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return b, fmt.Errorf("invalid UUID %q", s)
+	}
+	hex := strings.ReplaceAll(s, "-", "")
+	for i := range b {
+		n, err := strconv.ParseUint(hex[2*i:2*i+2], 16, 8)
+		if err != nil {
+			return b, fmt.Errorf("invalid UUID %q", s)
+		}
+		b[i] = byte(n)
+	}
+	return b, nil
+}
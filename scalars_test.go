@@ -0,0 +1,183 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/razzkumar/go-graphql/schema"
+)
+
+func scalarServer(t *testing.T, response *string, vars *map[string]json.RawMessage) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]json.RawMessage `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		*vars = body.Variables
+		io.WriteString(w, *response)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestScalarsWithSchema(t *testing.T) {
+	s, err := schema.LoadSDL(`
+		scalar DateTime
+		scalar Decimal
+		scalar BigInt
+		input PaymentInput { amount: Decimal!, at: DateTime }
+		type Payment { id: BigInt!, amount: Decimal!, at: DateTime, history: [DateTime!]! }
+		type Query { payments(since: DateTime!): [Payment!]! }
+		type Mutation { pay(input: PaymentInput!): Payment! }
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var vars map[string]json.RawMessage
+	response := `{"data":{"payments":[{"id":"18446744073709551617","amount":"0.10","at":"2024-01-02 03:04:05","history":["2024-01-01 00:00:00"]}]}}`
+	srv := scalarServer(t, &response, &vars)
+	client := NewClient(srv.URL,
+		WithSchemaValidation(s),
+		WithScalar("DateTime", TimeScalar(time.DateTime)),
+		WithScalar("Decimal", DecimalScalar()),
+		WithScalar("BigInt", BigIntScalar()),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	req := NewRequest(`query ($since: DateTime!) { payments(since: $since) { id amount at history } }`)
+	req.Var("since", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var resp struct {
+		Payments []struct {
+			ID      *big.Int
+			Amount  *big.Rat
+			At      *time.Time
+			History []time.Time
+		}
+	}
+	if err := client.Run(ctx, req, &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := string(vars["since"]), `"2024-01-01 00:00:00"`; got != want {
+		t.Errorf("since got %s, want %s", got, want)
+	}
+	p := resp.Payments[0]
+	if got, want := p.ID.String(), "18446744073709551617"; got != want {
+		t.Errorf("id got %s, want %s", got, want)
+	}
+	if got, want := p.Amount.FloatString(2), "0.10"; got != want {
+		t.Errorf("amount got %s, want %s", got, want)
+	}
+	if p.At == nil || !p.At.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("at got %v", p.At)
+	}
+	if len(p.History) != 1 || p.History[0].Year() != 2024 {
+		t.Errorf("history got %v", p.History)
+	}
+	if got := req.Vars()["since"]; got != time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("request variable was changed to %v", got)
+	}
+
+	// Input object fields are encoded, and untyped results decoded.
+	req = NewRequest(`mutation ($input: PaymentInput!) { pay(input: $input) { amount } }`)
+	req.Var("input", struct {
+		Amount *big.Rat   `json:"amount"`
+		At     *time.Time `json:"at,omitempty"`
+	}{Amount: big.NewRat(1, 8)})
+	response = `{"data":{"pay":{"amount":0.125}}}`
+	var untyped map[string]any
+	if err := client.Run(ctx, req, &untyped); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := string(vars["input"]), `{"amount":"0.125"}`; got != want {
+		t.Errorf("input got %s, want %s", got, want)
+	}
+	if got, want := untyped["pay"].(map[string]any)["amount"], json.Number("0.125"); got != want {
+		t.Errorf("amount got %#v, want %#v", got, want)
+	}
+}
+
+func TestScalarsWithTags(t *testing.T) {
+	var vars map[string]json.RawMessage
+	response := `{"data":{"event":{"id":"6ba7b810-9dad-11d1-80b4-00c04fd430c8","at":1700000000000,"meta":{"n":12345678901234567890}}}}`
+	srv := scalarServer(t, &response, &vars)
+	client := NewClient(srv.URL,
+		WithScalar("Timestamp", UnixTimeScalar(time.Millisecond)),
+		WithScalar("UUID", UUIDScalar()),
+		WithScalar("JSON", JSONScalar()),
+	)
+	req := NewRequest(`query ($id: UUID!) { event(id: $id) { id at meta } }`)
+	req.Var("id", [16]byte{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8})
+	var resp struct {
+		Event struct {
+			ID   [16]byte  `scalar:"UUID"`
+			At   time.Time `scalar:"Timestamp"`
+			Meta any       `scalar:"JSON"`
+		}
+	}
+	if err := client.Run(context.Background(), req, &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := string(vars["id"]), `"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`; got != want {
+		t.Errorf("id variable got %s, want %s", got, want)
+	}
+	if resp.Event.ID[0] != 0x6b || resp.Event.ID[15] != 0xc8 {
+		t.Errorf("id got %x", resp.Event.ID)
+	}
+	if got, want := resp.Event.At.UnixMilli(), int64(1700000000000); got != want {
+		t.Errorf("at got %v, want %v", got, want)
+	}
+	if got, want := resp.Event.Meta.(map[string]any)["n"], json.Number("12345678901234567890"); got != want {
+		t.Errorf("meta.n got %#v, want %#v", got, want)
+	}
+}
+
+// comment is a recursive type whose scalar field comes after the cycle.
+type comment struct {
+	Replies []comment
+	At      time.Time `scalar:"Timestamp"`
+}
+
+func TestScalarsWithRecursiveTags(t *testing.T) {
+	var vars map[string]json.RawMessage
+	response := `{"data":{"comment":{"replies":[{"replies":[],"at":1700000000000}],"at":1600000000000}}}`
+	srv := scalarServer(t, &response, &vars)
+	client := NewClient(srv.URL, WithScalar("Timestamp", UnixTimeScalar(time.Millisecond)))
+	var resp struct{ Comment comment }
+	if err := client.Run(context.Background(), NewRequest(`query { comment { replies { replies at } at } }`), &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := resp.Comment.At.UnixMilli(), int64(1600000000000); got != want {
+		t.Errorf("at got %v, want %v", got, want)
+	}
+	if len(resp.Comment.Replies) != 1 {
+		t.Fatalf("got %d replies, want 1", len(resp.Comment.Replies))
+	}
+	if got, want := resp.Comment.Replies[0].At.UnixMilli(), int64(1700000000000); got != want {
+		t.Errorf("reply at got %v, want %v", got, want)
+	}
+}
+
+func TestScalarCodecErrors(t *testing.T) {
+	if _, err := UUIDScalar().EncodeScalar("not-a-uuid"); err == nil {
+		t.Error("expected error for invalid UUID")
+	}
+	if _, err := DecimalScalar().EncodeScalar(*big.NewRat(1, 3)); err == nil {
+		t.Error("expected error for 1/3")
+	}
+	if _, err := BigIntScalar().EncodeScalar("1.5"); err == nil {
+		t.Error("expected error for 1.5")
+	}
+	var d float64
+	if err := DecimalScalar().DecodeScalar([]byte(`"2.5"`), &d); err != nil || d != 2.5 {
+		t.Errorf("decode got %v, %v", d, err)
+	}
+}
@@ -0,0 +1,441 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/razzkumar/go-graphql/language"
+)
+
+// Criticality classifies how a change affects existing clients.
+type Criticality string
+
+// Criticality levels.
+const (
+	// Breaking changes make existing operations fail.
+	Breaking Criticality = "BREAKING"
+	// Dangerous changes keep existing operations valid but may change
+	// their behaviour, for example a new enum value clients don't handle.
+	Dangerous Criticality = "DANGEROUS"
+	// Safe changes cannot affect existing clients.
+	Safe Criticality = "SAFE"
+)
+
+// ChangeType identifies the kind of a Change.
+type ChangeType string
+
+// Change types.
+const (
+	TypeRemoved                ChangeType = "TYPE_REMOVED"
+	TypeAdded                  ChangeType = "TYPE_ADDED"
+	TypeKindChanged            ChangeType = "TYPE_KIND_CHANGED"
+	RootTypeChanged            ChangeType = "ROOT_TYPE_CHANGED"
+	FieldRemoved               ChangeType = "FIELD_REMOVED"
+	FieldAdded                 ChangeType = "FIELD_ADDED"
+	FieldTypeChanged           ChangeType = "FIELD_TYPE_CHANGED"
+	FieldDeprecated            ChangeType = "FIELD_DEPRECATED"
+	ArgRemoved                 ChangeType = "ARG_REMOVED"
+	ArgAdded                   ChangeType = "ARG_ADDED"
+	ArgTypeChanged             ChangeType = "ARG_TYPE_CHANGED"
+	ArgDefaultChanged          ChangeType = "ARG_DEFAULT_CHANGED"
+	InputFieldRemoved          ChangeType = "INPUT_FIELD_REMOVED"
+	InputFieldAdded            ChangeType = "INPUT_FIELD_ADDED"
+	InputFieldTypeChanged      ChangeType = "INPUT_FIELD_TYPE_CHANGED"
+	InputFieldDefaultChanged   ChangeType = "INPUT_FIELD_DEFAULT_CHANGED"
+	EnumValueRemoved           ChangeType = "ENUM_VALUE_REMOVED"
+	EnumValueAdded             ChangeType = "ENUM_VALUE_ADDED"
+	EnumValueDeprecated        ChangeType = "ENUM_VALUE_DEPRECATED"
+	UnionMemberRemoved         ChangeType = "UNION_MEMBER_REMOVED"
+	UnionMemberAdded           ChangeType = "UNION_MEMBER_ADDED"
+	InterfaceRemoved           ChangeType = "INTERFACE_REMOVED"
+	InterfaceAdded             ChangeType = "INTERFACE_ADDED"
+	DirectiveRemoved           ChangeType = "DIRECTIVE_REMOVED"
+	DirectiveAdded             ChangeType = "DIRECTIVE_ADDED"
+	DirectiveLocationRemoved   ChangeType = "DIRECTIVE_LOCATION_REMOVED"
+	DirectiveRepeatableRemoved ChangeType = "DIRECTIVE_REPEATABLE_REMOVED"
+)
+
+// Change is a single difference between two schemas.
+type Change struct {
+	Criticality Criticality `json:"criticality"`
+	Type        ChangeType  `json:"type"`
+	// Path is the schema coordinate of the changed element, such as
+	// User.name or Query.user(id:).
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%-9s %s: %s", c.Criticality, c.Path, c.Message)
+}
+
+// Changes is a list of schema changes.
+type Changes []Change
+
+// Breaking gets the breaking changes.
+func (cs Changes) Breaking() Changes {
+	return cs.filter(Breaking)
+}
+
+// Dangerous gets the dangerous changes.
+func (cs Changes) Dangerous() Changes {
+	return cs.filter(Dangerous)
+}
+
+// HasBreaking reports whether any change is breaking.
+func (cs Changes) HasBreaking() bool {
+	return len(cs.Breaking()) > 0
+}
+
+func (cs Changes) filter(c Criticality) Changes {
+	var out Changes
+	for _, change := range cs {
+		if change.Criticality == c {
+			out = append(out, change)
+		}
+	}
+	return out
+}
+
+// String formats the changes as a human readable report, one change
+// per line with breaking changes first.
+func (cs Changes) String() string {
+	if len(cs) == 0 {
+		return "No changes.\n"
+	}
+	var sb strings.Builder
+	for _, c := range []Criticality{Breaking, Dangerous, Safe} {
+		for _, change := range cs.filter(c) {
+			sb.WriteString(change.String())
+			sb.WriteByte('\n')
+		}
+	}
+	fmt.Fprintf(&sb, "\n%d breaking, %d dangerous, %d safe\n", len(cs.Breaking()), len(cs.Dangerous()), len(cs.filter(Safe)))
+	return sb.String()
+}
+
+// Diff compares two schemas and classifies every change from oldSchema
+// to newSchema. Built-in and introspection definitions are ignored.
+func Diff(oldSchema, newSchema *Schema) Changes {
+	d := &differ{}
+	d.diffRoots(oldSchema, newSchema)
+	for _, name := range oldSchema.TypeNames() {
+		if IsBuiltinType(name) {
+			continue
+		}
+		oldType := oldSchema.Types[name]
+		newType, ok := newSchema.Types[name]
+		if !ok {
+			d.add(Breaking, TypeRemoved, name, "Type %q was removed.", name)
+			continue
+		}
+		if oldType.Kind != newType.Kind {
+			d.add(Breaking, TypeKindChanged, name, "%q changed from %s to %s.", name, kindName(oldType.Kind), kindName(newType.Kind))
+			continue
+		}
+		switch oldType.Kind {
+		case Object, Interface:
+			d.diffFields(oldType, newType)
+			d.diffInterfaces(oldType, newType)
+		case InputObject:
+			d.diffInputFields(oldType, newType)
+		case Enum:
+			d.diffEnumValues(oldType, newType)
+		case Union:
+			d.diffUnionMembers(oldType, newType)
+		}
+	}
+	for _, name := range newSchema.TypeNames() {
+		if _, ok := oldSchema.Types[name]; !ok && !IsBuiltinType(name) {
+			d.add(Safe, TypeAdded, name, "Type %q was added.", name)
+		}
+	}
+	d.diffDirectives(oldSchema, newSchema)
+	return d.changes
+}
+
+type differ struct {
+	changes Changes
+}
+
+func (d *differ) add(c Criticality, t ChangeType, path, format string, args ...any) {
+	d.changes = append(d.changes, Change{
+		Criticality: c,
+		Type:        t,
+		Path:        path,
+		Message:     fmt.Sprintf(format, args...),
+	})
+}
+
+func kindName(k language.DefinitionKind) string {
+	switch k {
+	case InputObject:
+		return "an Input type"
+	case Interface:
+		return "an Interface type"
+	case Object:
+		return "an Object type"
+	case Enum:
+		return "an Enum type"
+	case Union:
+		return "a Union type"
+	}
+	return "a Scalar type"
+}
+
+func (d *differ) diffRoots(oldSchema, newSchema *Schema) {
+	for _, op := range []language.Operation{language.Query, language.Mutation, language.Subscription} {
+		oldRoot, newRoot := oldSchema.RootType(op), newSchema.RootType(op)
+		switch {
+		case oldRoot == nil && newRoot == nil:
+		case oldRoot == nil:
+			d.add(Safe, RootTypeChanged, string(op), "Schema %s root type %q was added.", op, newRoot.Name)
+		case newRoot == nil:
+			d.add(Breaking, RootTypeChanged, string(op), "Schema %s root type %q was removed.", op, oldRoot.Name)
+		case oldRoot.Name != newRoot.Name:
+			d.add(Breaking, RootTypeChanged, string(op), "Schema %s root type changed from %q to %q.", op, oldRoot.Name, newRoot.Name)
+		}
+	}
+}
+
+func (d *differ) diffFields(oldType, newType *Type) {
+	for _, oldField := range oldType.Fields {
+		path := oldType.Name + "." + oldField.Name
+		newField := newType.Field(oldField.Name)
+		if newField == nil {
+			d.add(Breaking, FieldRemoved, path, "Field %q was removed.", path)
+			continue
+		}
+		if !oldField.Type.Equal(newField.Type) {
+			if isSafeOutputChange(oldField.Type, newField.Type) {
+				d.add(Safe, FieldTypeChanged, path, "Field %q changed type from %q to %q.", path, oldField.Type, newField.Type)
+			} else {
+				d.add(Breaking, FieldTypeChanged, path, "Field %q changed type from %q to %q.", path, oldField.Type, newField.Type)
+			}
+		}
+		if !oldField.IsDeprecated && newField.IsDeprecated {
+			d.add(Safe, FieldDeprecated, path, "Field %q was deprecated: %s", path, newField.DeprecationReason)
+		}
+		d.diffArgs(path, oldField.Args, newField.Args)
+	}
+	for _, newField := range newType.Fields {
+		if oldType.Field(newField.Name) == nil {
+			path := newType.Name + "." + newField.Name
+			d.add(Safe, FieldAdded, path, "Field %q was added.", path)
+		}
+	}
+}
+
+func (d *differ) diffArgs(owner string, oldArgs, newArgs []*InputValue) {
+	for _, oldArg := range oldArgs {
+		path := owner + "(" + oldArg.Name + ":)"
+		newArg := inputValue(newArgs, oldArg.Name)
+		if newArg == nil {
+			d.add(Breaking, ArgRemoved, path, "Argument %q was removed.", path)
+			continue
+		}
+		if !oldArg.Type.Equal(newArg.Type) {
+			if isSafeInputChange(oldArg.Type, newArg.Type) {
+				d.add(Safe, ArgTypeChanged, path, "Argument %q changed type from %q to %q.", path, oldArg.Type, newArg.Type)
+			} else {
+				d.add(Breaking, ArgTypeChanged, path, "Argument %q changed type from %q to %q.", path, oldArg.Type, newArg.Type)
+			}
+		}
+		switch {
+		case oldArg.DefaultValue == nil && newArg.DefaultValue != nil:
+			d.add(Dangerous, ArgDefaultChanged, path, "Argument %q has added default value %s.", path, *newArg.DefaultValue)
+		case !sameDefault(oldArg.DefaultValue, newArg.DefaultValue):
+			d.add(Dangerous, ArgDefaultChanged, path, "Argument %q has changed default value from %s to %s.", path, defaultString(oldArg.DefaultValue), defaultString(newArg.DefaultValue))
+		}
+	}
+	for _, newArg := range newArgs {
+		if inputValue(oldArgs, newArg.Name) != nil {
+			continue
+		}
+		path := owner + "(" + newArg.Name + ":)"
+		if isRequired(newArg) {
+			d.add(Breaking, ArgAdded, path, "Required argument %q was added.", path)
+		} else {
+			d.add(Dangerous, ArgAdded, path, "Optional argument %q was added.", path)
+		}
+	}
+}
+
+func (d *differ) diffInputFields(oldType, newType *Type) {
+	for _, oldField := range oldType.InputFields {
+		path := oldType.Name + "." + oldField.Name
+		newField := newType.InputField(oldField.Name)
+		if newField == nil {
+			d.add(Breaking, InputFieldRemoved, path, "Input field %q was removed.", path)
+			continue
+		}
+		if !oldField.Type.Equal(newField.Type) {
+			if isSafeInputChange(oldField.Type, newField.Type) {
+				d.add(Safe, InputFieldTypeChanged, path, "Input field %q changed type from %q to %q.", path, oldField.Type, newField.Type)
+			} else {
+				d.add(Breaking, InputFieldTypeChanged, path, "Input field %q changed type from %q to %q.", path, oldField.Type, newField.Type)
+			}
+		}
+		switch {
+		case oldField.DefaultValue == nil && newField.DefaultValue != nil:
+			d.add(Dangerous, InputFieldDefaultChanged, path, "Input field %q has added default value %s.", path, *newField.DefaultValue)
+		case !sameDefault(oldField.DefaultValue, newField.DefaultValue):
+			d.add(Dangerous, InputFieldDefaultChanged, path, "Input field %q has changed default value from %s to %s.", path, defaultString(oldField.DefaultValue), defaultString(newField.DefaultValue))
+		}
+	}
+	for _, newField := range newType.InputFields {
+		if oldType.InputField(newField.Name) != nil {
+			continue
+		}
+		path := newType.Name + "." + newField.Name
+		if isRequired(newField) {
+			d.add(Breaking, InputFieldAdded, path, "Required input field %q was added.", path)
+		} else {
+			d.add(Dangerous, InputFieldAdded, path, "Optional input field %q was added.", path)
+		}
+	}
+}
+
+func (d *differ) diffEnumValues(oldType, newType *Type) {
+	for _, oldValue := range oldType.EnumValues {
+		path := oldType.Name + "." + oldValue.Name
+		newValue := newType.EnumValue(oldValue.Name)
+		if newValue == nil {
+			d.add(Breaking, EnumValueRemoved, path, "Enum value %q was removed.", path)
+			continue
+		}
+		if !oldValue.IsDeprecated && newValue.IsDeprecated {
+			d.add(Safe, EnumValueDeprecated, path, "Enum value %q was deprecated: %s", path, newValue.DeprecationReason)
+		}
+	}
+	for _, newValue := range newType.EnumValues {
+		if oldType.EnumValue(newValue.Name) == nil {
+			path := newType.Name + "." + newValue.Name
+			d.add(Dangerous, EnumValueAdded, path, "Enum value %q was added.", path)
+		}
+	}
+}
+
+func (d *differ) diffUnionMembers(oldType, newType *Type) {
+	for _, member := range oldType.PossibleTypes {
+		if !slices.Contains(newType.PossibleTypes, member) {
+			d.add(Breaking, UnionMemberRemoved, oldType.Name, "%q was removed from union type %q.", member, oldType.Name)
+		}
+	}
+	for _, member := range newType.PossibleTypes {
+		if !slices.Contains(oldType.PossibleTypes, member) {
+			d.add(Dangerous, UnionMemberAdded, newType.Name, "%q was added to union type %q.", member, newType.Name)
+		}
+	}
+}
+
+func (d *differ) diffInterfaces(oldType, newType *Type) {
+	for _, iface := range oldType.Interfaces {
+		if !slices.Contains(newType.Interfaces, iface) {
+			d.add(Breaking, InterfaceRemoved, oldType.Name, "%q no longer implements interface %q.", oldType.Name, iface)
+		}
+	}
+	for _, iface := range newType.Interfaces {
+		if !slices.Contains(oldType.Interfaces, iface) {
+			d.add(Dangerous, InterfaceAdded, newType.Name, "%q added to interfaces implemented by %q.", iface, newType.Name)
+		}
+	}
+}
+
+func (d *differ) diffDirectives(oldSchema, newSchema *Schema) {
+	names := make([]string, 0, len(oldSchema.Directives))
+	for name := range oldSchema.Directives {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		oldDir := oldSchema.Directives[name]
+		path := "@" + name
+		newDir, ok := newSchema.Directives[name]
+		if !ok {
+			d.add(Breaking, DirectiveRemoved, path, "Directive %q was removed.", path)
+			continue
+		}
+		d.diffArgs(path, oldDir.Args, newDir.Args)
+		for _, loc := range oldDir.Locations {
+			if !newDir.HasLocation(loc) {
+				d.add(Breaking, DirectiveLocationRemoved, path, "%s was removed from directive %q.", loc, path)
+			}
+		}
+		if oldDir.IsRepeatable && !newDir.IsRepeatable {
+			d.add(Breaking, DirectiveRepeatableRemoved, path, "Repeatable flag was removed from directive %q.", path)
+		}
+	}
+	names = names[:0]
+	for name := range newSchema.Directives {
+		if _, ok := oldSchema.Directives[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d.add(Safe, DirectiveAdded, "@"+name, "Directive \"@%s\" was added.", name)
+	}
+}
+
+// isSafeOutputChange reports whether clients reading a field of type
+// oldType can also read newType: the new type may only add non-null
+// wrappers.
+func isSafeOutputChange(oldType, newType *language.Type) bool {
+	if newType.NonNull && !oldType.NonNull {
+		return isSafeOutputChange(oldType, newType.Nullable())
+	}
+	if oldType.NonNull != newType.NonNull || oldType.IsList() != newType.IsList() {
+		return false
+	}
+	if oldType.IsList() {
+		return isSafeOutputChange(oldType.Elem, newType.Elem)
+	}
+	return oldType.NamedType == newType.NamedType
+}
+
+// isSafeInputChange reports whether values clients send for oldType
+// are still accepted for newType: the new type may only remove non-null
+// wrappers.
+func isSafeInputChange(oldType, newType *language.Type) bool {
+	if oldType.NonNull && !newType.NonNull {
+		return isSafeInputChange(oldType.Nullable(), newType)
+	}
+	if oldType.NonNull != newType.NonNull || oldType.IsList() != newType.IsList() {
+		return false
+	}
+	if oldType.IsList() {
+		return isSafeInputChange(oldType.Elem, newType.Elem)
+	}
+	return oldType.NamedType == newType.NamedType
+}
+
+func isRequired(v *InputValue) bool {
+	return v.Type.NonNull && v.DefaultValue == nil
+}
+
+// sameDefault compares two default value literals. They are
+// normalized first since servers format them differently.
+func sameDefault(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return normalizeLiteral(*a) == normalizeLiteral(*b)
+}
+
+func normalizeLiteral(s string) string {
+	v, err := language.ParseValue(s)
+	if err != nil {
+		return s
+	}
+	return v.String()
+}
+
+func defaultString(v *string) string {
+	if v == nil {
+		return "none"
+	}
+	return *v
+}
@@ -0,0 +1,116 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	oldSchema, err := LoadSDL(`
+		type Query {
+			user(id: ID!, locale: String = "en"): User
+			users(first: Int): [User!]!
+			items(limit: Int): [String!]!
+			legacy: String
+		}
+		type User {
+			id: ID!
+			name: String!
+			email: String
+			role: Role
+		}
+		enum Role { ADMIN MEMBER GUEST }
+		union Actor = User | Bot
+		type Bot { id: ID! }
+		input Filter { role: Role, page: Int }
+		directive @cached(ttl: Int) on FIELD
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	newSchema, err := LoadSDL(`
+		type Query {
+			user(id: ID!, locale: String = "de", preview: Boolean): User
+			users(first: Int!): [User!]!
+			items(limit: Int = 10): [String!]!
+			search(term: String!): [Actor!]!
+		}
+		type User {
+			id: ID!
+			name: String
+			email: String!
+			role: Role @deprecated(reason: "use roles")
+		}
+		enum Role { ADMIN MEMBER OWNER }
+		union Actor = User
+		type Bot { id: ID! }
+		input Filter { role: Role, page: Int = 1, limit: Int! }
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changes := Diff(oldSchema, newSchema)
+	want := []Change{
+		{Breaking, FieldRemoved, "Query.legacy", `Field "Query.legacy" was removed.`},
+		{Dangerous, ArgDefaultChanged, "Query.user(locale:)", `Argument "Query.user(locale:)" has changed default value from "en" to "de".`},
+		{Dangerous, ArgAdded, "Query.user(preview:)", `Optional argument "Query.user(preview:)" was added.`},
+		{Dangerous, ArgDefaultChanged, "Query.items(limit:)", `Argument "Query.items(limit:)" has added default value 10.`},
+		{Dangerous, InputFieldDefaultChanged, "Filter.page", `Input field "Filter.page" has added default value 1.`},
+		{Breaking, ArgTypeChanged, "Query.users(first:)", `Argument "Query.users(first:)" changed type from "Int" to "Int!".`},
+		{Safe, FieldAdded, "Query.search", `Field "Query.search" was added.`},
+		{Breaking, UnionMemberRemoved, "Actor", `"Bot" was removed from union type "Actor".`},
+		{Breaking, InputFieldAdded, "Filter.limit", `Required input field "Filter.limit" was added.`},
+		{Breaking, EnumValueRemoved, "Role.GUEST", `Enum value "Role.GUEST" was removed.`},
+		{Dangerous, EnumValueAdded, "Role.OWNER", `Enum value "Role.OWNER" was added.`},
+		{Breaking, FieldTypeChanged, "User.name", `Field "User.name" changed type from "String!" to "String".`},
+		{Safe, FieldTypeChanged, "User.email", `Field "User.email" changed type from "String" to "String!".`},
+		{Safe, FieldDeprecated, "User.role", `Field "User.role" was deprecated: use roles`},
+		{Breaking, DirectiveRemoved, "@cached", `Directive "@cached" was removed.`},
+	}
+	for _, w := range want {
+		found := false
+		for _, c := range changes {
+			if c == w {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing change %v", w)
+		}
+	}
+	if got, want := len(changes), len(want); got != want {
+		t.Errorf("len(changes) got %v, want %v:\n%v", got, want, changes)
+	}
+	if !changes.HasBreaking() {
+		t.Error("changes should be breaking")
+	}
+	report := changes.String()
+	if !strings.HasPrefix(report, "BREAKING  Actor: ") {
+		t.Errorf("report should start with breaking changes:\n%s", report)
+	}
+	if !strings.HasSuffix(report, "7 breaking, 5 dangerous, 3 safe\n") {
+		t.Errorf("report summary got:\n%s", report)
+	}
+	b, err := json.Marshal(changes.Breaking()[3])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := string(b), `{"criticality":"BREAKING","type":"FIELD_REMOVED","path":"Query.legacy","message":"Field \"Query.legacy\" was removed."}`; got != want {
+		t.Errorf("json got %v, want %v", got, want)
+	}
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	s, err := LoadSDL(`type Query { a(x: [Int!] = [1, 2]): String }`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changes := Diff(s, s)
+	if len(changes) != 0 {
+		t.Errorf("changes got %v, want none", changes)
+	}
+	if got, want := changes.String(), "No changes.\n"; got != want {
+		t.Errorf("changes.String() got %q, want %q", got, want)
+	}
+}
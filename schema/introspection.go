@@ -0,0 +1,306 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/razzkumar/go-graphql/language"
+)
+
+// IntrospectionQuery is the query used to fetch a schema from a server.
+// The response can be loaded with LoadIntrospection.
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    description
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      locations
+      isRepeatable
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  specifiedByURL
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+              ofType {
+                kind
+                name
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}
+`
+
+type introspectionResult struct {
+	Data *struct {
+		Schema *introspectionSchema `json:"__schema"`
+	} `json:"data"`
+	Schema *introspectionSchema `json:"__schema"`
+}
+
+type introspectionSchema struct {
+	Description      string                   `json:"description"`
+	QueryType        *introspectionTypeRef    `json:"queryType"`
+	MutationType     *introspectionTypeRef    `json:"mutationType"`
+	SubscriptionType *introspectionTypeRef    `json:"subscriptionType"`
+	Types            []introspectionType      `json:"types"`
+	Directives       []introspectionDirective `json:"directives"`
+}
+
+type introspectionType struct {
+	Kind           string                    `json:"kind"`
+	Name           string                    `json:"name"`
+	Description    string                    `json:"description"`
+	SpecifiedByURL string                    `json:"specifiedByURL"`
+	Fields         []introspectionField      `json:"fields"`
+	InputFields    []introspectionInputValue `json:"inputFields"`
+	Interfaces     []introspectionTypeRef    `json:"interfaces"`
+	EnumValues     []introspectionEnumValue  `json:"enumValues"`
+	PossibleTypes  []introspectionTypeRef    `json:"possibleTypes"`
+}
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+type introspectionField struct {
+	Name              string                    `json:"name"`
+	Description       string                    `json:"description"`
+	Args              []introspectionInputValue `json:"args"`
+	Type              *introspectionTypeRef     `json:"type"`
+	IsDeprecated      bool                      `json:"isDeprecated"`
+	DeprecationReason string                    `json:"deprecationReason"`
+}
+
+type introspectionInputValue struct {
+	Name         string                `json:"name"`
+	Description  string                `json:"description"`
+	Type         *introspectionTypeRef `json:"type"`
+	DefaultValue *string               `json:"defaultValue"`
+}
+
+type introspectionEnumValue struct {
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	IsDeprecated      bool   `json:"isDeprecated"`
+	DeprecationReason string `json:"deprecationReason"`
+}
+
+type introspectionDirective struct {
+	Name         string                    `json:"name"`
+	Description  string                    `json:"description"`
+	Locations    []string                  `json:"locations"`
+	Args         []introspectionInputValue `json:"args"`
+	IsRepeatable bool                      `json:"isRepeatable"`
+}
+
+// LoadIntrospection loads a schema from the JSON result of
+// IntrospectionQuery. Both the full response ({"data": {"__schema": ...}})
+// and the bare data object ({"__schema": ...}) are accepted.
+func LoadIntrospection(data []byte) (*Schema, error) {
+	var res introspectionResult
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("decoding introspection: %w", err)
+	}
+	is := res.Schema
+	if res.Data != nil && res.Data.Schema != nil {
+		is = res.Data.Schema
+	}
+	if is == nil {
+		return nil, errors.New("graphql: introspection result has no __schema")
+	}
+	s := &Schema{
+		Description: is.Description,
+		Types:       make(map[string]*Type, len(is.Types)),
+		Directives:  make(map[string]*Directive, len(is.Directives)),
+	}
+	for _, it := range is.Types {
+		t := &Type{
+			Kind:           language.DefinitionKind(it.Kind),
+			Name:           it.Name,
+			Description:    it.Description,
+			SpecifiedByURL: it.SpecifiedByURL,
+		}
+		for _, f := range it.Fields {
+			ft, err := f.Type.toType()
+			if err != nil {
+				return nil, fmt.Errorf("graphql: field %s.%s: %w", it.Name, f.Name, err)
+			}
+			args, err := toInputValues(f.Args)
+			if err != nil {
+				return nil, fmt.Errorf("graphql: field %s.%s: %w", it.Name, f.Name, err)
+			}
+			t.Fields = append(t.Fields, &Field{
+				Name:              f.Name,
+				Description:       f.Description,
+				Args:              args,
+				Type:              ft,
+				IsDeprecated:      f.IsDeprecated,
+				DeprecationReason: f.DeprecationReason,
+			})
+		}
+		inputFields, err := toInputValues(it.InputFields)
+		if err != nil {
+			return nil, fmt.Errorf("graphql: type %s: %w", it.Name, err)
+		}
+		t.InputFields = inputFields
+		for _, ref := range it.Interfaces {
+			t.Interfaces = append(t.Interfaces, ref.Name)
+		}
+		for _, ref := range it.PossibleTypes {
+			t.PossibleTypes = append(t.PossibleTypes, ref.Name)
+		}
+		for _, v := range it.EnumValues {
+			t.EnumValues = append(t.EnumValues, &EnumValue{
+				Name:              v.Name,
+				Description:       v.Description,
+				IsDeprecated:      v.IsDeprecated,
+				DeprecationReason: v.DeprecationReason,
+			})
+		}
+		s.Types[t.Name] = t
+	}
+	for _, id := range is.Directives {
+		args, err := toInputValues(id.Args)
+		if err != nil {
+			return nil, fmt.Errorf("graphql: directive @%s: %w", id.Name, err)
+		}
+		d := &Directive{
+			Name:         id.Name,
+			Description:  id.Description,
+			Args:         args,
+			IsRepeatable: id.IsRepeatable,
+		}
+		for _, loc := range id.Locations {
+			d.Locations = append(d.Locations, language.DirectiveLocation(loc))
+		}
+		s.Directives[d.Name] = d
+	}
+	roots := []struct {
+		ref  *introspectionTypeRef
+		dest **Type
+	}{
+		{is.QueryType, &s.Query},
+		{is.MutationType, &s.Mutation},
+		{is.SubscriptionType, &s.Subscription},
+	}
+	for _, root := range roots {
+		if root.ref == nil || root.ref.Name == "" {
+			continue
+		}
+		t, ok := s.Types[root.ref.Name]
+		if !ok {
+			return nil, fmt.Errorf("graphql: root type %q is not defined", root.ref.Name)
+		}
+		*root.dest = t
+	}
+	s.finish()
+	if err := s.checkReferences(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func toInputValues(ivs []introspectionInputValue) ([]*InputValue, error) {
+	values := make([]*InputValue, 0, len(ivs))
+	for _, iv := range ivs {
+		t, err := iv.Type.toType()
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", iv.Name, err)
+		}
+		values = append(values, &InputValue{
+			Name:         iv.Name,
+			Description:  iv.Description,
+			Type:         t,
+			DefaultValue: iv.DefaultValue,
+		})
+	}
+	return values, nil
+}
+
+func (ref *introspectionTypeRef) toType() (*language.Type, error) {
+	if ref == nil {
+		return nil, errors.New("missing type")
+	}
+	switch ref.Kind {
+	case "NON_NULL":
+		inner, err := ref.OfType.toType()
+		if err != nil {
+			return nil, err
+		}
+		return language.NonNullTypeRef(inner), nil
+	case "LIST":
+		inner, err := ref.OfType.toType()
+		if err != nil {
+			return nil, err
+		}
+		return language.ListTypeRef(inner), nil
+	}
+	if ref.Name == "" {
+		return nil, fmt.Errorf("type of kind %q has no name", ref.Kind)
+	}
+	return language.NamedTypeRef(ref.Name), nil
+}
@@ -0,0 +1,174 @@
+package schema
+
+import "github.com/razzkumar/go-graphql/language"
+
+// preludeSDL declares the built-in scalars, directives and
+// introspection types every schema has.
+const preludeSDL = `
+"The ` + "`Int`" + ` scalar type represents non-fractional signed whole numeric values."
+scalar Int
+"The ` + "`Float`" + ` scalar type represents signed double-precision fractional values."
+scalar Float
+"The ` + "`String`" + ` scalar type represents textual data."
+scalar String
+"The ` + "`Boolean`" + ` scalar type represents ` + "`true` or `false`" + `."
+scalar Boolean
+"The ` + "`ID`" + ` scalar type represents a unique identifier."
+scalar ID
+
+"Directs the executor to include this field or fragment only when the ` + "`if`" + ` argument is true."
+directive @include(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+"Directs the executor to skip this field or fragment when the ` + "`if`" + ` argument is true."
+directive @skip(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+"Marks an element of a GraphQL schema as no longer supported."
+directive @deprecated(reason: String = "No longer supported") on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
+"Exposes a URL that specifies the behavior of this scalar."
+directive @specifiedBy(url: String!) on SCALAR
+
+type __Schema {
+	description: String
+	types: [__Type!]!
+	queryType: __Type!
+	mutationType: __Type
+	subscriptionType: __Type
+	directives: [__Directive!]!
+}
+
+type __Type {
+	kind: __TypeKind!
+	name: String
+	description: String
+	specifiedByURL: String
+	fields(includeDeprecated: Boolean = false): [__Field!]
+	interfaces: [__Type!]
+	possibleTypes: [__Type!]
+	enumValues(includeDeprecated: Boolean = false): [__EnumValue!]
+	inputFields(includeDeprecated: Boolean = false): [__InputValue!]
+	ofType: __Type
+}
+
+enum __TypeKind {
+	SCALAR
+	OBJECT
+	INTERFACE
+	UNION
+	ENUM
+	INPUT_OBJECT
+	LIST
+	NON_NULL
+}
+
+type __Field {
+	name: String!
+	description: String
+	args(includeDeprecated: Boolean = false): [__InputValue!]!
+	type: __Type!
+	isDeprecated: Boolean!
+	deprecationReason: String
+}
+
+type __InputValue {
+	name: String!
+	description: String
+	type: __Type!
+	defaultValue: String
+	isDeprecated: Boolean!
+	deprecationReason: String
+}
+
+type __EnumValue {
+	name: String!
+	description: String
+	isDeprecated: Boolean!
+	deprecationReason: String
+}
+
+type __Directive {
+	name: String!
+	description: String
+	locations: [__DirectiveLocation!]!
+	args(includeDeprecated: Boolean = false): [__InputValue!]!
+	isRepeatable: Boolean!
+}
+
+enum __DirectiveLocation {
+	QUERY
+	MUTATION
+	SUBSCRIPTION
+	FIELD
+	FRAGMENT_DEFINITION
+	FRAGMENT_SPREAD
+	INLINE_FRAGMENT
+	VARIABLE_DEFINITION
+	SCHEMA
+	SCALAR
+	OBJECT
+	FIELD_DEFINITION
+	ARGUMENT_DEFINITION
+	INTERFACE
+	UNION
+	ENUM
+	ENUM_VALUE
+	INPUT_OBJECT
+	INPUT_FIELD_DEFINITION
+}
+`
+
+var prelude *Schema
+
+func init() {
+	s, err := buildSchema(mustParse(preludeSDL))
+	if err != nil {
+		panic(err)
+	}
+	prelude = s
+}
+
+// IsBuiltinType reports whether name is a built-in scalar or
+// introspection type.
+func IsBuiltinType(name string) bool {
+	_, ok := prelude.Types[name]
+	return ok
+}
+
+// IsBuiltinDirective reports whether name is a directive every schema
+// supports, such as skip and include.
+func IsBuiltinDirective(name string) bool {
+	_, ok := prelude.Directives[name]
+	return ok
+}
+
+// SchemaMetaField and TypeMetaField are the introspection fields
+// available on the query root type, and TypenameMetaField is available
+// on every object, interface and union.
+var (
+	SchemaMetaField = &Field{
+		Name: "__schema",
+		Type: mustParseType("__Schema!"),
+	}
+	TypeMetaField = &Field{
+		Name: "__type",
+		Args: []*InputValue{{Name: "name", Type: mustParseType("String!")}},
+		Type: mustParseType("__Type"),
+	}
+	TypenameMetaField = &Field{
+		Name: "__typename",
+		Type: mustParseType("String!"),
+	}
+)
+
+func mustParse(src string) *language.SchemaDocument {
+	doc, err := language.ParseSchema(src)
+	if err != nil {
+		panic(err)
+	}
+	return doc
+}
+
+func mustParseType(src string) *language.Type {
+	t, err := language.ParseType(src)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
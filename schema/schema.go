@@ -0,0 +1,309 @@
+// Package schema models a GraphQL schema.
+//
+// A Schema can be loaded from an introspection result or from a
+// document written in the schema definition language:
+//
+//	s, err := schema.LoadFile("schema.graphql")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	user := s.Types["User"]
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/razzkumar/go-graphql/language"
+)
+
+// Schema is a GraphQL type system.
+type Schema struct {
+	Description  string
+	Query        *Type
+	Mutation     *Type
+	Subscription *Type
+	Types        map[string]*Type
+	Directives   map[string]*Directive
+}
+
+// Type kinds, matching the __TypeKind introspection enum.
+const (
+	Scalar      = language.Scalar
+	Object      = language.Object
+	Interface   = language.Interface
+	Union       = language.Union
+	Enum        = language.Enum
+	InputObject = language.InputObject
+)
+
+// Type is a named type in a Schema.
+type Type struct {
+	Kind        language.DefinitionKind
+	Name        string
+	Description string
+	// Fields holds the fields of objects and interfaces.
+	Fields []*Field
+	// InputFields holds the fields of input objects.
+	InputFields []*InputValue
+	// Interfaces lists the interfaces an object or interface implements.
+	Interfaces []string
+	// PossibleTypes lists the object types of a union or the
+	// implementations of an interface.
+	PossibleTypes  []string
+	EnumValues     []*EnumValue
+	SpecifiedByURL string
+}
+
+// Field is a field of an object or interface type.
+type Field struct {
+	Name              string
+	Description       string
+	Args              []*InputValue
+	Type              *language.Type
+	IsDeprecated      bool
+	DeprecationReason string
+}
+
+// InputValue is an argument or an input object field.
+type InputValue struct {
+	Name        string
+	Description string
+	Type        *language.Type
+	// DefaultValue is the default as a GraphQL literal, or nil.
+	DefaultValue *string
+}
+
+// EnumValue is a value of an enum type.
+type EnumValue struct {
+	Name              string
+	Description       string
+	IsDeprecated      bool
+	DeprecationReason string
+}
+
+// Directive is a directive supported by a Schema.
+type Directive struct {
+	Name         string
+	Description  string
+	Locations    []language.DirectiveLocation
+	Args         []*InputValue
+	IsRepeatable bool
+}
+
+// Field gets the named field of an object or interface, or nil.
+func (t *Type) Field(name string) *Field {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// InputField gets the named field of an input object, or nil.
+func (t *Type) InputField(name string) *InputValue {
+	return inputValue(t.InputFields, name)
+}
+
+// EnumValue gets the named enum value, or nil.
+func (t *Type) EnumValue(name string) *EnumValue {
+	for _, v := range t.EnumValues {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// Arg gets the named argument, or nil.
+func (f *Field) Arg(name string) *InputValue {
+	return inputValue(f.Args, name)
+}
+
+// Arg gets the named argument, or nil.
+func (d *Directive) Arg(name string) *InputValue {
+	return inputValue(d.Args, name)
+}
+
+// HasLocation reports whether the directive may be used at loc.
+func (d *Directive) HasLocation(loc language.DirectiveLocation) bool {
+	for _, l := range d.Locations {
+		if l == loc {
+			return true
+		}
+	}
+	return false
+}
+
+func inputValue(values []*InputValue, name string) *InputValue {
+	for _, v := range values {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// IsLeaf reports whether t is a scalar or enum.
+func (t *Type) IsLeaf() bool {
+	return t.Kind == Scalar || t.Kind == Enum
+}
+
+// IsComposite reports whether t is an object, interface or union.
+func (t *Type) IsComposite() bool {
+	return t.Kind == Object || t.Kind == Interface || t.Kind == Union
+}
+
+// IsAbstract reports whether t is an interface or union.
+func (t *Type) IsAbstract() bool {
+	return t.Kind == Interface || t.Kind == Union
+}
+
+// IsInputType reports whether t may be used for arguments and
+// variables.
+func (t *Type) IsInputType() bool {
+	return t.Kind == Scalar || t.Kind == Enum || t.Kind == InputObject
+}
+
+// IsOutputType reports whether t may be used as the type of a field.
+func (t *Type) IsOutputType() bool {
+	return t.Kind != InputObject
+}
+
+// RootType gets the root type for an operation, or nil if the schema
+// does not support it.
+func (s *Schema) RootType(op language.Operation) *Type {
+	switch op {
+	case language.Query:
+		return s.Query
+	case language.Mutation:
+		return s.Mutation
+	case language.Subscription:
+		return s.Subscription
+	}
+	return nil
+}
+
+// PossibleTypes gets the object types that t may resolve to. For an
+// object that is t itself.
+func (s *Schema) PossibleTypes(t *Type) []*Type {
+	if t.Kind == Object {
+		return []*Type{t}
+	}
+	types := make([]*Type, 0, len(t.PossibleTypes))
+	for _, name := range t.PossibleTypes {
+		if pt := s.Types[name]; pt != nil {
+			types = append(types, pt)
+		}
+	}
+	return types
+}
+
+// IsPossibleType reports whether the object type obj is a possible
+// type of the abstract type t.
+func (s *Schema) IsPossibleType(t, obj *Type) bool {
+	for _, pt := range s.PossibleTypes(t) {
+		if pt.Name == obj.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSubType reports whether values of sub are always valid where
+// super is expected: super is the same type, or an interface or union
+// that sub belongs to.
+func (s *Schema) IsSubType(super, sub *Type) bool {
+	if super.Name == sub.Name {
+		return true
+	}
+	if super.Kind == Interface && (sub.Kind == Object || sub.Kind == Interface) {
+		for _, name := range sub.Interfaces {
+			if name == super.Name {
+				return true
+			}
+		}
+	}
+	if super.Kind == Union && sub.Kind == Object {
+		return s.IsPossibleType(super, sub)
+	}
+	return false
+}
+
+// TypeNames gets the names of all types in s, sorted.
+func (s *Schema) TypeNames() []string {
+	names := make([]string, 0, len(s.Types))
+	for name := range s.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load loads a schema from either an introspection result (JSON) or
+// a schema definition language document.
+func Load(data []byte) (*Schema, error) {
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		return LoadIntrospection(data)
+	}
+	return LoadSDL(string(data))
+}
+
+// LoadFile loads a schema from a file. Files ending in .json are read
+// as introspection results, everything else as schema definition
+// language.
+func LoadFile(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		s, err := LoadIntrospection(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return s, nil
+	}
+	s, err := LoadSDL(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// finish links possible types and adds missing built-in definitions.
+func (s *Schema) finish() {
+	for _, t := range prelude.Types {
+		if _, ok := s.Types[t.Name]; !ok {
+			s.Types[t.Name] = t
+		}
+	}
+	for _, d := range prelude.Directives {
+		if _, ok := s.Directives[d.Name]; !ok {
+			s.Directives[d.Name] = d
+		}
+	}
+	implementations := make(map[string][]string)
+	for _, name := range s.TypeNames() {
+		t := s.Types[name]
+		if t.Kind != Object && t.Kind != Interface {
+			continue
+		}
+		for _, iface := range t.Interfaces {
+			implementations[iface] = append(implementations[iface], t.Name)
+		}
+	}
+	for _, t := range s.Types {
+		if t.Kind == Interface && len(t.PossibleTypes) == 0 {
+			for _, name := range implementations[t.Name] {
+				if s.Types[name].Kind == Object {
+					t.PossibleTypes = append(t.PossibleTypes, name)
+				}
+			}
+		}
+	}
+}
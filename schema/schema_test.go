@@ -0,0 +1,135 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSDL = `
+interface Node { id: ID! }
+
+type User implements Node {
+	id: ID!
+	name: String @deprecated
+	role: Role!
+}
+
+type Post implements Node {
+	id: ID!
+	title: String!
+}
+
+union SearchResult = User | Post
+
+enum Role { ADMIN MEMBER @deprecated(reason: "use ADMIN") }
+
+input UserFilter {
+	role: Role = MEMBER
+	limit: Int!
+}
+
+type Query {
+	node(id: ID!): Node
+	users(filter: UserFilter): [User!]!
+	search(term: String!): [SearchResult!]!
+}
+`
+
+func TestLoadSDL(t *testing.T) {
+	s, err := LoadSDL(testSDL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Query == nil || s.Query.Name != "Query" {
+		t.Fatalf("s.Query got %v, want Query", s.Query)
+	}
+	if s.Mutation != nil {
+		t.Errorf("s.Mutation got %v, want nil", s.Mutation)
+	}
+	node := s.Types["Node"]
+	if got, want := strings.Join(node.PossibleTypes, ","), "Post,User"; got != want {
+		t.Errorf("node.PossibleTypes got %v, want %v", got, want)
+	}
+	user := s.Types["User"]
+	if !s.IsSubType(node, user) {
+		t.Error("User should be a subtype of Node")
+	}
+	if !s.IsSubType(s.Types["SearchResult"], user) {
+		t.Error("User should be a subtype of SearchResult")
+	}
+	name := user.Field("name")
+	if !name.IsDeprecated || name.DeprecationReason != "No longer supported" {
+		t.Errorf("name deprecation got %v %q", name.IsDeprecated, name.DeprecationReason)
+	}
+	if got, want := s.Types["Role"].EnumValue("MEMBER").DeprecationReason, "use ADMIN"; got != want {
+		t.Errorf("MEMBER deprecation got %v, want %v", got, want)
+	}
+	if got, want := *s.Types["UserFilter"].InputField("role").DefaultValue, "MEMBER"; got != want {
+		t.Errorf("role default got %v, want %v", got, want)
+	}
+	for _, name := range []string{"String", "__Schema", "__Type"} {
+		if _, ok := s.Types[name]; !ok {
+			t.Errorf("built-in type %s missing", name)
+		}
+	}
+	if _, ok := s.Directives["include"]; !ok {
+		t.Error("built-in directive @include missing")
+	}
+}
+
+func TestLoadSDLErrors(t *testing.T) {
+	tests := []struct {
+		sdl  string
+		want string
+	}{
+		{`type Query { a: Missing }`, `field Query.a has undefined type "Missing"`},
+		{`type Query { a: String } type Query { b: String }`, `type "Query" defined more than once`},
+		{`input In { a: String } type Query { a: In }`, `must be an output type`},
+		{`extend type Nope { a: String }`, `cannot extend undefined type "Nope"`},
+		{`type Query { a(x: Query): String }`, `must be an input type`},
+	}
+	for _, tt := range tests {
+		_, err := LoadSDL(tt.sdl)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("LoadSDL(%q) err got %v, want %q", tt.sdl, err, tt.want)
+		}
+	}
+}
+
+func TestLoadIntrospection(t *testing.T) {
+	s, err := Load([]byte(`{"data": {"__schema": {
+		"queryType": {"name": "Query"},
+		"mutationType": null,
+		"subscriptionType": null,
+		"types": [
+			{"kind": "OBJECT", "name": "Query", "fields": [
+				{"name": "user", "args": [
+					{"name": "id", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}, "defaultValue": null}
+				], "type": {"kind": "OBJECT", "name": "User"}, "isDeprecated": false}
+			], "interfaces": []},
+			{"kind": "OBJECT", "name": "User", "fields": [
+				{"name": "tags", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "LIST", "ofType": {"kind": "SCALAR", "name": "String"}}}, "isDeprecated": true, "deprecationReason": "gone"}
+			], "interfaces": []},
+			{"kind": "SCALAR", "name": "ID"},
+			{"kind": "SCALAR", "name": "String"}
+		],
+		"directives": []
+	}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	arg := s.Query.Field("user").Arg("id")
+	if got, want := arg.Type.String(), "ID!"; got != want {
+		t.Errorf("arg type got %v, want %v", got, want)
+	}
+	tags := s.Types["User"].Field("tags")
+	if got, want := tags.Type.String(), "[String]!"; got != want {
+		t.Errorf("tags type got %v, want %v", got, want)
+	}
+	if !tags.IsDeprecated || tags.DeprecationReason != "gone" {
+		t.Errorf("tags deprecation got %v %q", tags.IsDeprecated, tags.DeprecationReason)
+	}
+	if _, ok := s.Directives["skip"]; !ok {
+		t.Error("built-in directive @skip missing")
+	}
+}
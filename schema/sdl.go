@@ -0,0 +1,261 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/razzkumar/go-graphql/language"
+)
+
+// LoadSDL loads a schema from a schema definition language document.
+// Built-in scalars, directives and introspection types are added
+// automatically.
+func LoadSDL(src string) (*Schema, error) {
+	doc, err := language.ParseSchema(src)
+	if err != nil {
+		return nil, err
+	}
+	return FromDocument(doc)
+}
+
+// FromDocument builds a schema from a parsed type system document.
+func FromDocument(doc *language.SchemaDocument) (*Schema, error) {
+	s, err := buildSchema(doc)
+	if err != nil {
+		return nil, err
+	}
+	s.finish()
+	if err := s.checkReferences(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func buildSchema(doc *language.SchemaDocument) (*Schema, error) {
+	s := &Schema{
+		Types:      make(map[string]*Type),
+		Directives: make(map[string]*Directive),
+	}
+	for _, def := range doc.Definitions {
+		if _, ok := s.Types[def.Name]; ok {
+			return nil, fmt.Errorf("graphql: %s: type %q defined more than once", def.Position, def.Name)
+		}
+		s.Types[def.Name] = &Type{Kind: def.Kind, Name: def.Name}
+		if err := extendType(s.Types[def.Name], def); err != nil {
+			return nil, err
+		}
+		s.Types[def.Name].Description = def.Description
+	}
+	for _, ext := range doc.Extensions {
+		t, ok := s.Types[ext.Name]
+		if !ok {
+			t, ok = prelude.Types[ext.Name]
+			if ok {
+				cp := *t
+				cp.Fields = slices.Clone(t.Fields)
+				cp.InputFields = slices.Clone(t.InputFields)
+				cp.Interfaces = slices.Clone(t.Interfaces)
+				cp.PossibleTypes = slices.Clone(t.PossibleTypes)
+				cp.EnumValues = slices.Clone(t.EnumValues)
+				t = &cp
+				s.Types[ext.Name] = t
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("graphql: %s: cannot extend undefined type %q", ext.Position, ext.Name)
+		}
+		if t.Kind != ext.Kind {
+			return nil, fmt.Errorf("graphql: %s: cannot extend %s %q as %s", ext.Position, t.Kind, ext.Name, ext.Kind)
+		}
+		if err := extendType(t, ext); err != nil {
+			return nil, err
+		}
+	}
+	for _, def := range doc.Directives {
+		if _, ok := s.Directives[def.Name]; ok {
+			return nil, fmt.Errorf("graphql: %s: directive %q defined more than once", def.Position, def.Name)
+		}
+		s.Directives[def.Name] = &Directive{
+			Name:         def.Name,
+			Description:  def.Description,
+			Locations:    def.Locations,
+			Args:         inputValues(def.Arguments),
+			IsRepeatable: def.Repeatable,
+		}
+	}
+	roots := make(map[language.Operation]string)
+	for _, def := range append(doc.Schema, doc.SchemaExtensions...) {
+		if def.Description != "" {
+			s.Description = def.Description
+		}
+		for _, ot := range def.OperationTypes {
+			if _, ok := roots[ot.Operation]; ok {
+				return nil, fmt.Errorf("graphql: %s: %s root type defined more than once", ot.Position, ot.Operation)
+			}
+			roots[ot.Operation] = ot.Type
+		}
+	}
+	if len(doc.Schema) == 0 {
+		for _, op := range []language.Operation{language.Query, language.Mutation, language.Subscription} {
+			if _, ok := roots[op]; ok {
+				continue
+			}
+			name := defaultRootNames[op]
+			if _, ok := s.Types[name]; ok {
+				roots[op] = name
+			}
+		}
+	}
+	for op, name := range roots {
+		t, ok := s.Types[name]
+		if !ok || t.Kind != Object {
+			return nil, fmt.Errorf("graphql: %s root type %q must be a defined object type", op, name)
+		}
+		switch op {
+		case language.Query:
+			s.Query = t
+		case language.Mutation:
+			s.Mutation = t
+		case language.Subscription:
+			s.Subscription = t
+		}
+	}
+	return s, nil
+}
+
+var defaultRootNames = map[language.Operation]string{
+	language.Query:        "Query",
+	language.Mutation:     "Mutation",
+	language.Subscription: "Subscription",
+}
+
+func extendType(t *Type, def *language.TypeDefinition) error {
+	t.Interfaces = append(t.Interfaces, def.Interfaces...)
+	t.PossibleTypes = append(t.PossibleTypes, def.Types...)
+	for _, d := range def.Directives {
+		if d.Name == "specifiedBy" {
+			if url := d.ArgumentValue("url"); url != nil {
+				t.SpecifiedByURL = url.Raw
+			}
+		}
+	}
+	for _, fd := range def.Fields {
+		if t.Field(fd.Name) != nil || t.InputField(fd.Name) != nil {
+			return fmt.Errorf("graphql: %s: field %s.%s defined more than once", fd.Position, t.Name, fd.Name)
+		}
+		if t.Kind == InputObject {
+			t.InputFields = append(t.InputFields, &InputValue{
+				Name:         fd.Name,
+				Description:  fd.Description,
+				Type:         fd.Type,
+				DefaultValue: literal(fd.DefaultValue),
+			})
+			continue
+		}
+		f := &Field{
+			Name:        fd.Name,
+			Description: fd.Description,
+			Args:        inputValues(fd.Arguments),
+			Type:        fd.Type,
+		}
+		f.IsDeprecated, f.DeprecationReason = deprecation(fd.Directives)
+		t.Fields = append(t.Fields, f)
+	}
+	for _, vd := range def.EnumValues {
+		if t.EnumValue(vd.Name) != nil {
+			return fmt.Errorf("graphql: %s: enum value %s.%s defined more than once", vd.Position, t.Name, vd.Name)
+		}
+		v := &EnumValue{Name: vd.Name, Description: vd.Description}
+		v.IsDeprecated, v.DeprecationReason = deprecation(vd.Directives)
+		t.EnumValues = append(t.EnumValues, v)
+	}
+	return nil
+}
+
+func inputValues(args []*language.ArgumentDefinition) []*InputValue {
+	values := make([]*InputValue, len(args))
+	for i, arg := range args {
+		values[i] = &InputValue{
+			Name:         arg.Name,
+			Description:  arg.Description,
+			Type:         arg.Type,
+			DefaultValue: literal(arg.DefaultValue),
+		}
+	}
+	return values
+}
+
+func literal(v *language.Value) *string {
+	if v == nil {
+		return nil
+	}
+	s := v.String()
+	return &s
+}
+
+func deprecation(directives []*language.Directive) (bool, string) {
+	for _, d := range directives {
+		if d.Name != "deprecated" {
+			continue
+		}
+		if reason := d.ArgumentValue("reason"); reason != nil && reason.Kind != language.NullValue {
+			return true, reason.Raw
+		}
+		return true, "No longer supported"
+	}
+	return false, ""
+}
+
+// checkReferences makes sure every type referenced by a field,
+// argument, interface or union exists and is of a suitable kind.
+func (s *Schema) checkReferences() error {
+	for _, name := range s.TypeNames() {
+		t := s.Types[name]
+		for _, f := range t.Fields {
+			ft, ok := s.Types[f.Type.Name()]
+			if !ok {
+				return fmt.Errorf("graphql: field %s.%s has undefined type %q", t.Name, f.Name, f.Type.Name())
+			}
+			if !ft.IsOutputType() {
+				return fmt.Errorf("graphql: field %s.%s must be an output type but got %q", t.Name, f.Name, f.Type)
+			}
+			if err := s.checkInputValues(t.Name+"."+f.Name, f.Args); err != nil {
+				return err
+			}
+		}
+		if err := s.checkInputValues(t.Name, t.InputFields); err != nil {
+			return err
+		}
+		for _, iface := range t.Interfaces {
+			if it, ok := s.Types[iface]; !ok || it.Kind != Interface {
+				return fmt.Errorf("graphql: type %s implements %q which is not an interface", t.Name, iface)
+			}
+		}
+		if t.Kind == Union {
+			for _, member := range t.PossibleTypes {
+				if mt, ok := s.Types[member]; !ok || mt.Kind != Object {
+					return fmt.Errorf("graphql: union %s member %q must be an object type", t.Name, member)
+				}
+			}
+		}
+	}
+	for _, d := range s.Directives {
+		if err := s.checkInputValues("@"+d.Name, d.Args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) checkInputValues(owner string, values []*InputValue) error {
+	for _, v := range values {
+		vt, ok := s.Types[v.Type.Name()]
+		if !ok {
+			return fmt.Errorf("graphql: %s(%s:) has undefined type %q", owner, v.Name, v.Type.Name())
+		}
+		if !vt.IsInputType() {
+			return fmt.Errorf("graphql: %s(%s:) must be an input type but got %q", owner, v.Name, v.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signer signs requests.
+type Signer interface {
+	// Sign signs r, whose body is body, by setting its headers. It is
+	// called after all other headers are set and the body is compressed,
+	// every time the request is sent.
+	Sign(ctx context.Context, r *http.Request, body []byte) error
+}
+
+// SignerFunc adapts a function to a Signer.
+type SignerFunc func(ctx context.Context, r *http.Request, body []byte) error
+
+// Sign calls f.
+func (f SignerFunc) Sign(ctx context.Context, r *http.Request, body []byte) error {
+	return f(ctx, r, body)
+}
+
+// WithSigner signs every request with signer. Signers are called in the
+// order they are given.
+func WithSigner(signer Signer) ClientOption {
+	return func(client *Client) {
+		client.signers = append(client.signers, signer)
+	}
+}
+
+// sign signs r with the signers of the client.
+func (c *Client) sign(ctx context.Context, r *http.Request) error {
+	if len(c.signers) == 0 {
+		return nil
+	}
+	var body []byte
+	if r.GetBody != nil {
+		rc, err := r.GetBody()
+		if err != nil {
+			return fmt.Errorf("graphql: signing request: %w", err)
+		}
+		body, err = io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("graphql: signing request: %w", err)
+		}
+	}
+	for _, signer := range c.signers {
+		if err := signer.Sign(ctx, r, body); err != nil {
+			return fmt.Errorf("graphql: signing request: %w", err)
+		}
+	}
+	return nil
+}
+
+// AWSCredentials are the credentials of an AWS identity.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials.
+	SessionToken string
+}
+
+// Retrieve returns c, so that fixed credentials can be used as an
+// AWSCredentialsProvider.
+func (c AWSCredentials) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	return c, nil
+}
+
+// AWSCredentialsProvider gets AWS credentials. An aws.CredentialsProvider
+// of the AWS SDK can be adapted to it.
+type AWSCredentialsProvider interface {
+	Retrieve(ctx context.Context) (AWSCredentials, error)
+}
+
+// SigV4Signer signs requests with AWS Signature Version 4, such as for
+// AppSync APIs with IAM authorization.
+//
+//	NewClient(endpoint, WithSigner(&SigV4Signer{
+//	    Region:      "eu-west-1",
+//	    Credentials: AWSCredentials{AccessKeyID: id, SecretAccessKey: secret},
+//	}))
+type SigV4Signer struct {
+	Region string
+	// Service is the signing name of the service, "appsync" if empty.
+	Service     string
+	Credentials AWSCredentialsProvider
+	// Now returns the signing time, time.Now if nil.
+	Now func() time.Time
+}
+
+// sigV4Unsigned are headers that are not signed, because proxies may
+// change them.
+var sigV4Unsigned = map[string]bool{
+	"Authorization":   true,
+	"User-Agent":      true,
+	"X-Amzn-Trace-Id": true,
+	"Expect":          true,
+}
+
+const sigV4Algorithm = "AWS4-HMAC-SHA256"
+
+// Sign sets the X-Amz-Date, X-Amz-Security-Token and Authorization
+// headers of r.
+func (s *SigV4Signer) Sign(ctx context.Context, r *http.Request, body []byte) error {
+	if s.Credentials == nil {
+		return fmt.Errorf("sigv4: no credentials")
+	}
+	creds, err := s.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("sigv4: retrieving credentials: %w", err)
+	}
+	service := s.Service
+	if service == "" {
+		service = "appsync"
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	t := now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	scope := t.Format("20060102") + "/" + s.Region + "/" + service + "/aws4_request"
+
+	r.Header.Del("Authorization")
+	r.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	} else {
+		r.Header.Del("X-Amz-Security-Token")
+	}
+
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+	headers := map[string][]string{"host": {host}}
+	for key, values := range r.Header {
+		if !sigV4Unsigned[http.CanonicalHeaderKey(key)] {
+			name := strings.ToLower(key)
+			headers[name] = append(headers[name], values...)
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		values := make([]string, len(headers[name]))
+		for i, v := range headers[name] {
+			values[i] = strings.Join(strings.Fields(v), " ")
+		}
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.Join(values, ","))
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		awsEscape(path, false),
+		canonicalQuery(r.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := sigV4Algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSum(sha256.New, []byte("AWS4"+creds.SecretAccessKey), t.Format("20060102"))
+	for _, part := range []string{s.Region, service, "aws4_request"} {
+		key = hmacSum(sha256.New, key, part)
+	}
+	signature := hex.EncodeToString(hmacSum(sha256.New, key, stringToSign))
+	r.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, creds.AccessKeyID, scope, signedHeaders, signature))
+	return nil
+}
+
+// canonicalQuery formats the query parameters sorted by key and value.
+func canonicalQuery(query url.Values) string {
+	var params []string
+	for key, values := range query {
+		for _, value := range values {
+			params = append(params, awsEscape(key, true)+"="+awsEscape(value, true))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// awsEscape percent-encodes every byte of s but the unreserved characters
+// of RFC 3986, and slashes unless escapeSlash is set.
+func awsEscape(s string, escapeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !escapeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSum(h func() hash.Hash, key []byte, data string) []byte {
+	mac := hmac.New(h, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// HMACSigner signs requests with a shared key. By default the signed
+// message is the method, request URI, timestamp and body, each followed
+// by a newline but the body, and the signature is sent hex-encoded.
+type HMACSigner struct {
+	Key []byte
+	// Hash is the hash function, sha256.New if nil.
+	Hash func() hash.Hash
+	// Header is the header of the signature, "X-Signature" if empty.
+	Header string
+	// TimestampHeader is the header of the Unix time the request was
+	// signed at, "X-Signature-Timestamp" if empty.
+	TimestampHeader string
+	// Message returns the message to sign, if set.
+	Message func(r *http.Request, body []byte, timestamp string) []byte
+	// Now returns the signing time, time.Now if nil.
+	Now func() time.Time
+}
+
+// Sign sets the signature and timestamp headers of r.
+func (s *HMACSigner) Sign(ctx context.Context, r *http.Request, body []byte) error {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	timestamp := strconv.FormatInt(now().Unix(), 10)
+	var message []byte
+	if s.Message != nil {
+		message = s.Message(r, body, timestamp)
+	} else {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "%s\n%s\n%s\n", r.Method, r.URL.RequestURI(), timestamp)
+		buf.Write(body)
+		message = buf.Bytes()
+	}
+	h := s.Hash
+	if h == nil {
+		h = sha256.New
+	}
+	mac := hmac.New(h, s.Key)
+	mac.Write(message)
+	header, timestampHeader := s.Header, s.TimestampHeader
+	if header == "" {
+		header = "X-Signature"
+	}
+	if timestampHeader == "" {
+		timestampHeader = "X-Signature-Timestamp"
+	}
+	r.Header.Set(timestampHeader, timestamp)
+	r.Header.Set(header, hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
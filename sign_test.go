@@ -0,0 +1,180 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The requests and signatures of the AWS Signature Version 4 test suite.
+func TestSigV4Signer(t *testing.T) {
+	signer := &SigV4Signer{
+		Region:  "us-east-1",
+		Service: "service",
+		Credentials: AWSCredentials{
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		},
+		Now: func() time.Time {
+			return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+		},
+	}
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		want   string
+	}{
+		{
+			name:   "get-vanilla",
+			method: http.MethodGet,
+			url:    "https://example.amazonaws.com/",
+			want:   "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:   "post-vanilla",
+			method: http.MethodPost,
+			url:    "https://example.amazonaws.com/",
+			want:   "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:   "get-vanilla-query-order-key-case",
+			method: http.MethodGet,
+			url:    "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			want:   "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := http.NewRequest(tt.method, tt.url, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := signer.Sign(context.Background(), r, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := r.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date got %q, want 20150830T123600Z", got)
+			}
+			if got := r.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("Authorization got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSigV4SignerSessionToken(t *testing.T) {
+	signer := &SigV4Signer{
+		Region:      "eu-west-1",
+		Credentials: AWSCredentials{AccessKeyID: "id", SecretAccessKey: "secret", SessionToken: "session"},
+	}
+	r, err := http.NewRequest(http.MethodPost, "https://example.appsync-api.eu-west-1.amazonaws.com/graphql", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	if err := signer.Sign(context.Background(), r, []byte(`{}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := r.Header.Get("X-Amz-Security-Token"); got != "session" {
+		t.Errorf("X-Amz-Security-Token got %q, want session", got)
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.Contains(auth, "/eu-west-1/appsync/aws4_request") || !strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("Authorization got %q", auth)
+	}
+}
+
+func TestHMACSigner(t *testing.T) {
+	key := []byte("shared key")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		mac := hmac.New(sha256.New, key)
+		io.WriteString(mac, "POST\n/graphql?v=1\n1700000000\n")
+		mac.Write(body)
+		if got, want := r.Header.Get("X-Signature"), hex.EncodeToString(mac.Sum(nil)); got != want {
+			t.Errorf("signature got %q, want %q", got, want)
+		}
+		if got := r.Header.Get("X-Signature-Timestamp"); got != "1700000000" {
+			t.Errorf("timestamp got %q, want 1700000000", got)
+		}
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	signer := &HMACSigner{
+		Key: key,
+		Now: func() time.Time { return time.Unix(1700000000, 0) },
+	}
+	client := NewClient(srv.URL+"/graphql?v=1", WithSigner(signer))
+	req := NewRequest("query { items }")
+	req.Var("id", 1)
+	if err := client.Run(ctx, req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSignerFinalRequest(t *testing.T) {
+	var signed []byte
+	var signedHeader http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(body, signed) {
+			t.Errorf("body got %q, want the signed body %q", body, signed)
+		}
+		if got := r.Header.Get("X-Signed"); got != "yes" {
+			t.Errorf("X-Signed got %q, want yes", got)
+		}
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL,
+		WithRequestCompression("gzip", 0),
+		WithTokenSource(TokenSourceFunc(func(context.Context) (*Token, error) {
+			return &Token{AccessToken: "abc"}, nil
+		})),
+		WithSigner(SignerFunc(func(ctx context.Context, r *http.Request, body []byte) error {
+			signed = body
+			signedHeader = r.Header.Clone()
+			r.Header.Set("X-Signed", "yes")
+			return nil
+		})),
+	)
+	if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := signedHeader.Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("Content-Encoding got %q, want gzip", got)
+	}
+	if got := signedHeader.Get("Authorization"); got != "Bearer abc" {
+		t.Errorf("Authorization got %q, want %q", got, "Bearer abc")
+	}
+
+	errSign := errors.New("no key")
+	client = NewClient(srv.URL, WithSigner(SignerFunc(func(context.Context, *http.Request, []byte) error {
+		return errSign
+	})))
+	if err := client.Run(ctx, NewRequest("query {}"), nil); !errors.Is(err, errSign) {
+		t.Errorf("error got %v, want %v", err, errSign)
+	}
+}
@@ -0,0 +1,265 @@
+package graphql
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ID is the GraphQL ID type. Use it for variables of struct queries
+// that are declared as ID rather than String.
+type ID string
+
+// GraphQLTyper is implemented by variable types that name their
+// GraphQL type, such as "DateTime". Whether the variable is non-null
+// still follows from the Go type.
+type GraphQLTyper interface {
+	GraphQLType() string
+}
+
+var (
+	typerType           = reflect.TypeFor[GraphQLTyper]()
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// ConstructQuery builds a query document from the struct q. Fields are
+// selected by their lower camel case name, or by their graphql tag,
+// which may add arguments, an alias or directives, or make the field an
+// inline fragment:
+//
+//	var q struct {
+//	    User struct {
+//	        Name  string
+//	        Admin struct {
+//	            Level int
+//	        } `graphql:"... on Admin"`
+//	    } `graphql:"user(id: $id)"`
+//	}
+//
+// A variable definition is declared for each entry in vars, with a type
+// derived from its Go type: string, bool, integer and float types map to
+// String, Boolean, Int and Float, other named types and ID use their
+// name, slices become lists and pointers are nullable. Types can name
+// their GraphQL type by implementing GraphQLTyper.
+func ConstructQuery(q any, vars map[string]any) (string, error) {
+	return construct("query", q, vars)
+}
+
+// ConstructMutation builds a mutation document from the struct m, in
+// the same way as ConstructQuery.
+func ConstructMutation(m any, vars map[string]any) (string, error) {
+	return construct("mutation", m, vars)
+}
+
+// Query runs the query built from q by ConstructQuery, and decodes the
+// response data into q, which must be a pointer to a struct.
+func (c *Client) Query(ctx context.Context, q any, vars map[string]any) error {
+	return c.runStruct(ctx, "query", q, vars)
+}
+
+// Mutate runs the mutation built from m by ConstructMutation, and
+// decodes the response data into m, which must be a pointer to a struct.
+func (c *Client) Mutate(ctx context.Context, m any, vars map[string]any) error {
+	return c.runStruct(ctx, "mutation", m, vars)
+}
+
+func (c *Client) runStruct(ctx context.Context, op string, v any, vars map[string]any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("graphql: %s must be a non-nil pointer to a struct, got %T", op, v)
+	}
+	query, err := construct(op, v, vars)
+	if err != nil {
+		return err
+	}
+	req := NewRequest(query)
+	for key, value := range vars {
+		req.Var(key, value)
+	}
+	return c.Run(ctx, req, &responseValue{v: rv.Elem(), structQuery: true})
+}
+
+func construct(op string, v any, vars map[string]any) (string, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return "", fmt.Errorf("graphql: %s must be a struct, got %T", op, v)
+	}
+	var sb strings.Builder
+	sb.WriteString(op)
+	if len(vars) > 0 {
+		names := make([]string, 0, len(vars))
+		for name := range vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		sb.WriteString("(")
+		for i, name := range names {
+			if vars[name] == nil {
+				return "", fmt.Errorf("graphql: cannot infer the type of variable $%s from nil", name)
+			}
+			typ, err := variableType(reflect.TypeOf(vars[name]))
+			if err != nil {
+				return "", fmt.Errorf("graphql: variable $%s: %w", name, err)
+			}
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString("$" + name + ": " + typ)
+		}
+		sb.WriteString(")")
+	}
+	sb.WriteString(" ")
+	if err := writeSelectionSet(&sb, t, nil); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// variableType derives the GraphQL type of a variable from its Go type.
+func variableType(t reflect.Type) (string, error) {
+	nullable := t.Kind() == reflect.Pointer
+	if nullable {
+		t = t.Elem()
+	}
+	var typ string
+	switch {
+	case t.Implements(typerType):
+		typ = reflect.Zero(t).Interface().(GraphQLTyper).GraphQLType()
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8:
+		elem, err := variableType(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		typ = "[" + elem + "]"
+	case t.PkgPath() != "" && t.Name() != "":
+		typ = t.Name()
+	case t.Kind() == reflect.String, t.Kind() == reflect.Slice:
+		typ = "String"
+	case t.Kind() == reflect.Bool:
+		typ = "Boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		typ = "Int"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		typ = "Float"
+	default:
+		return "", fmt.Errorf("cannot infer the GraphQL type of %s; implement GraphQLTyper", t)
+	}
+	if !nullable {
+		typ += "!"
+	}
+	return typ, nil
+}
+
+// writeSelectionSet writes the selection set of the struct type t.
+// seen holds the struct types being written, to reject recursive types.
+func writeSelectionSet(sb *strings.Builder, t reflect.Type, seen []reflect.Type) error {
+	for _, s := range seen {
+		if s == t {
+			return fmt.Errorf("graphql: %s refers to itself", t)
+		}
+	}
+	sb.WriteString("{")
+	first := true
+	if err := writeFields(sb, t, append(seen, t), &first); err != nil {
+		return err
+	}
+	sb.WriteString("}")
+	return nil
+}
+
+func writeFields(sb *strings.Builder, t reflect.Type, seen []reflect.Type, first *bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("graphql")
+		if !f.IsExported() && !(f.Anonymous && f.Type.Kind() == reflect.Struct) || tag == "-" {
+			continue
+		}
+		if f.Anonymous && !tagged {
+			if embedded := selectionType(f.Type); embedded != nil {
+				if err := writeFields(sb, embedded, seen, first); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !*first {
+			sb.WriteString(" ")
+		}
+		*first = false
+		if tagged {
+			sb.WriteString(strings.TrimSpace(tag))
+		} else {
+			sb.WriteString(fieldName(f.Name))
+		}
+		if sub := selectionType(f.Type); sub != nil {
+			if err := writeSelectionSet(sb, sub, seen); err != nil {
+				return err
+			}
+		} else if strings.HasPrefix(tag, "...") {
+			return fmt.Errorf("graphql: inline fragment %s.%s must be a struct", t, f.Name)
+		}
+	}
+	return nil
+}
+
+// selectionType gets the struct type whose fields are selected for a
+// field of type t, or nil if t is a leaf value.
+func selectionType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || isLeaf(t) {
+		return nil
+	}
+	return t
+}
+
+// isLeaf reports whether t decodes itself, like time.Time.
+func isLeaf(t reflect.Type) bool {
+	p := reflect.PointerTo(t)
+	return p.Implements(jsonUnmarshalerType) || p.Implements(textUnmarshalerType)
+}
+
+// fieldName converts a Go field name such as AvatarURL to a GraphQL
+// field name such as avatarUrl.
+func fieldName(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		boundary := i == len(runes) ||
+			unicode.IsLower(runes[i-1]) && unicode.IsUpper(runes[i]) ||
+			i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i+1])
+		if !boundary {
+			continue
+		}
+		word := strings.ToLower(string(runes[start:i]))
+		if start > 0 {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		sb.WriteString(word)
+		start = i
+	}
+	return sb.String()
+}
+
+// responseKey gets the key of a field in the response from its graphql
+// tag, such as "admin" for "admin: user(id: 1) @include(if: $a)".
+func responseKey(tag string) string {
+	if i := strings.IndexAny(tag, "(@"); i >= 0 {
+		tag = tag[:i]
+	}
+	if i := strings.Index(tag, ":"); i >= 0 {
+		tag = tag[:i]
+	}
+	return strings.TrimSpace(tag)
+}
@@ -0,0 +1,146 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type role string
+
+type dateTime time.Time
+
+func (dateTime) GraphQLType() string { return "DateTime" }
+
+func TestConstructQuery(t *testing.T) {
+	type userFields struct {
+		ID   ID
+		Name string
+	}
+	var q struct {
+		User struct {
+			userFields
+			AvatarURL string `graphql:"avatarUrl(size: 64)"`
+			Admin     *struct {
+				Level int
+			} `graphql:"... on Admin"`
+			Friends []struct {
+				Name string
+			} `graphql:"friends(first: $first, filter: {role: $role})"`
+		} `graphql:"user(id: $id)"`
+		Me struct {
+			Name string
+		} `graphql:"me: user(id: 1) @include(if: $me)"`
+		Ignored string `graphql:"-"`
+	}
+	got, err := ConstructQuery(&q, map[string]any{
+		"id":    ID("1"),
+		"first": (*int)(nil),
+		"role":  []role{"ADMIN"},
+		"me":    true,
+		"since": dateTime{},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `query($first: Int, $id: ID!, $me: Boolean!, $role: [role!]!, $since: DateTime!) ` +
+		`{user(id: $id){id name avatarUrl(size: 64) ... on Admin{level} friends(first: $first, filter: {role: $role}){name}} ` +
+		`me: user(id: 1) @include(if: $me){name}}`
+	if got != want {
+		t.Errorf("ConstructQuery got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestConstructQueryErrors(t *testing.T) {
+	type node struct {
+		Parent *node
+	}
+	if _, err := ConstructQuery(node{}, nil); err == nil {
+		t.Error("expected error for recursive struct")
+	}
+	if _, err := ConstructQuery(struct{ A string }{}, map[string]any{"m": map[string]any{}}); err == nil {
+		t.Error("expected error for map variable")
+	}
+	if _, err := ConstructQuery("{ a }", nil); err == nil {
+		t.Error("expected error for non-struct")
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	for name, want := range map[string]string{
+		"Name":      "name",
+		"ID":        "id",
+		"UserID":    "userId",
+		"AvatarURL": "avatarUrl",
+		"HTMLBody":  "htmlBody",
+	} {
+		if got := fieldName(name); got != want {
+			t.Errorf("fieldName(%q) got %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestClientQuery(t *testing.T) {
+	var body struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		io.WriteString(w, `{"data":{"nodes":[
+			{"__typename":"User","id":"1","name":"Ada","joined":"2024-01-02T03:04:05Z"},
+			{"__typename":"Bot","id":"2","model":"x"}
+		],"first":null}}`)
+	}))
+	defer srv.Close()
+
+	var q struct {
+		Nodes []struct {
+			Typename string `graphql:"__typename"`
+			ID       ID
+			User     *struct {
+				Name   string
+				Joined time.Time
+			} `graphql:"... on User"`
+			Bot struct {
+				Model string
+			} `graphql:"... on Bot"`
+		} `graphql:"nodes(ids: $ids)"`
+		First *struct {
+			ID ID
+		} `graphql:"first: node(id: 1)"`
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	client := NewClient(srv.URL)
+	if err := client.Query(ctx, &q, map[string]any{"ids": []ID{"1", "2"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `query($ids: [ID!]!) {nodes(ids: $ids){__typename id ... on User{name joined} ... on Bot{model}} first: node(id: 1){id}}`; body.Query != want {
+		t.Errorf("query got %s, want %s", body.Query, want)
+	}
+	if len(q.Nodes) != 2 {
+		t.Fatalf("nodes got %d, want 2", len(q.Nodes))
+	}
+	if u := q.Nodes[0].User; u == nil || u.Name != "Ada" || u.Joined.Year() != 2024 {
+		t.Errorf("nodes[0].User got %+v", u)
+	}
+	if q.Nodes[1].User != nil {
+		t.Errorf("nodes[1].User got %+v, want nil", q.Nodes[1].User)
+	}
+	if got, want := q.Nodes[1].Bot.Model, "x"; got != want {
+		t.Errorf("nodes[1].Bot.Model got %q, want %q", got, want)
+	}
+	if q.First != nil {
+		t.Errorf("first got %+v, want nil", q.First)
+	}
+	if err := client.Query(ctx, q, nil); err == nil {
+		t.Error("expected error for non-pointer")
+	}
+}
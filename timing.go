@@ -0,0 +1,126 @@
+package graphql
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing breaks down where the time of a request went. Phases that did
+// not happen, such as connecting when a connection was reused, are
+// zero. If a request is sent more than once, such as with a refreshed
+// token or to another endpoint, the phases are those of the last
+// attempt while Total covers them all.
+type Timing struct {
+	// DNS is the time spent looking up the host.
+	DNS time.Duration
+	// Connect is the time spent making the TCP connection.
+	Connect time.Duration
+	// TLS is the time spent in the TLS handshake.
+	TLS time.Duration
+	// TTFB is the time from starting the request to the first byte of
+	// the response, including the phases above.
+	TTFB time.Duration
+	// Download is the time from the first byte to the end of the body.
+	Download time.Duration
+	// Decode is the time spent decoding the body, not counting time
+	// waiting for it to arrive.
+	Decode time.Duration
+	// Total is the time the whole request took.
+	Total time.Duration
+	// ReusedConn reports whether an idle connection was reused.
+	ReusedConn bool
+}
+
+// timer collects the times of a request from httptrace callbacks, which
+// may be called concurrently. Callbacks of an earlier attempt are
+// ignored.
+type timer struct {
+	mu                     sync.Mutex
+	attempt                int
+	start                  time.Time
+	dnsStart, connectStart time.Time
+	tlsStart, firstByte    time.Time
+	timing                 Timing
+}
+
+// trace starts an attempt and returns ctx with a ClientTrace that
+// records into t.
+func (t *timer) trace(ctx context.Context) context.Context {
+	t.mu.Lock()
+	t.attempt++
+	attempt := t.attempt
+	t.start = time.Now()
+	t.dnsStart, t.connectStart, t.tlsStart, t.firstByte = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+	t.timing = Timing{}
+	t.mu.Unlock()
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mark(attempt, &t.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.since(attempt, &t.timing.DNS, &t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mark(attempt, &t.connectStart)
+		},
+		ConnectDone: func(string, string, error) {
+			t.since(attempt, &t.timing.Connect, &t.connectStart)
+		},
+		TLSHandshakeStart: func() {
+			t.mark(attempt, &t.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.since(attempt, &t.timing.TLS, &t.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			if attempt == t.attempt {
+				t.timing.ReusedConn = info.Reused
+			}
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.mark(attempt, &t.firstByte)
+			t.since(attempt, &t.timing.TTFB, &t.start)
+		},
+	})
+}
+
+func (t *timer) mark(attempt int, at *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if attempt == t.attempt {
+		*at = time.Now()
+	}
+}
+
+func (t *timer) since(attempt int, d *time.Duration, start *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if attempt == t.attempt && !start.IsZero() {
+		*d = time.Since(*start)
+	}
+}
+
+// bodyRead records that the body has been read, after decoding that
+// took decode.
+func (t *timer) bodyRead(decode time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.firstByte.IsZero() {
+		t.timing.Download = time.Since(t.firstByte)
+	}
+	t.timing.Decode = decode
+}
+
+// result returns the timing of a request that started at start.
+func (t *timer) result(start time.Time) Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timing := t.timing
+	timing.Total = time.Since(start)
+	return timing
+}
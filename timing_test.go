@@ -0,0 +1,100 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type timingMetrics struct {
+	Metrics
+	timing Timing
+}
+
+func (m *timingMetrics) ObserveRequest(r RequestMetrics) {
+	m.timing = r.Timing
+}
+
+func TestTiming(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		io.WriteString(w, `{"data":{"items":[`)
+		w.(http.Flusher).Flush()
+		time.Sleep(delay)
+		io.WriteString(w, `1,2,3]}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	metrics := &timingMetrics{}
+	client := NewClient(srv.URL, WithHTTPClient(srv.Client()), WithMetrics(metrics))
+	var resp Response
+	if err := client.Run(ctx, NewRequest("query {}"), &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	timing := resp.Timing
+	if timing.ReusedConn || timing.Connect <= 0 || timing.TLS <= 0 {
+		t.Errorf("got %+v, want a new connection with a TLS handshake", timing)
+	}
+	// When the first byte is noticed depends on scheduling, but the two
+	// delays are always covered.
+	if timing.TTFB < delay || timing.Download <= 0 || timing.TTFB+timing.Download < 2*delay {
+		t.Errorf("got TTFB %v and download %v, want at least %v in all", timing.TTFB, timing.Download, 2*delay)
+	}
+	if timing.Total < timing.TTFB+timing.Download {
+		t.Errorf("total got %v, want at least TTFB and download", timing.Total)
+	}
+	if timing.Decode >= delay {
+		t.Errorf("decode got %v, want it not to count waiting for the body", timing.Decode)
+	}
+	if metrics.timing != timing {
+		t.Errorf("metrics got %+v, want %+v", metrics.timing, timing)
+	}
+
+	if err := client.Run(ctx, NewRequest("query {}"), &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Timing.ReusedConn || resp.Timing.Connect != 0 || resp.Timing.TLS != 0 {
+		t.Errorf("got %+v, want a reused connection", resp.Timing)
+	}
+}
+
+func TestTimingRetry(t *testing.T) {
+	var requests int
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	tokens := TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		return &Token{AccessToken: "t"}, nil
+	})
+	client := NewClient(srv.URL, WithHTTPClient(srv.Client()), WithTokenSource(tokens))
+	var resp Response
+	if err := client.Run(ctx, NewRequest("query {}"), &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("got %d requests, want 2", requests)
+	}
+	// The second attempt reuses the connection of the first, whose
+	// handshake must not be reported.
+	timing := resp.Timing
+	if !timing.ReusedConn || timing.Connect != 0 || timing.TLS != 0 {
+		t.Errorf("got %+v, want the timing of the second attempt", timing)
+	}
+	if timing.Total < timing.TTFB {
+		t.Errorf("total %v got less than TTFB %v", timing.Total, timing.TTFB)
+	}
+}
@@ -0,0 +1,216 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Attribute keys of spans, following the OpenTelemetry semantic
+// conventions where they define one.
+const (
+	AttrOperationName    = "graphql.operation.name"
+	AttrOperationType    = "graphql.operation.type"
+	AttrDocumentHash     = "graphql.document.hash"
+	AttrErrorCount       = "graphql.error.count"
+	AttrStatusCode       = "http.response.status_code"
+	AttrRequestBodySize  = "http.request.body.size"
+	AttrResponseBodySize = "http.response.body.size"
+	AttrServerAddress    = "server.address"
+)
+
+// Attribute is a key and value describing a span.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Tracer starts a span for every request, so that requests can be
+// traced without this package depending on a tracing library. To use
+// OpenTelemetry, implement Tracer with an otel trace.Tracer, and store
+// the span's context with ContextWithSpanContext so that it is
+// propagated.
+type Tracer interface {
+	// Start starts a span named name with the attributes attrs, as a
+	// child of any span in ctx. The returned context is used for the
+	// request.
+	Start(ctx context.Context, name string, attrs []Attribute) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// SetAttributes adds attributes to the span.
+	SetAttributes(attrs ...Attribute)
+	// End ends the span. err is the error returned for the request, if
+	// any.
+	End(err error)
+}
+
+// WithTracer traces every request with tracer. Spans are named after
+// the operation, such as "query GetUser", and have the attributes:
+//
+//   - graphql.operation.name and graphql.operation.type
+//   - graphql.document.hash, the hex SHA-256 of the query
+//   - server.address
+//
+// When the request completes, these are added:
+//
+//   - graphql.error.count
+//   - http.response.status_code
+//   - http.request.body.size and http.response.body.size
+func WithTracer(tracer Tracer) ClientOption {
+	return func(client *Client) {
+		client.tracer = tracer
+	}
+}
+
+// Propagator sets headers that carry the trace context in ctx to the
+// server.
+type Propagator interface {
+	Inject(ctx context.Context, h http.Header)
+}
+
+// WithPropagator sets the headers of every request with propagator.
+// Headers already set on the Request or with WithHeader are kept.
+//
+//	NewClient(endpoint, WithTracer(tracer), WithPropagator(TraceContext{}))
+func WithPropagator(propagator Propagator) ClientOption {
+	return func(client *Client) {
+		client.propagator = propagator
+	}
+}
+
+// SpanContext identifies a span in a trace, as in the W3C Trace Context
+// recommendation.
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceFlags byte
+	// TraceState is the vendor-specific tracestate header value.
+	TraceState string
+}
+
+// IsValid reports whether the trace and span IDs are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// Sampled reports whether the sampled flag is set.
+func (sc SpanContext) Sampled() bool {
+	return sc.TraceFlags&0x01 != 0
+}
+
+// Traceparent formats sc as a traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]), sc.TraceFlags)
+}
+
+// ParseTraceparent parses a traceparent header value.
+func ParseTraceparent(s string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, fmt.Errorf("graphql: invalid traceparent %q", s)
+	}
+	version, err := hex.DecodeString(parts[0])
+	switch {
+	case err != nil, version[0] == 0xff:
+		return sc, fmt.Errorf("graphql: invalid traceparent version %q", parts[0])
+	case version[0] == 0 && len(parts) != 4:
+		return sc, fmt.Errorf("graphql: invalid traceparent %q", s)
+	}
+	if parts[1] != strings.ToLower(parts[1]) || parts[2] != strings.ToLower(parts[2]) {
+		return sc, fmt.Errorf("graphql: invalid traceparent %q", s)
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, fmt.Errorf("graphql: invalid trace ID %q", parts[1])
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, fmt.Errorf("graphql: invalid span ID %q", parts[2])
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return sc, fmt.Errorf("graphql: invalid trace flags %q", parts[3])
+	}
+	sc.TraceFlags = flags[0]
+	if !sc.IsValid() {
+		return sc, errors.New("graphql: traceparent has a zero trace or span ID")
+	}
+	return sc, nil
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of ctx carrying sc, for
+// TraceContext to propagate.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the SpanContext in ctx, if any.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// TraceContext is a Propagator that sets the W3C traceparent and
+// tracestate headers from the SpanContext in the context.
+type TraceContext struct{}
+
+// Inject sets the traceparent and tracestate headers of h.
+func (TraceContext) Inject(ctx context.Context, h http.Header) {
+	sc, ok := SpanContextFromContext(ctx)
+	if !ok {
+		return
+	}
+	h.Set("Traceparent", sc.Traceparent())
+	if sc.TraceState != "" {
+		h.Set("Tracestate", sc.TraceState)
+	}
+}
+
+// Extract reads the SpanContext from the traceparent and tracestate
+// headers of h.
+func (TraceContext) Extract(h http.Header) (SpanContext, error) {
+	sc, err := ParseTraceparent(h.Get("Traceparent"))
+	if err != nil {
+		return sc, err
+	}
+	sc.TraceState = h.Get("Tracestate")
+	return sc, nil
+}
+
+// spanName names the span of an operation as the OpenTelemetry
+// conventions do.
+func spanName(typ, name string) string {
+	switch {
+	case typ == "":
+		return "GraphQL Operation"
+	case name == "":
+		return typ
+	}
+	return typ + " " + name
+}
+
+func documentHash(q string) string {
+	sum := sha256.Sum256([]byte(q))
+	return hex.EncodeToString(sum[:])
+}
+
+// inject sets the propagation headers of r that are not set yet.
+func (c *Client) inject(ctx context.Context, r *http.Request) {
+	if c.propagator == nil {
+		return
+	}
+	h := make(http.Header)
+	c.propagator.Inject(ctx, h)
+	for key, values := range h {
+		if _, ok := r.Header[key]; !ok {
+			r.Header[key] = values
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type recordedSpan struct {
+	name  string
+	attrs map[string]any
+	ended bool
+	err   error
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+// recordingTracer records spans and puts a fixed SpanContext in the
+// context.
+type recordingTracer struct {
+	spans []*recordedSpan
+	sc    SpanContext
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs []Attribute) (context.Context, Span) {
+	s := &recordedSpan{name: name, attrs: make(map[string]any)}
+	s.SetAttributes(attrs...)
+	t.spans = append(t.spans, s)
+	return ContextWithSpanContext(ctx, t.sc), s
+}
+
+func TestWithTracer(t *testing.T) {
+	var traceparent, tracestate string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		tracestate = r.Header.Get("tracestate")
+		io.WriteString(w, `{"data":null,"errors":[{"message":"boom"}]}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sc.TraceState = "vendor=value"
+	tracer := &recordingTracer{sc: sc}
+	client := NewClient(srv.URL, WithTracer(tracer), WithPropagator(TraceContext{}))
+	q := `query GetUser { user { name } }`
+	err = client.Run(ctx, NewRequest(q), nil)
+	if err == nil {
+		t.Fatalf("want an error")
+	}
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != "query GetUser" {
+		t.Errorf("name got %q, want query GetUser", span.name)
+	}
+	want := map[string]any{
+		AttrOperationName:    "GetUser",
+		AttrOperationType:    "query",
+		AttrDocumentHash:     documentHash(q),
+		AttrServerAddress:    "127.0.0.1",
+		AttrErrorCount:       1,
+		AttrStatusCode:       http.StatusOK,
+		AttrResponseBodySize: int64(len(`{"data":null,"errors":[{"message":"boom"}]}`)),
+	}
+	for key, value := range want {
+		if span.attrs[key] != value {
+			t.Errorf("%s got %v, want %v", key, span.attrs[key], value)
+		}
+	}
+	if !span.ended || span.err != err {
+		t.Errorf("got ended %v with %v, want ended with %v", span.ended, span.err, err)
+	}
+	if traceparent != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" || tracestate != "vendor=value" {
+		t.Errorf("got traceparent %q and tracestate %q", traceparent, tracestate)
+	}
+}
+
+func TestWithPropagatorKeepsHeader(t *testing.T) {
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	sc := SpanContext{TraceID: [16]byte{1}, SpanID: [8]byte{2}}
+	ctx = ContextWithSpanContext(ctx, sc)
+	client := NewClient(srv.URL, WithPropagator(TraceContext{}))
+	if err := client.Run(ctx, NewRequest("query {}"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if traceparent != sc.Traceparent() {
+		t.Errorf("traceparent got %q, want %q", traceparent, sc.Traceparent())
+	}
+	req := NewRequest("query {}")
+	req.Header.Set("Traceparent", "mine")
+	if err := client.Run(ctx, req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if traceparent != "mine" {
+		t.Errorf("traceparent got %q, want the Request's", traceparent)
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		s       string
+		wantErr bool
+	}{
+		{s: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{s: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future"},
+		{s: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", wantErr: true},
+		{s: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantErr: true},
+		{s: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", wantErr: true},
+		{s: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", wantErr: true},
+		{s: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", wantErr: true},
+		{s: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		sc, err := ParseTraceparent(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTraceparent(%q) got error %v, want error %v", tt.s, err, tt.wantErr)
+			continue
+		}
+		if err == nil && tt.s[:2] == "00" && sc.Traceparent() != tt.s {
+			t.Errorf("Traceparent got %q, want %q", sc.Traceparent(), tt.s)
+		}
+	}
+}
+
+func TestTraceContextExtract(t *testing.T) {
+	h := make(http.Header)
+	sc := SpanContext{TraceID: [16]byte{1}, SpanID: [8]byte{2}, TraceFlags: 1, TraceState: "a=b"}
+	TraceContext{}.Inject(ContextWithSpanContext(context.Background(), sc), h)
+	got, err := TraceContext{}.Extract(h)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != sc || !got.Sampled() {
+		t.Errorf("got %+v, want %+v", got, sc)
+	}
+}
@@ -0,0 +1,73 @@
+package graphql
+
+import (
+	"github.com/razzkumar/go-graphql/language"
+	"github.com/razzkumar/go-graphql/schema"
+	"github.com/razzkumar/go-graphql/validator"
+)
+
+// WithSchemaValidation validates every Request against s before it is
+// sent, including its variables as WithVariableValidation does. Requests
+// that fail validation are never sent to the server; Run returns the
+// validator.Errors instead.
+//
+//	s, err := schema.LoadFile("schema.graphql")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	client := graphql.NewClient(endpoint, graphql.WithSchemaValidation(s))
+func WithSchemaValidation(s *schema.Schema) ClientOption {
+	return func(client *Client) {
+		client.schema = s
+	}
+}
+
+// WithVariableValidation checks the variables of every Request against
+// the variable definitions of its query before it is sent: required
+// variables must be given, undeclared variables are rejected and values
+// must fit the built-in scalar and list types. Declared defaults are
+// sent for variables that are missing, leaving the Request unchanged.
+// Run returns the validator.Errors instead of sending an invalid
+// request.
+//
+// Without a schema from WithSchemaValidation, enums, input objects and
+// custom scalars are not checked.
+func WithVariableValidation() ClientOption {
+	return func(client *Client) {
+		client.validateVariables = true
+	}
+}
+
+// validate checks req and returns the request to send, which has the
+// defaults of missing variables.
+func (c *Client) validate(req *Request) (*Request, error) {
+	if c.schema != nil {
+		if errs := validator.ValidateQuery(c.schema, req.q); len(errs) > 0 {
+			c.logf(">> validation failed: %v", errs)
+			return nil, errs
+		}
+	}
+	if c.schema == nil && !c.validateVariables {
+		return req, nil
+	}
+	doc, err := language.ParseQuery(req.q)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.Operations) != 1 {
+		// The operation to run cannot be told apart without an
+		// operation name.
+		return req, nil
+	}
+	vars, errs := validator.ValidateVariables(c.schema, doc.Operations[0], req.vars)
+	if len(errs) > 0 {
+		c.logf(">> variable validation failed: %v", errs)
+		return nil, errs
+	}
+	if len(vars) == 0 {
+		return req, nil
+	}
+	validated := *req
+	validated.vars = vars
+	return &validated, nil
+}
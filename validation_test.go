@@ -0,0 +1,132 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/razzkumar/go-graphql/schema"
+	"github.com/razzkumar/go-graphql/validator"
+)
+
+func TestWithSchemaValidation(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.WriteString(w, `{"data":{"user":{"name":"matryer"}}}`)
+	}))
+	defer srv.Close()
+
+	s, err := schema.LoadSDL(`
+		type User { name: String }
+		type Query { user(id: ID!): User }
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL, WithSchemaValidation(s))
+
+	err = client.Run(ctx, NewRequest(`{ user { email } }`), nil)
+	var errs validator.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("err got %v, want validator.Errors", err)
+	}
+	if got, want := len(errs), 2; got != want {
+		t.Errorf("len(errs) got %v, want %v: %v", got, want, errs)
+	}
+	if calls != 0 {
+		t.Errorf("calls got %v, want %v", calls, 0)
+	}
+
+	req := NewRequest(`query ($id: ID!) { user(id: $id) { name } }`)
+	req.Var("id", "1")
+	var resp struct {
+		User struct {
+			Name string
+		}
+	}
+	if err := client.Run(ctx, req, &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls got %v, want %v", calls, 1)
+	}
+	if got, want := resp.User.Name, "matryer"; got != want {
+		t.Errorf("resp.User.Name got %v, want %v", got, want)
+	}
+}
+
+func TestWithVariableValidation(t *testing.T) {
+	var vars map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		vars = body.Variables
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL, WithVariableValidation())
+	query := `query ($id: ID!, $first: Int = 10) { user(id: $id) { friends(first: $first) { name } } }`
+
+	req := NewRequest(query)
+	req.Var("id", []int{1})
+	err := client.Run(ctx, req, nil)
+	var errs validator.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("err got %v, want validator.Errors", err)
+	}
+	if vars != nil {
+		t.Errorf("request was sent with variables %v", vars)
+	}
+
+	req = NewRequest(query)
+	req.Var("id", "1")
+	if err := client.Run(ctx, req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := vars["first"], 10.0; got != want {
+		t.Errorf("first got %v, want %v", got, want)
+	}
+	if _, ok := req.Vars()["first"]; ok {
+		t.Errorf("the default was set on the request, want it unchanged")
+	}
+}
+
+func TestVariableValidationConcurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithVariableValidation())
+	req := NewRequest(`query ($id: ID!, $first: Int = 10) { user(id: $id) { friends(first: $first) { name } } }`)
+	req.Var("id", "1")
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.Run(ctx, req, nil); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
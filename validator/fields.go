@@ -0,0 +1,195 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/razzkumar/go-graphql/language"
+	"github.com/razzkumar/go-graphql/schema"
+)
+
+// walkSelectionSet validates set as a selection on parent. A nil
+// parent means the type is unknown (an error has already been
+// reported); the selections are still walked to collect variables.
+func (v *validator) walkSelectionSet(w *walk, parent *schema.Type, set language.SelectionSet) {
+	if parent != nil {
+		v.checkOverlappingFields(parent, set)
+	}
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *language.Field:
+			v.walkField(w, parent, sel)
+		case *language.InlineFragment:
+			v.walkDirectives(w, sel.Directives, language.LocationInlineFragment)
+			t := parent
+			if sel.TypeCondition != "" {
+				t = v.fragmentType(sel.TypeCondition, sel.Position)
+				if t != nil && parent != nil && !v.typesOverlap(parent, t) {
+					v.report("PossibleFragmentSpreads", at(sel.Position), "Fragment cannot be spread here as objects of type %q can never be of type %q.", parent.Name, t.Name)
+				}
+			}
+			v.walkSelectionSet(w, t, sel.SelectionSet)
+		case *language.FragmentSpread:
+			v.walkDirectives(w, sel.Directives, language.LocationFragmentSpread)
+			w.spreads = append(w.spreads, sel.Name)
+			frag, ok := v.fragments[sel.Name]
+			if !ok {
+				v.report("KnownFragmentNames", at(sel.Position), "Unknown fragment %q.", sel.Name)
+				continue
+			}
+			t, ok := v.schema.Types[frag.TypeCondition]
+			if ok && t.IsComposite() && parent != nil && !v.typesOverlap(parent, t) {
+				v.report("PossibleFragmentSpreads", at(sel.Position), "Fragment %q cannot be spread here as objects of type %q can never be of type %q.", sel.Name, parent.Name, t.Name)
+			}
+		}
+	}
+}
+
+// fragmentType looks up the type condition of an inline fragment.
+func (v *validator) fragmentType(name string, pos language.Position) *schema.Type {
+	t, ok := v.schema.Types[name]
+	if !ok {
+		v.report("KnownTypeNames", at(pos), "Unknown type %q.", name)
+		return nil
+	}
+	if !t.IsComposite() {
+		v.report("FragmentsOnCompositeTypes", at(pos), "Fragment cannot condition on non composite type %q.", name)
+		return nil
+	}
+	return t
+}
+
+// typesOverlap reports whether some object type is a possible type of
+// both a and b.
+func (v *validator) typesOverlap(a, b *schema.Type) bool {
+	if a.Name == b.Name {
+		return true
+	}
+	for _, pa := range v.schema.PossibleTypes(a) {
+		for _, pb := range v.schema.PossibleTypes(b) {
+			if pa.Name == pb.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldDefinition finds the definition of the named field on parent,
+// including the introspection meta fields.
+func (v *validator) fieldDefinition(parent *schema.Type, name string) *schema.Field {
+	if name == schema.TypenameMetaField.Name && parent.IsComposite() {
+		return schema.TypenameMetaField
+	}
+	if parent == v.schema.Query {
+		switch name {
+		case schema.SchemaMetaField.Name:
+			return schema.SchemaMetaField
+		case schema.TypeMetaField.Name:
+			return schema.TypeMetaField
+		}
+	}
+	return parent.Field(name)
+}
+
+func (v *validator) walkField(w *walk, parent *schema.Type, f *language.Field) {
+	var def *schema.Field
+	if parent != nil {
+		def = v.fieldDefinition(parent, f.Name)
+		if def == nil {
+			v.report("FieldsOnCorrectType", at(f.Position), "Cannot query field %q on type %q.", f.Name, parent.Name)
+		}
+	}
+	v.walkDirectives(w, f.Directives, language.LocationField)
+	if def == nil {
+		v.walkArguments(w, f.Arguments, nil, "")
+		v.walkSelectionSet(w, nil, f.SelectionSet)
+		return
+	}
+	v.walkArguments(w, f.Arguments, def.Args, fmt.Sprintf("field %q", parent.Name+"."+f.Name))
+	v.checkRequiredArguments(f.Arguments, def.Args, f.Position, fmt.Sprintf("Field %q", f.Name))
+	t, ok := v.schema.Types[def.Type.Name()]
+	if !ok {
+		v.walkSelectionSet(w, nil, f.SelectionSet)
+		return
+	}
+	if t.IsLeaf() {
+		if len(f.SelectionSet) > 0 {
+			v.report("ScalarLeafs", at(f.Position), "Field %q must not have a selection since type %q has no subfields.", f.Name, def.Type)
+			v.walkSelectionSet(w, nil, f.SelectionSet)
+		}
+		return
+	}
+	if len(f.SelectionSet) == 0 {
+		v.report("ScalarLeafs", at(f.Position), "Field %q of type %q must have a selection of subfields. Did you mean \"%s { ... }\"?", f.Name, def.Type, f.Name)
+		return
+	}
+	v.walkSelectionSet(w, t, f.SelectionSet)
+}
+
+// walkArguments validates args against their definitions. A nil defs
+// with an empty owner means the definitions are unknown.
+func (v *validator) walkArguments(w *walk, args []*language.Argument, defs []*schema.InputValue, owner string) {
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		if seen[arg.Name] {
+			v.report("UniqueArgumentNames", at(arg.Position), "There can be only one argument named %q.", arg.Name)
+		}
+		seen[arg.Name] = true
+		if owner == "" {
+			v.walkValue(w, arg.Value, nil, false)
+			continue
+		}
+		var def *schema.InputValue
+		for _, d := range defs {
+			if d.Name == arg.Name {
+				def = d
+				break
+			}
+		}
+		if def == nil {
+			v.report("KnownArgumentNames", at(arg.Position), "Unknown argument %q on %s.", arg.Name, owner)
+			v.walkValue(w, arg.Value, nil, false)
+			continue
+		}
+		v.walkValue(w, arg.Value, def.Type, def.DefaultValue != nil)
+	}
+}
+
+func (v *validator) checkRequiredArguments(args []*language.Argument, defs []*schema.InputValue, pos language.Position, owner string) {
+	for _, def := range defs {
+		if !def.Type.NonNull || def.DefaultValue != nil {
+			continue
+		}
+		provided := false
+		for _, arg := range args {
+			if arg.Name == def.Name {
+				provided = true
+				break
+			}
+		}
+		if !provided {
+			v.report("ProvidedRequiredArguments", at(pos), "%s argument %q of type %q is required, but it was not provided.", owner, def.Name, def.Type)
+		}
+	}
+}
+
+func (v *validator) walkDirectives(w *walk, directives []*language.Directive, loc language.DirectiveLocation) {
+	seen := make(map[string]bool)
+	for _, d := range directives {
+		def, ok := v.schema.Directives[d.Name]
+		if !ok {
+			v.report("KnownDirectives", at(d.Position), "Unknown directive \"@%s\".", d.Name)
+			v.walkArguments(w, d.Arguments, nil, "")
+			continue
+		}
+		if !def.HasLocation(loc) {
+			v.report("KnownDirectives", at(d.Position), "Directive \"@%s\" may not be used on %s.", d.Name, loc)
+		}
+		if seen[d.Name] && !def.IsRepeatable {
+			v.report("UniqueDirectivesPerLocation", at(d.Position), "The directive \"@%s\" can only be used once at this location.", d.Name)
+		}
+		seen[d.Name] = true
+		v.walkArguments(w, d.Arguments, def.Args, fmt.Sprintf("directive \"@%s\"", d.Name))
+		v.checkRequiredArguments(d.Arguments, def.Args, d.Position, fmt.Sprintf("Directive \"@%s\"", d.Name))
+	}
+}
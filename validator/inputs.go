@@ -0,0 +1,275 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/razzkumar/go-graphql/language"
+	"github.com/razzkumar/go-graphql/schema"
+)
+
+// ValidateVariables checks the variable values vars against the
+// variable definitions of op, and returns the values with the declared
+// defaults of missing variables applied. It reports required variables
+// that are missing, variables op does not declare, and values that
+// cannot be coerced to their declared type. Values are compared in
+// their JSON form, so structs and other Go types are checked the way
+// the server will see them.
+//
+// s may be nil, in which case only list shapes and the built-in
+// scalars are checked; enums, input objects and custom scalars accept
+// any value.
+func ValidateVariables(s *schema.Schema, op *language.OperationDefinition, vars map[string]any) (map[string]any, Errors) {
+	var errs Errors
+	report := func(pos language.Position, format string, args ...any) {
+		errs = append(errs, &Error{
+			Message:   fmt.Sprintf(format, args...),
+			Locations: []language.Position{pos},
+			Rule:      "VariableValues",
+		})
+	}
+
+	declared := make(map[string]bool)
+	result := make(map[string]any, len(vars))
+	for k, v := range vars {
+		result[k] = v
+	}
+	for _, def := range op.VariableDefinitions {
+		declared[def.Variable] = true
+		value, ok := vars[def.Variable]
+		if !ok {
+			switch {
+			case def.DefaultValue != nil:
+				result[def.Variable] = literalValue(def.DefaultValue)
+			case def.Type.NonNull:
+				report(def.Position, "Variable \"$%s\" of required type %q was not provided.", def.Variable, def.Type)
+			}
+			continue
+		}
+		normal, err := normalize(value)
+		if err != nil {
+			report(def.Position, "Variable \"$%s\" got invalid value: %v.", def.Variable, err)
+			continue
+		}
+		if normal == nil && def.Type.NonNull {
+			report(def.Position, "Variable \"$%s\" of non-null type %q must not be null.", def.Variable, def.Type)
+			continue
+		}
+		c := &inputChecker{schema: s}
+		c.check(def.Variable, normal, def.Type)
+		for _, p := range c.problems {
+			if p.path == def.Variable {
+				report(def.Position, "Variable \"$%s\" got invalid value %s; %s", def.Variable, jsonString(p.value), p.message)
+			} else {
+				report(def.Position, "Variable \"$%s\" got invalid value %s at %q; %s", def.Variable, jsonString(p.value), p.path, p.message)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		if !declared[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if op.Name != "" {
+			report(op.Position, "Variable \"$%s\" is not defined by operation %q.", name, op.Name)
+		} else {
+			report(op.Position, "Variable \"$%s\" is not defined by the operation.", name)
+		}
+	}
+	return result, errs
+}
+
+// normalize converts v to the generic form encoding/json would decode
+// it to, with numbers kept as json.Number.
+func normalize(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func jsonString(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// literalValue converts a constant literal to the value sent as a
+// variable.
+func literalValue(val *language.Value) any {
+	switch val.Kind {
+	case language.IntValue, language.FloatValue:
+		return json.Number(val.Raw)
+	case language.StringValue, language.BlockValue, language.EnumValue:
+		return val.Raw
+	case language.BooleanValue:
+		return val.Raw == "true"
+	case language.ListValue:
+		list := make([]any, len(val.Children))
+		for i, child := range val.Children {
+			list[i] = literalValue(child.Value)
+		}
+		return list
+	case language.ObjectValue:
+		obj := make(map[string]any, len(val.Children))
+		for _, child := range val.Children {
+			obj[child.Name] = literalValue(child.Value)
+		}
+		return obj
+	}
+	return nil
+}
+
+type inputProblem struct {
+	path    string
+	value   any
+	message string
+}
+
+// inputChecker checks a normalized variable value against an input
+// type.
+type inputChecker struct {
+	schema   *schema.Schema
+	problems []inputProblem
+}
+
+func (c *inputChecker) problem(path string, value any, format string, args ...any) {
+	c.problems = append(c.problems, inputProblem{path, value, fmt.Sprintf(format, args...)})
+}
+
+func (c *inputChecker) check(path string, value any, t *language.Type) {
+	if value == nil {
+		if t.NonNull {
+			c.problem(path, value, "Expected non-nullable type %q not to be null.", t)
+		}
+		return
+	}
+	if t.Elem != nil {
+		list, ok := value.([]any)
+		if !ok {
+			// A single value is coerced to a list of one.
+			c.check(path, value, t.Elem)
+			return
+		}
+		for i, item := range list {
+			c.check(fmt.Sprintf("%s[%d]", path, i), item, t.Elem)
+		}
+		return
+	}
+	if msg := checkScalarValue(t.NamedType, value); msg != "" {
+		c.problem(path, value, "%s", msg)
+		return
+	}
+	if c.schema == nil {
+		return
+	}
+	named := c.schema.Types[t.NamedType]
+	if named == nil {
+		return
+	}
+	switch named.Kind {
+	case schema.Enum:
+		s, ok := value.(string)
+		switch {
+		case !ok:
+			c.problem(path, value, "Enum %q cannot represent non-string value: %s.", named.Name, jsonString(value))
+		case named.EnumValue(s) == nil:
+			c.problem(path, value, "Value %q does not exist in %q enum.", s, named.Name)
+		}
+	case schema.InputObject:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			c.problem(path, value, "Expected type %q to be an object.", named.Name)
+			return
+		}
+		for _, f := range named.InputFields {
+			fv, ok := obj[f.Name]
+			if !ok {
+				if f.Type.NonNull && f.DefaultValue == nil {
+					c.problem(path, value, "Field %q of required type %q was not provided.", f.Name, f.Type)
+				}
+				continue
+			}
+			c.check(path+"."+f.Name, fv, f.Type)
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if named.InputField(k) == nil {
+				c.problem(path, value, "Field %q is not defined by type %q.", k, named.Name)
+			}
+		}
+	case schema.Object, schema.Interface, schema.Union:
+		c.problem(path, value, "Type %q is not an input type.", named.Name)
+	}
+}
+
+// checkScalarValue checks a normalized value against a built-in scalar
+// and returns a description of the problem, or "" if it is valid.
+// Other types accept any value.
+func checkScalarValue(scalar string, value any) string {
+	switch scalar {
+	case "Int":
+		n, ok := value.(json.Number)
+		if !ok {
+			return "Int cannot represent non-integer value: " + jsonString(value)
+		}
+		i, err := strconv.ParseInt(string(n), 10, 64)
+		if err != nil {
+			f, ferr := strconv.ParseFloat(string(n), 64)
+			if ferr != nil || f != math.Trunc(f) {
+				return "Int cannot represent non-integer value: " + string(n)
+			}
+			i = int64(f)
+			if float64(i) != f {
+				return "Int cannot represent non 32-bit signed integer value: " + string(n)
+			}
+		}
+		if i > math.MaxInt32 || i < math.MinInt32 {
+			return "Int cannot represent non 32-bit signed integer value: " + string(n)
+		}
+	case "Float":
+		if _, ok := value.(json.Number); !ok {
+			return "Float cannot represent non numeric value: " + jsonString(value)
+		}
+	case "String":
+		if _, ok := value.(string); !ok {
+			return "String cannot represent a non string value: " + jsonString(value)
+		}
+	case "Boolean":
+		if _, ok := value.(bool); !ok {
+			return "Boolean cannot represent a non boolean value: " + jsonString(value)
+		}
+	case "ID":
+		switch v := value.(type) {
+		case string:
+		case json.Number:
+			if _, err := strconv.ParseInt(string(v), 10, 64); err != nil {
+				return "ID cannot represent value: " + string(v)
+			}
+		default:
+			return "ID cannot represent value: " + jsonString(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package validator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/razzkumar/go-graphql/language"
+)
+
+func TestValidateVariables(t *testing.T) {
+	s := mustSchema(t)
+	doc, err := language.ParseQuery(`query Users($filter: UserFilter, $ids: [ID!], $first: Int = 5, $term: String!) {
+		users(filter: $filter, ids: $ids) { friends(first: $first) { id } }
+		search(term: $term) { __typename }
+	}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	op := doc.Operations[0]
+
+	type filter struct {
+		Role  string `json:"role,omitempty"`
+		Limit int    `json:"limit"`
+	}
+	vars, errs := ValidateVariables(s, op, map[string]any{
+		"filter": filter{Role: "ADMIN", Limit: 3},
+		"ids":    []string{"1", "2"},
+		"term":   "a",
+	})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if got, want := vars["first"], json.Number("5"); got != want {
+		t.Errorf("first got %#v, want %#v", got, want)
+	}
+
+	tests := []struct {
+		vars map[string]any
+		want []string
+	}{
+		{
+			map[string]any{},
+			[]string{`Variable "$term" of required type "String!" was not provided.`},
+		},
+		{
+			map[string]any{"term": nil},
+			[]string{`Variable "$term" of non-null type "String!" must not be null.`},
+		},
+		{
+			map[string]any{"term": 1, "extra": true},
+			[]string{
+				`Variable "$term" got invalid value 1; String cannot represent a non string value: 1`,
+				`Variable "$extra" is not defined by operation "Users".`,
+			},
+		},
+		{
+			map[string]any{"term": "a", "first": 1.5, "ids": []any{"1", nil}},
+			[]string{
+				`Variable "$ids" got invalid value null at "ids[1]"; Expected non-nullable type "ID!" not to be null.`,
+				`Variable "$first" got invalid value 1.5; Int cannot represent non-integer value: 1.5`,
+			},
+		},
+		{
+			map[string]any{"term": "a", "filter": map[string]any{"role": "OWNER", "size": 1}},
+			[]string{
+				`Variable "$filter" got invalid value "OWNER" at "filter.role"; Value "OWNER" does not exist in "Role" enum.`,
+				`Variable "$filter" got invalid value {"role":"OWNER","size":1}; Field "limit" of required type "Int!" was not provided.`,
+				`Variable "$filter" got invalid value {"role":"OWNER","size":1}; Field "size" is not defined by type "UserFilter".`,
+			},
+		},
+		{
+			map[string]any{"term": "a", "filter": "x"},
+			[]string{`Variable "$filter" got invalid value "x"; Expected type "UserFilter" to be an object.`},
+		},
+	}
+	for _, tt := range tests {
+		_, errs := ValidateVariables(s, op, tt.vars)
+		var got []string
+		for _, err := range errs {
+			got = append(got, err.Message)
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("vars %v got:\n%s\nwant:\n%s", tt.vars, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+
+	// Without a schema, input objects are not checked.
+	if _, errs := ValidateVariables(nil, op, map[string]any{"term": "a", "filter": "x"}); len(errs) > 0 {
+		t.Errorf("unexpected errors without schema: %v", errs)
+	}
+}
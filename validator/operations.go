@@ -0,0 +1,285 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/razzkumar/go-graphql/language"
+	"github.com/razzkumar/go-graphql/schema"
+)
+
+// Operation is a client document to check, such as the contents of a
+// .graphql file or an entry of a persisted query manifest.
+type Operation struct {
+	// Source identifies where the operation came from: a file path or
+	// a persisted query id.
+	Source string `json:"source"`
+	// Name is the operation name, if known.
+	Name  string `json:"name,omitempty"`
+	Query string `json:"-"`
+}
+
+// OperationResult is the outcome of checking one Operation.
+type OperationResult struct {
+	Operation Operation `json:"operation"`
+	Errors    Errors    `json:"errors"`
+}
+
+// Report lists the operations that fail validation.
+type Report struct {
+	// Checked is the number of operations checked.
+	Checked int               `json:"checked"`
+	Failed  []OperationResult `json:"failed"`
+}
+
+// OK reports whether every operation passed.
+func (r *Report) OK() bool {
+	return len(r.Failed) == 0
+}
+
+// String formats the report for humans.
+func (r *Report) String() string {
+	var sb strings.Builder
+	for _, res := range r.Failed {
+		name := res.Operation.Source
+		if res.Operation.Name != "" {
+			name += " (" + res.Operation.Name + ")"
+		}
+		fmt.Fprintf(&sb, "%s\n", name)
+		for _, err := range res.Errors {
+			if len(err.Locations) > 0 {
+				fmt.Fprintf(&sb, "  %s: %s\n", err.Locations[0], err.Message)
+			} else {
+				fmt.Fprintf(&sb, "  %s\n", err.Message)
+			}
+		}
+	}
+	fmt.Fprintf(&sb, "%d of %d operations failed\n", len(r.Failed), r.Checked)
+	return sb.String()
+}
+
+// CheckOperations validates each operation against s and reports the
+// ones that fail.
+//
+// Fragments are shared between operations: a document may spread a
+// fragment defined in another one, as is common when each fragment
+// lives in its own .graphql file. Documents that only define fragments
+// are not reported on their own, and unused fragments are not errors.
+func CheckOperations(s *schema.Schema, ops []Operation) *Report {
+	report, _ := checkOperations(s, ops)
+	return report
+}
+
+// CheckImpact reports the operations that are valid against oldSchema
+// but fail against newSchema, which are the clients a schema change
+// would break.
+func CheckImpact(oldSchema, newSchema *schema.Schema, ops []Operation) *Report {
+	_, failedBefore := checkOperations(oldSchema, ops)
+	report, failedAfter := checkOperations(newSchema, ops)
+	report.Failed = report.Failed[:0]
+	for i := range ops {
+		if res, ok := failedAfter[i]; ok && !failedBefore[i].failed() {
+			report.Failed = append(report.Failed, res)
+		}
+	}
+	return report
+}
+
+func (res OperationResult) failed() bool {
+	return len(res.Errors) > 0
+}
+
+func checkOperations(s *schema.Schema, ops []Operation) (*Report, map[int]OperationResult) {
+	report := &Report{Failed: []OperationResult{}}
+	failed := make(map[int]OperationResult)
+	docs, shared := parseOperations(ops)
+	for i, op := range ops {
+		doc := docs[i]
+		var errs Errors
+		if doc.err != nil {
+			errs = doc.err
+		} else if len(doc.doc.Operations) == 0 {
+			continue
+		} else {
+			for _, err := range Validate(s, withFragments(doc.doc, shared)) {
+				if err.Rule != "NoUnusedFragments" {
+					errs = append(errs, err)
+				}
+			}
+		}
+		report.Checked++
+		if len(errs) > 0 {
+			res := OperationResult{Operation: op, Errors: errs}
+			report.Failed = append(report.Failed, res)
+			failed[i] = res
+		}
+	}
+	return report, failed
+}
+
+type parsedOperation struct {
+	doc *language.QueryDocument
+	err Errors
+}
+
+func parseOperations(ops []Operation) ([]parsedOperation, map[string]*language.FragmentDefinition) {
+	docs := make([]parsedOperation, len(ops))
+	shared := make(map[string]*language.FragmentDefinition)
+	for i, op := range ops {
+		doc, err := language.ParseQuery(op.Query)
+		if err != nil {
+			docs[i].err = syntaxErrors(err)
+			continue
+		}
+		docs[i].doc = doc
+		for _, frag := range doc.Fragments {
+			if _, ok := shared[frag.Name]; !ok {
+				shared[frag.Name] = frag
+			}
+		}
+	}
+	return docs, shared
+}
+
+// withFragments copies doc adding the shared fragments it spreads but
+// does not define itself.
+func withFragments(doc *language.QueryDocument, shared map[string]*language.FragmentDefinition) *language.QueryDocument {
+	out := &language.QueryDocument{
+		Operations: doc.Operations,
+		Fragments:  append([]*language.FragmentDefinition(nil), doc.Fragments...),
+	}
+	defined := make(map[string]bool)
+	for _, frag := range doc.Fragments {
+		defined[frag.Name] = true
+	}
+	var pending []*language.FragmentSpread
+	for _, op := range doc.Operations {
+		pending = append(pending, fragmentSpreads(op.SelectionSet)...)
+	}
+	for _, frag := range doc.Fragments {
+		pending = append(pending, fragmentSpreads(frag.SelectionSet)...)
+	}
+	for len(pending) > 0 {
+		spread := pending[0]
+		pending = pending[1:]
+		if defined[spread.Name] {
+			continue
+		}
+		frag, ok := shared[spread.Name]
+		if !ok {
+			continue
+		}
+		defined[spread.Name] = true
+		out.Fragments = append(out.Fragments, frag)
+		pending = append(pending, fragmentSpreads(frag.SelectionSet)...)
+	}
+	return out
+}
+
+func syntaxErrors(err error) Errors {
+	var syntaxErr *language.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return Errors{{
+			Message:   syntaxErr.Message,
+			Locations: []language.Position{syntaxErr.Position},
+			Rule:      "Syntax",
+		}}
+	}
+	return Errors{{Message: err.Error(), Rule: "Syntax"}}
+}
+
+// LoadOperationFiles reads operations from .graphql and .gql files.
+// Each path may be a file or a directory, which is searched
+// recursively.
+func LoadOperationFiles(paths ...string) ([]Operation, error) {
+	var ops []Operation
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if path != root {
+				switch strings.ToLower(filepath.Ext(path)) {
+				case ".graphql", ".gql":
+				default:
+					return nil
+				}
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			ops = append(ops, Operation{
+				Source: path,
+				Name:   operationNames(string(data)),
+				Query:  string(data),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ops, nil
+}
+
+// LoadPersistedQueries reads operations from a persisted query
+// manifest. Both the Apollo manifest format
+//
+//	{"format": "apollo-persisted-query-manifest", "version": 1,
+//	 "operations": [{"id": "...", "name": "GetUser", "body": "query GetUser { ... }"}]}
+//
+// and a plain object mapping ids to documents are accepted.
+func LoadPersistedQueries(data []byte) ([]Operation, error) {
+	var manifest struct {
+		Operations []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			Body string `json:"body"`
+		} `json:"operations"`
+	}
+	if err := json.Unmarshal(data, &manifest); err == nil && manifest.Operations != nil {
+		ops := make([]Operation, len(manifest.Operations))
+		for i, op := range manifest.Operations {
+			ops[i] = Operation{Source: op.ID, Name: op.Name, Query: op.Body}
+		}
+		return ops, nil
+	}
+	var byID map[string]string
+	if err := json.Unmarshal(data, &byID); err != nil {
+		return nil, fmt.Errorf("decoding persisted queries: %w", err)
+	}
+	ids := make([]string, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	ops := make([]Operation, len(ids))
+	for i, id := range ids {
+		ops[i] = Operation{Source: id, Name: operationNames(byID[id]), Query: byID[id]}
+	}
+	return ops, nil
+}
+
+func operationNames(query string) string {
+	doc, err := language.ParseQuery(query)
+	if err != nil {
+		return ""
+	}
+	var names []string
+	for _, op := range doc.Operations {
+		if op.Name != "" {
+			names = append(names, op.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,110 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/razzkumar/go-graphql/schema"
+)
+
+func TestCheckOperations(t *testing.T) {
+	s := mustSchema(t)
+	ops := []Operation{
+		{Source: "user.graphql", Query: `query GetUser($id: ID!) { user(id: $id) { ...UserFields } }`},
+		{Source: "fragments.graphql", Query: `fragment UserFields on User { id name email }`},
+		{Source: "users.graphql", Query: `query ListUsers { users { id role } }`},
+		{Source: "broken.graphql", Query: `query {`},
+	}
+	report := CheckOperations(s, ops)
+	if got, want := report.Checked, 3; got != want {
+		t.Errorf("report.Checked got %v, want %v", got, want)
+	}
+	if got, want := len(report.Failed), 2; got != want {
+		t.Fatalf("len(report.Failed) got %v, want %v: %v", got, want, report)
+	}
+	if got, want := report.Failed[0].Operation.Source, "user.graphql"; got != want {
+		t.Errorf("failed operation got %v, want %v", got, want)
+	}
+	if got, want := report.Failed[0].Errors[0].Message, `Cannot query field "email" on type "User".`; got != want {
+		t.Errorf("error got %v, want %v", got, want)
+	}
+	if got, want := report.Failed[1].Errors[0].Rule, "Syntax"; got != want {
+		t.Errorf("rule got %v, want %v", got, want)
+	}
+	if report.OK() {
+		t.Error("report should not be OK")
+	}
+	if !strings.HasSuffix(report.String(), "2 of 3 operations failed\n") {
+		t.Errorf("report.String() got:\n%s", report)
+	}
+}
+
+func TestCheckImpact(t *testing.T) {
+	oldSchema := mustSchema(t)
+	newSchema, err := schema.LoadSDL(strings.Replace(testSDL, "name: String\n", "", 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ops := []Operation{
+		{Source: "a", Query: `{ user(id: 1) { name } }`},
+		{Source: "b", Query: `{ user(id: 1) { id } }`},
+		{Source: "c", Query: `{ user(id: 1) { email } }`},
+	}
+	report := CheckImpact(oldSchema, newSchema, ops)
+	if got, want := len(report.Failed), 1; got != want {
+		t.Fatalf("len(report.Failed) got %v, want %v: %v", got, want, report)
+	}
+	if got, want := report.Failed[0].Operation.Source, "a"; got != want {
+		t.Errorf("failed operation got %v, want %v", got, want)
+	}
+}
+
+func TestLoadOperationFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "nested"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"a.graphql":        `query A { a }`,
+		"nested/b.gql":     `query B { b } query C { c }`,
+		"nested/notes.txt": `ignored`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ops, err := LoadOperationFiles(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := len(ops), 2; got != want {
+		t.Fatalf("len(ops) got %v, want %v", got, want)
+	}
+	if got, want := ops[1].Name, "B, C"; got != want {
+		t.Errorf("ops[1].Name got %v, want %v", got, want)
+	}
+}
+
+func TestLoadPersistedQueries(t *testing.T) {
+	ops, err := LoadPersistedQueries([]byte(`{
+		"format": "apollo-persisted-query-manifest",
+		"version": 1,
+		"operations": [{"id": "abc", "name": "GetUser", "type": "query", "body": "query GetUser { a }"}]
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ops) != 1 || ops[0].Source != "abc" || ops[0].Name != "GetUser" || ops[0].Query != "query GetUser { a }" {
+		t.Errorf("ops got %+v", ops)
+	}
+	ops, err = LoadPersistedQueries([]byte(`{"h2": "query Two { b }", "h1": "query One { a }"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ops) != 2 || ops[0].Source != "h1" || ops[0].Name != "One" {
+		t.Errorf("ops got %+v", ops)
+	}
+}
@@ -0,0 +1,129 @@
+package validator
+
+import (
+	"github.com/razzkumar/go-graphql/language"
+	"github.com/razzkumar/go-graphql/schema"
+)
+
+type collectedField struct {
+	field  *language.Field
+	parent *schema.Type
+	def    *schema.Field
+}
+
+// collectFields flattens set into the fields it selects, following
+// inline fragments and fragment spreads. Each field is paired with the
+// type it is selected on, if known.
+func (v *validator) collectFields(parent *schema.Type, set language.SelectionSet, visited map[string]bool) []collectedField {
+	var fields []collectedField
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *language.Field:
+			cf := collectedField{field: sel, parent: parent}
+			if parent != nil {
+				cf.def = v.fieldDefinition(parent, sel.Name)
+			}
+			fields = append(fields, cf)
+		case *language.InlineFragment:
+			t := parent
+			if sel.TypeCondition != "" {
+				t = v.schema.Types[sel.TypeCondition]
+			}
+			fields = append(fields, v.collectFields(t, sel.SelectionSet, visited)...)
+		case *language.FragmentSpread:
+			frag, ok := v.fragments[sel.Name]
+			if !ok || visited[sel.Name] {
+				continue
+			}
+			visited[sel.Name] = true
+			fields = append(fields, v.collectFields(v.schema.Types[frag.TypeCondition], frag.SelectionSet, visited)...)
+		}
+	}
+	return fields
+}
+
+// checkOverlappingFields reports fields in set that share a response
+// key but cannot be merged: different fields or arguments on the same
+// parent type, or incompatible return types.
+//
+// This is a simplified form of the OverlappingFieldsCanBeMerged rule;
+// the sub-selections of conflicting fields are compared when they are
+// walked rather than across the merged set.
+func (v *validator) checkOverlappingFields(parent *schema.Type, set language.SelectionSet) {
+	fields := v.collectFields(parent, set, make(map[string]bool))
+	byKey := make(map[string][]collectedField)
+	var keys []string
+	for _, f := range fields {
+		key := f.field.ResponseKey()
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], f)
+	}
+	for _, key := range keys {
+		group := byKey[key]
+	pairs:
+		for i := 0; i < len(group); i++ {
+			for j := i + 1; j < len(group); j++ {
+				if reason := v.fieldConflict(group[i], group[j]); reason != "" {
+					v.report("OverlappingFieldsCanBeMerged", at(group[i].field.Position, group[j].field.Position),
+						"Fields %q conflict because %s. Use different aliases on the fields to fetch both if this was intentional.", key, reason)
+					break pairs
+				}
+			}
+		}
+	}
+}
+
+func (v *validator) fieldConflict(a, b collectedField) string {
+	exclusive := a.parent != nil && b.parent != nil && a.parent.Name != b.parent.Name &&
+		a.parent.Kind == schema.Object && b.parent.Kind == schema.Object
+	if !exclusive {
+		if a.field.Name != b.field.Name {
+			return `"` + a.field.Name + `" and "` + b.field.Name + `" are different fields`
+		}
+		if !sameArguments(a.field.Arguments, b.field.Arguments) {
+			return "they have differing arguments"
+		}
+	}
+	if a.def != nil && b.def != nil && !v.sameShape(a.def.Type, b.def.Type) {
+		return `they return conflicting types "` + a.def.Type.String() + `" and "` + b.def.Type.String() + `"`
+	}
+	return ""
+}
+
+func sameArguments(a, b []*language.Argument) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, argA := range a {
+		found := false
+		for _, argB := range b {
+			if argA.Name == argB.Name {
+				found = argA.Value.String() == argB.Value.String()
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sameShape reports whether two field types produce the same response
+// shape: lists and non-null wrappers must match, and leaf types must be
+// identical. Composite types are compared by their sub-selections.
+func (v *validator) sameShape(a, b *language.Type) bool {
+	if a.NonNull != b.NonNull || a.IsList() != b.IsList() {
+		return false
+	}
+	if a.IsList() {
+		return v.sameShape(a.Elem, b.Elem)
+	}
+	ta, tb := v.schema.Types[a.NamedType], v.schema.Types[b.NamedType]
+	if (ta != nil && ta.IsLeaf()) || (tb != nil && tb.IsLeaf()) {
+		return a.NamedType == b.NamedType
+	}
+	return true
+}
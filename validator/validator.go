@@ -0,0 +1,310 @@
+// Package validator checks GraphQL operations against a schema without
+// sending them to a server.
+//
+// It implements the validation rules from the GraphQL specification
+// and reports every problem it finds along with its location in the
+// document:
+//
+//	s, err := schema.LoadFile("schema.graphql")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, err := range validator.ValidateQuery(s, query) {
+//	    fmt.Println(err)
+//	}
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/razzkumar/go-graphql/language"
+	"github.com/razzkumar/go-graphql/schema"
+)
+
+// Error is a single validation error.
+type Error struct {
+	Message   string              `json:"message"`
+	Locations []language.Position `json:"locations,omitempty"`
+	// Rule is the name of the validation rule that failed, such as
+	// FieldsOnCorrectType.
+	Rule string `json:"rule,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Locations) == 0 {
+		return "graphql: " + e.Message
+	}
+	return fmt.Sprintf("graphql: %s: %s", e.Locations[0], e.Message)
+}
+
+// Errors is a list of validation errors.
+type Errors []*Error
+
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// ValidateQuery parses query and validates it against s. Syntax errors
+// are reported with the rule name "Syntax".
+func ValidateQuery(s *schema.Schema, query string) Errors {
+	doc, err := language.ParseQuery(query)
+	if err != nil {
+		return syntaxErrors(err)
+	}
+	return Validate(s, doc)
+}
+
+// Validate validates doc against s and returns all errors found, or nil
+// if the document is valid.
+func Validate(s *schema.Schema, doc *language.QueryDocument) Errors {
+	v := &validator{
+		schema:    s,
+		doc:       doc,
+		fragments: make(map[string]*language.FragmentDefinition),
+		fragWalks: make(map[string]*walk),
+		reported:  make(map[string]bool),
+	}
+	v.validate()
+	return v.errs
+}
+
+type validator struct {
+	schema    *schema.Schema
+	doc       *language.QueryDocument
+	fragments map[string]*language.FragmentDefinition
+	fragWalks map[string]*walk
+	errs      Errors
+	reported  map[string]bool
+}
+
+// walk collects what a selection set refers to: the variables it uses
+// and the fragments it spreads.
+type walk struct {
+	usages  []variableUsage
+	spreads []string
+}
+
+type variableUsage struct {
+	name string
+	// typ is the type expected where the variable is used, or nil if
+	// unknown.
+	typ             *language.Type
+	locationDefault bool
+	pos             language.Position
+}
+
+func (v *validator) report(rule string, positions []language.Position, format string, args ...any) {
+	err := &Error{
+		Message:   fmt.Sprintf(format, args...),
+		Locations: positions,
+		Rule:      rule,
+	}
+	key := fmt.Sprint(err.Message, err.Locations)
+	if v.reported[key] {
+		return
+	}
+	v.reported[key] = true
+	v.errs = append(v.errs, err)
+}
+
+func at(positions ...language.Position) []language.Position {
+	return positions
+}
+
+func (v *validator) validate() {
+	anonymous := 0
+	opNames := make(map[string]bool)
+	for _, op := range v.doc.Operations {
+		if op.Name == "" {
+			anonymous++
+			continue
+		}
+		if opNames[op.Name] {
+			v.report("UniqueOperationNames", at(op.Position), "There can be only one operation named %q.", op.Name)
+		}
+		opNames[op.Name] = true
+	}
+	if anonymous > 0 && len(v.doc.Operations) > 1 {
+		for _, op := range v.doc.Operations {
+			if op.Name == "" {
+				v.report("LoneAnonymousOperation", at(op.Position), "This anonymous operation must be the only defined operation.")
+			}
+		}
+	}
+	for _, frag := range v.doc.Fragments {
+		if _, ok := v.fragments[frag.Name]; ok {
+			v.report("UniqueFragmentNames", at(frag.Position), "There can be only one fragment named %q.", frag.Name)
+			continue
+		}
+		v.fragments[frag.Name] = frag
+	}
+	for _, frag := range v.doc.Fragments {
+		if v.fragments[frag.Name] != frag {
+			continue
+		}
+		v.fragWalks[frag.Name] = v.walkFragmentDefinition(frag)
+	}
+	used := make(map[string]bool)
+	for _, op := range v.doc.Operations {
+		w := v.walkOperation(op)
+		spread := v.collectSpreads(w)
+		for name := range spread {
+			used[name] = true
+		}
+		v.checkVariables(op, w, spread)
+	}
+	for _, frag := range v.doc.Fragments {
+		if !used[frag.Name] {
+			v.report("NoUnusedFragments", at(frag.Position), "Fragment %q is never used.", frag.Name)
+		}
+	}
+	v.checkFragmentCycles()
+}
+
+func (v *validator) walkOperation(op *language.OperationDefinition) *walk {
+	w := &walk{}
+	var loc language.DirectiveLocation
+	switch op.Operation {
+	case language.Mutation:
+		loc = language.LocationMutation
+	case language.Subscription:
+		loc = language.LocationSubscription
+	default:
+		loc = language.LocationQuery
+	}
+	v.walkDirectives(w, op.Directives, loc)
+	root := v.schema.RootType(op.Operation)
+	if root == nil {
+		v.report("KnownOperationTypes", at(op.Position), "Schema is not configured to execute %s operation.", op.Operation)
+	}
+	if op.Operation == language.Subscription {
+		v.checkSingleRootField(op)
+	}
+	v.walkSelectionSet(w, root, op.SelectionSet)
+	return w
+}
+
+func (v *validator) walkFragmentDefinition(frag *language.FragmentDefinition) *walk {
+	w := &walk{}
+	v.walkDirectives(w, frag.Directives, language.LocationFragmentDefinition)
+	t, ok := v.schema.Types[frag.TypeCondition]
+	switch {
+	case !ok:
+		v.report("KnownTypeNames", at(frag.Position), "Unknown type %q.", frag.TypeCondition)
+		t = nil
+	case !t.IsComposite():
+		v.report("FragmentsOnCompositeTypes", at(frag.Position), "Fragment %q cannot condition on non composite type %q.", frag.Name, frag.TypeCondition)
+		t = nil
+	}
+	v.walkSelectionSet(w, t, frag.SelectionSet)
+	return w
+}
+
+// collectSpreads gets the names of all fragments w spreads, directly
+// or through other fragments.
+func (v *validator) collectSpreads(w *walk) map[string]bool {
+	seen := make(map[string]bool)
+	var visit func(names []string)
+	visit = func(names []string) {
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			if fw, ok := v.fragWalks[name]; ok {
+				visit(fw.spreads)
+			}
+		}
+	}
+	visit(w.spreads)
+	return seen
+}
+
+func (v *validator) checkSingleRootField(op *language.OperationDefinition) {
+	fields := v.collectFields(nil, op.SelectionSet, make(map[string]bool))
+	keys := make(map[string]bool)
+	var extra []language.Position
+	for _, f := range fields {
+		if !keys[f.field.ResponseKey()] && len(keys) > 0 {
+			extra = append(extra, f.field.Position)
+		}
+		keys[f.field.ResponseKey()] = true
+	}
+	if len(extra) == 0 {
+		return
+	}
+	if op.Name == "" {
+		v.report("SingleFieldSubscriptions", extra, "Anonymous Subscription must select only one top level field.")
+		return
+	}
+	v.report("SingleFieldSubscriptions", extra, "Subscription %q must select only one top level field.", op.Name)
+}
+
+func (v *validator) checkFragmentCycles() {
+	visited := make(map[string]bool)
+	pathIndex := make(map[string]int)
+	var path []*language.FragmentSpread
+	var detect func(frag *language.FragmentDefinition)
+	detect = func(frag *language.FragmentDefinition) {
+		if visited[frag.Name] {
+			return
+		}
+		visited[frag.Name] = true
+		spreads := fragmentSpreads(frag.SelectionSet)
+		if len(spreads) == 0 {
+			return
+		}
+		pathIndex[frag.Name] = len(path)
+		for _, spread := range spreads {
+			cycleIndex, inPath := pathIndex[spread.Name]
+			path = append(path, spread)
+			if !inPath {
+				if next := v.fragments[spread.Name]; next != nil {
+					detect(next)
+				}
+			} else {
+				cycle := path[cycleIndex:]
+				var via []string
+				var positions []language.Position
+				for i, s := range cycle {
+					if i < len(cycle)-1 {
+						via = append(via, fmt.Sprintf("%q", s.Name))
+					}
+					positions = append(positions, s.Position)
+				}
+				if len(via) == 0 {
+					v.report("NoFragmentCycles", positions, "Cannot spread fragment %q within itself.", spread.Name)
+				} else {
+					v.report("NoFragmentCycles", positions, "Cannot spread fragment %q within itself via %s.", spread.Name, strings.Join(via, ", "))
+				}
+			}
+			path = path[:len(path)-1]
+		}
+		delete(pathIndex, frag.Name)
+	}
+	for _, frag := range v.doc.Fragments {
+		detect(frag)
+	}
+}
+
+// fragmentSpreads gets the fragment spreads anywhere in set without
+// following them.
+func fragmentSpreads(set language.SelectionSet) []*language.FragmentSpread {
+	var spreads []*language.FragmentSpread
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *language.Field:
+			spreads = append(spreads, fragmentSpreads(sel.SelectionSet)...)
+		case *language.InlineFragment:
+			spreads = append(spreads, fragmentSpreads(sel.SelectionSet)...)
+		case *language.FragmentSpread:
+			spreads = append(spreads, sel)
+		}
+	}
+	return spreads
+}
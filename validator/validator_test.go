@@ -0,0 +1,172 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/razzkumar/go-graphql/language"
+	"github.com/razzkumar/go-graphql/schema"
+)
+
+const testSDL = `
+interface Node { id: ID! }
+
+type User implements Node {
+	id: ID!
+	name: String
+	friends(first: Int = 10): [User!]!
+	avatar(size: Int!): String
+	role: Role!
+}
+
+type Post implements Node {
+	id: ID!
+	title: String!
+}
+
+union SearchResult = User | Post
+
+enum Role { ADMIN MEMBER }
+
+input UserFilter {
+	role: Role
+	limit: Int!
+}
+
+type Query {
+	node(id: ID!): Node
+	user(id: ID!): User
+	users(filter: UserFilter, ids: [ID!]): [User!]!
+	search(term: String!): [SearchResult!]!
+}
+
+type Mutation {
+	rename(id: ID!, name: String!): User
+}
+
+type Subscription {
+	userChanged: User
+	postAdded: Post
+}
+`
+
+func mustSchema(t *testing.T) *schema.Schema {
+	t.Helper()
+	s, err := schema.LoadSDL(testSDL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return s
+}
+
+func TestValidateValid(t *testing.T) {
+	s := mustSchema(t)
+	queries := []string{
+		`{ user(id: "1") { id name role } }`,
+		`query ($id: ID!) { user(id: $id) { ...F } } fragment F on User { name friends { id } }`,
+		`query ($limit: Int!) { users(filter: {role: ADMIN, limit: $limit}, ids: "1") { id } }`,
+		`query ($skip: Boolean!) { search(term: "x") { __typename ... on User { name } ... on Post { title } } u: user(id: 1) @skip(if: $skip) { id } }`,
+		`{ node(id: "1") { id ... on User { friends(first: 2) { name } } } }`,
+		`query ($first: Int) { user(id: "1") { friends(first: $first) { id } } }`,
+		`{ __schema { types { name fields { name } } } __type(name: "User") { name } }`,
+		`mutation { rename(id: "1", name: "x") { id } }`,
+		`subscription { userChanged { id } }`,
+	}
+	for _, q := range queries {
+		if errs := ValidateQuery(s, q); len(errs) > 0 {
+			t.Errorf("ValidateQuery(%q) got %v, want no errors", q, errs)
+		}
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	s := mustSchema(t)
+	tests := []struct {
+		query string
+		rule  string
+		want  string
+	}{
+		{`{ user(id: "1") { email } }`, "FieldsOnCorrectType", `Cannot query field "email" on type "User".`},
+		{`{ user(id: "1") }`, "ScalarLeafs", `Field "user" of type "User" must have a selection of subfields. Did you mean "user { ... }"?`},
+		{`{ user(id: "1") { name { x } } }`, "ScalarLeafs", `Field "name" must not have a selection since type "String" has no subfields.`},
+		{`{ user { id } }`, "ProvidedRequiredArguments", `Field "user" argument "id" of type "ID!" is required, but it was not provided.`},
+		{`{ user(id: "1", foo: 1) { id } }`, "KnownArgumentNames", `Unknown argument "foo" on field "Query.user".`},
+		{`{ user(id: "1", id: "2") { id } }`, "UniqueArgumentNames", `There can be only one argument named "id".`},
+		{`{ user(id: true) { id } }`, "ValuesOfCorrectType", `Expected value of type "ID!", found true; ID cannot represent a non-string and non-integer value: true`},
+		{`{ user(id: "1") { avatar(size: 3000000000) } }`, "ValuesOfCorrectType", `Expected value of type "Int!", found 3000000000; Int cannot represent non 32-bit signed integer value: 3000000000`},
+		{`{ users(filter: {role: OWNER, limit: 1}) { id } }`, "ValuesOfCorrectType", `Value "OWNER" does not exist in "Role" enum.`},
+		{`{ users(filter: {role: ADMIN}) { id } }`, "ValuesOfCorrectType", `Field "UserFilter.limit" of required type "Int!" was not provided.`},
+		{`{ users(filter: {limit: 1, nope: 2}) { id } }`, "ValuesOfCorrectType", `Field "nope" is not defined by type "UserFilter".`},
+		{`{ user(id: null) { id } }`, "ValuesOfCorrectType", `Expected value of type "ID!", found null.`},
+		{`query ($id: ID) { user(id: $id) { id } }`, "VariablesInAllowedPosition", `Variable "$id" of type "ID" used in position expecting type "ID!".`},
+		{`query ($id: String!) { user(id: $id) { id } }`, "VariablesInAllowedPosition", `Variable "$id" of type "String!" used in position expecting type "ID!".`},
+		{`query Q { user(id: $id) { id } }`, "NoUndefinedVariables", `Variable "$id" is not defined by operation "Q".`},
+		{`query ($id: ID!, $x: Int) { user(id: $id) { id } }`, "NoUnusedVariables", `Variable "$x" is never used.`},
+		{`query ($id: ID!, $id: ID!) { user(id: $id) { id } }`, "UniqueVariableNames", `There can be only one variable named "$id".`},
+		{`query ($u: User) { user(id: 1) { id } }`, "VariablesAreInputTypes", `Variable "$u" cannot be non-input type "User".`},
+		{`query ($u: Nope) { user(id: 1) { id } }`, "KnownTypeNames", `Unknown type "Nope".`},
+		{`{ user(id: 1) { ...Missing } }`, "KnownFragmentNames", `Unknown fragment "Missing".`},
+		{`{ user(id: 1) { id } } fragment F on User { id }`, "NoUnusedFragments", `Fragment "F" is never used.`},
+		{`{ user(id: 1) { ...F } } fragment F on Post { id }`, "PossibleFragmentSpreads", `Fragment "F" cannot be spread here as objects of type "User" can never be of type "Post".`},
+		{`{ user(id: 1) { ... on String { id } } }`, "FragmentsOnCompositeTypes", `Fragment cannot condition on non composite type "String".`},
+		{`{ user(id: 1) { ...A } } fragment A on User { ...B } fragment B on User { ...A }`, "NoFragmentCycles", `Cannot spread fragment "A" within itself via "B".`},
+		{`{ user(id: 1) { id @foo } }`, "KnownDirectives", `Unknown directive "@foo".`},
+		{`query @skip(if: true) { user(id: 1) { id } }`, "KnownDirectives", `Directive "@skip" may not be used on QUERY.`},
+		{`{ user(id: 1) { id @skip(if: true) @skip(if: false) } }`, "UniqueDirectivesPerLocation", `The directive "@skip" can only be used once at this location.`},
+		{`{ user(id: 1) { id @include } }`, "ProvidedRequiredArguments", `Directive "@include" argument "if" of type "Boolean!" is required, but it was not provided.`},
+		{`{ a: user(id: 1) { id } a: node(id: 1) { id } }`, "OverlappingFieldsCanBeMerged", `Fields "a" conflict because "user" and "node" are different fields. Use different aliases on the fields to fetch both if this was intentional.`},
+		{`{ user(id: 1) { id } user(id: 2) { id } }`, "OverlappingFieldsCanBeMerged", `Fields "user" conflict because they have differing arguments. Use different aliases on the fields to fetch both if this was intentional.`},
+		{`{ a { id } } { b { id } }`, "LoneAnonymousOperation", `This anonymous operation must be the only defined operation.`},
+		{`query Q { user(id: 1) { id } } query Q { user(id: 1) { id } }`, "UniqueOperationNames", `There can be only one operation named "Q".`},
+		{`subscription S { userChanged { id } postAdded { id } }`, "SingleFieldSubscriptions", `Subscription "S" must select only one top level field.`},
+		{`{ user(id: 1) { id }`, "Syntax", `Expected Name, found "<EOF>"`},
+	}
+	for _, tt := range tests {
+		errs := ValidateQuery(s, tt.query)
+		found := false
+		for _, err := range errs {
+			if err.Rule == tt.rule && err.Message == tt.want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("ValidateQuery(%q)\n got %v\nwant %s: %s", tt.query, errs, tt.rule, tt.want)
+		}
+	}
+}
+
+func TestValidateLocations(t *testing.T) {
+	s := mustSchema(t)
+	errs := ValidateQuery(s, "{\n  user(id: 1) {\n    email\n    phone\n  }\n}")
+	if got, want := len(errs), 2; got != want {
+		t.Fatalf("len(errs) got %v, want %v: %v", got, want, errs)
+	}
+	if got, want := errs[0].Locations, []language.Position{{Line: 3, Column: 5}}; len(got) != 1 || got[0] != want[0] {
+		t.Errorf("errs[0].Locations got %v, want %v", got, want)
+	}
+	if got, want := errs.Error(), "graphql: 3:5: Cannot query field \"email\" on type \"User\".\ngraphql: 4:5: Cannot query field \"phone\" on type \"User\"."; got != want {
+		t.Errorf("errs.Error() got %v, want %v", got, want)
+	}
+}
+
+func TestValidateFragmentVariables(t *testing.T) {
+	s := mustSchema(t)
+	errs := ValidateQuery(s, `
+		query Q($size: Int) { user(id: 1) { ...F } }
+		fragment F on User { avatar(size: $size) }
+	`)
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, `Variable "$size" of type "Int" used in position expecting type "Int!"`) {
+		t.Errorf("errs got %v", errs)
+	}
+}
+
+func TestValidateMissingRoot(t *testing.T) {
+	s, err := schema.LoadSDL(`type Query { a: String }`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	errs := ValidateQuery(s, `mutation { a }`)
+	if len(errs) != 1 || errs[0].Message != "Schema is not configured to execute mutation operation." {
+		t.Errorf("errs got %v", errs)
+	}
+}
@@ -0,0 +1,130 @@
+package validator
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/razzkumar/go-graphql/language"
+	"github.com/razzkumar/go-graphql/schema"
+)
+
+// walkValue checks that val can be coerced to t and records variable
+// usages. locationDefault reports whether the argument or input field
+// val is given for has a default value.
+func (v *validator) walkValue(w *walk, val *language.Value, t *language.Type, locationDefault bool) {
+	if val == nil {
+		return
+	}
+	if val.Kind == language.Variable {
+		w.usages = append(w.usages, variableUsage{
+			name:            val.Raw,
+			typ:             t,
+			locationDefault: locationDefault,
+			pos:             val.Position,
+		})
+		return
+	}
+	if t == nil {
+		for _, child := range val.Children {
+			v.walkValue(w, child.Value, nil, false)
+		}
+		return
+	}
+	if val.Kind == language.NullValue {
+		if t.NonNull {
+			v.report("ValuesOfCorrectType", at(val.Position), "Expected value of type %q, found null.", t)
+		}
+		return
+	}
+	if t.Elem != nil {
+		if val.Kind != language.ListValue {
+			v.walkValue(w, val, t.Elem, false)
+			return
+		}
+		for _, item := range val.Children {
+			v.walkValue(w, item.Value, t.Elem, false)
+		}
+		return
+	}
+	named, ok := v.schema.Types[t.NamedType]
+	if !ok {
+		v.walkValue(w, val, nil, false)
+		return
+	}
+	switch named.Kind {
+	case schema.InputObject:
+		v.walkObjectValue(w, val, t, named)
+	case schema.Enum:
+		if val.Kind != language.EnumValue {
+			v.report("ValuesOfCorrectType", at(val.Position), "Enum %q cannot represent non-enum value: %s.", named.Name, val)
+		} else if named.EnumValue(val.Raw) == nil {
+			v.report("ValuesOfCorrectType", at(val.Position), "Value %q does not exist in %q enum.", val.Raw, named.Name)
+		}
+	case schema.Scalar:
+		if msg := checkScalarLiteral(named.Name, val); msg != "" {
+			v.report("ValuesOfCorrectType", at(val.Position), "Expected value of type %q, found %s; %s", t, val, msg)
+		}
+		for _, child := range val.Children {
+			v.walkValue(w, child.Value, nil, false)
+		}
+	}
+}
+
+func (v *validator) walkObjectValue(w *walk, val *language.Value, t *language.Type, named *schema.Type) {
+	if val.Kind != language.ObjectValue {
+		v.report("ValuesOfCorrectType", at(val.Position), "Expected value of type %q, found %s.", t, val)
+		return
+	}
+	seen := make(map[string]bool)
+	for _, child := range val.Children {
+		if seen[child.Name] {
+			v.report("UniqueInputFieldNames", at(child.Position), "There can be only one input field named %q.", child.Name)
+		}
+		seen[child.Name] = true
+		field := named.InputField(child.Name)
+		if field == nil {
+			v.report("ValuesOfCorrectType", at(child.Position), "Field %q is not defined by type %q.", child.Name, named.Name)
+			v.walkValue(w, child.Value, nil, false)
+			continue
+		}
+		v.walkValue(w, child.Value, field.Type, field.DefaultValue != nil)
+	}
+	for _, field := range named.InputFields {
+		if field.Type.NonNull && field.DefaultValue == nil && !seen[field.Name] {
+			v.report("ValuesOfCorrectType", at(val.Position), "Field %q of required type %q was not provided.", named.Name+"."+field.Name, field.Type)
+		}
+	}
+}
+
+// checkScalarLiteral checks a literal against a built-in scalar and
+// returns a description of the problem, or "" if it is valid. Custom
+// scalars accept any literal.
+func checkScalarLiteral(scalar string, val *language.Value) string {
+	switch scalar {
+	case "Int":
+		if val.Kind != language.IntValue {
+			return "Int cannot represent non-integer value: " + val.String()
+		}
+		n, err := strconv.ParseInt(val.Raw, 10, 64)
+		if err != nil || n > math.MaxInt32 || n < math.MinInt32 {
+			return "Int cannot represent non 32-bit signed integer value: " + val.Raw
+		}
+	case "Float":
+		if val.Kind != language.IntValue && val.Kind != language.FloatValue {
+			return "Float cannot represent non numeric value: " + val.String()
+		}
+	case "String":
+		if val.Kind != language.StringValue && val.Kind != language.BlockValue {
+			return "String cannot represent a non string value: " + val.String()
+		}
+	case "Boolean":
+		if val.Kind != language.BooleanValue {
+			return "Boolean cannot represent a non boolean value: " + val.String()
+		}
+	case "ID":
+		if val.Kind != language.StringValue && val.Kind != language.BlockValue && val.Kind != language.IntValue {
+			return "ID cannot represent a non-string and non-integer value: " + val.String()
+		}
+	}
+	return ""
+}
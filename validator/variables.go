@@ -0,0 +1,102 @@
+package validator
+
+import "github.com/razzkumar/go-graphql/language"
+
+// checkVariables validates the variable definitions of op and how the
+// variables are used by the operation and the fragments it spreads.
+func (v *validator) checkVariables(op *language.OperationDefinition, w *walk, spreads map[string]bool) {
+	defs := make(map[string]*language.VariableDefinition)
+	for _, def := range op.VariableDefinitions {
+		if _, ok := defs[def.Variable]; ok {
+			v.report("UniqueVariableNames", at(def.Position), "There can be only one variable named \"$%s\".", def.Variable)
+			continue
+		}
+		defs[def.Variable] = def
+		defWalk := &walk{}
+		v.walkDirectives(defWalk, def.Directives, language.LocationVariableDefinition)
+		t, ok := v.schema.Types[def.Type.Name()]
+		if !ok {
+			v.report("KnownTypeNames", at(def.Type.Position), "Unknown type %q.", def.Type.Name())
+			continue
+		}
+		if !t.IsInputType() {
+			v.report("VariablesAreInputTypes", at(def.Type.Position), "Variable \"$%s\" cannot be non-input type %q.", def.Variable, def.Type)
+			continue
+		}
+		if def.DefaultValue != nil {
+			v.walkValue(defWalk, def.DefaultValue, def.Type, false)
+		}
+	}
+
+	usages := w.usages
+	for _, frag := range v.doc.Fragments {
+		if spreads[frag.Name] && v.fragments[frag.Name] == frag {
+			usages = append(usages, v.fragWalks[frag.Name].usages...)
+		}
+	}
+	used := make(map[string]bool)
+	for _, usage := range usages {
+		used[usage.name] = true
+		def, ok := defs[usage.name]
+		if !ok {
+			if op.Name == "" {
+				v.report("NoUndefinedVariables", at(usage.pos, op.Position), "Variable \"$%s\" is not defined.", usage.name)
+			} else {
+				v.report("NoUndefinedVariables", at(usage.pos, op.Position), "Variable \"$%s\" is not defined by operation %q.", usage.name, op.Name)
+			}
+			continue
+		}
+		if usage.typ == nil {
+			continue
+		}
+		if !v.allowedInPosition(def, usage) {
+			v.report("VariablesInAllowedPosition", at(def.Position, usage.pos), "Variable \"$%s\" of type %q used in position expecting type %q.", usage.name, def.Type, usage.typ)
+		}
+	}
+	for _, def := range op.VariableDefinitions {
+		if used[def.Variable] {
+			continue
+		}
+		if op.Name == "" {
+			v.report("NoUnusedVariables", at(def.Position), "Variable \"$%s\" is never used.", def.Variable)
+		} else {
+			v.report("NoUnusedVariables", at(def.Position), "Variable \"$%s\" is never used in operation %q.", def.Variable, op.Name)
+		}
+	}
+}
+
+func (v *validator) allowedInPosition(def *language.VariableDefinition, usage variableUsage) bool {
+	locType := usage.typ
+	if locType.NonNull && !def.Type.NonNull {
+		hasDefault := def.DefaultValue != nil && def.DefaultValue.Kind != language.NullValue
+		if !hasDefault && !usage.locationDefault {
+			return false
+		}
+		locType = locType.Nullable()
+	}
+	return isSubTypeRef(def.Type, locType)
+}
+
+// isSubTypeRef reports whether a value of type sub may be used where
+// super is expected.
+func isSubTypeRef(sub, super *language.Type) bool {
+	if super.NonNull {
+		if !sub.NonNull {
+			return false
+		}
+		return isSubTypeRef(sub.Nullable(), super.Nullable())
+	}
+	if sub.NonNull {
+		return isSubTypeRef(sub.Nullable(), super)
+	}
+	if super.Elem != nil {
+		if sub.Elem == nil {
+			return false
+		}
+		return isSubTypeRef(sub.Elem, super.Elem)
+	}
+	if sub.Elem != nil {
+		return false
+	}
+	return sub.NamedType == super.NamedType
+}